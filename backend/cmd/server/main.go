@@ -51,6 +51,10 @@ func main() {
 		w.Write([]byte(`{"status":"ok"}`))
 	}).Methods("GET")
 
+	// Facebook webhooks (xác thực bằng verify token / X-Hub-Signature-256)
+	router.HandleFunc("/webhooks/facebook", handler.VerifyFacebookWebhook).Methods("GET")
+	router.HandleFunc("/webhooks/facebook", handler.ReceiveFacebookWebhook).Methods("POST")
+
 	// API routes
 	apiRouter := router.PathPrefix("/api").Subrouter()
 	
@@ -69,12 +73,20 @@ func main() {
 	apiRouter.HandleFunc("/pages/unassigned", handler.GetUnassignedPages).Methods("GET")
 	apiRouter.HandleFunc("/pages/{id}", handler.DeletePage).Methods("DELETE")
 	apiRouter.HandleFunc("/pages/{id}/toggle", handler.TogglePage).Methods("PATCH")
+	apiRouter.HandleFunc("/pages/{id}/native-scheduling", handler.SetPageNativeScheduling).Methods("PATCH")
 	apiRouter.HandleFunc("/pages/{id}/assignments", handler.GetPageAssignments).Methods("GET")
 	apiRouter.HandleFunc("/pages/{id}/assign", handler.AssignPageToAccount).Methods("POST")
 	apiRouter.HandleFunc("/pages/{id}/assign/{accountId}", handler.UnassignPageFromAccount).Methods("DELETE")
 	apiRouter.HandleFunc("/pages/{id}/primary", handler.SetPrimaryAccount).Methods("PUT")
 	apiRouter.HandleFunc("/pages/{id}/timeslots", handler.GetPageTimeSlots).Methods("GET")
 	apiRouter.HandleFunc("/pages/{id}/timeslots", handler.CreateTimeSlot).Methods("POST")
+	apiRouter.HandleFunc("/pages/{id}/insights", handler.GetPageInsights).Methods("GET")
+	apiRouter.HandleFunc("/pages/{id}/locations", handler.GetSavedLocations).Methods("GET")
+	apiRouter.HandleFunc("/pages/{id}/locations", handler.SaveLocation).Methods("POST")
+	apiRouter.HandleFunc("/pages/{id}/locations/{locationId}", handler.DeleteSavedLocation).Methods("DELETE")
+	
+	// Places routes (check-in)
+	apiRouter.HandleFunc("/places/search", handler.SearchPlaces).Methods("GET")
 	
 	// Posts routes
 	apiRouter.HandleFunc("/posts", handler.CreatePost).Methods("POST")
@@ -83,6 +95,12 @@ func main() {
 	apiRouter.HandleFunc("/posts/{id}", handler.GetPost).Methods("GET")
 	apiRouter.HandleFunc("/posts/{id}", handler.UpdatePost).Methods("PUT")
 	apiRouter.HandleFunc("/posts/{id}", handler.DeletePost).Methods("DELETE")
+	apiRouter.HandleFunc("/posts/{id}/insights", handler.GetPostInsights).Methods("GET")
+	apiRouter.HandleFunc("/posts/{id}/published", handler.EditPublishedPost).Methods("PUT")
+	apiRouter.HandleFunc("/posts/{id}/published", handler.DeletePublishedPost).Methods("DELETE")
+	
+	// Insights routes
+	apiRouter.HandleFunc("/insights/pages", handler.GetPagesInsights).Methods("GET")
 	
 	// Schedule routes
 	apiRouter.HandleFunc("/schedule", handler.SchedulePost).Methods("POST")
@@ -93,10 +111,13 @@ func main() {
 	apiRouter.HandleFunc("/schedule/stats", handler.GetScheduleStats).Methods("GET")
 	apiRouter.HandleFunc("/schedule/{id}", handler.DeleteScheduledPost).Methods("DELETE")
 	apiRouter.HandleFunc("/schedule/{id}/retry", handler.RetryScheduledPost).Methods("POST")
+	apiRouter.HandleFunc("/schedule/{id}/reschedule", handler.ReschedulePost).Methods("PUT")
 	apiRouter.HandleFunc("/schedule/{id}/test", handler.TestScheduleNow).Methods("POST") // DEV: Test ngay
 	
 	// Logs routes
 	apiRouter.HandleFunc("/logs", handler.GetPostLogs).Methods("GET")
+	apiRouter.HandleFunc("/logs/{id}", handler.EditPostLog).Methods("PUT")
+	apiRouter.HandleFunc("/logs/{id}", handler.DeletePostLog).Methods("DELETE")
 	
 	// Hashtag routes
 	apiRouter.HandleFunc("/hashtags/search", handler.SearchHashtags).Methods("GET")
@@ -112,6 +133,12 @@ func main() {
 	apiRouter.HandleFunc("/accounts/{id}", handler.DeleteAccount).Methods("DELETE")
 	apiRouter.HandleFunc("/accounts/{id}/pages", handler.GetAccountPages).Methods("GET")
 	apiRouter.HandleFunc("/accounts/{id}/refresh", handler.RefreshAccountToken).Methods("POST")
+	
+	// Graph API usage (throttling)
+	apiRouter.HandleFunc("/usage", handler.GetAPIUsage).Methods("GET")
+
+	// Webhook events
+	apiRouter.HandleFunc("/webhooks/events", handler.GetWebhookEvents).Methods("GET")
 
 	// Notifications routes
 	apiRouter.HandleFunc("/notifications", handler.GetNotifications).Methods("GET")
@@ -60,6 +60,8 @@ func (h *Handler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 		MaxPages       int    `json:"max_pages"`
 		MaxPostsPerDay int    `json:"max_posts_per_day"`
 		Notes          string `json:"notes"`
+
+		MaxStoriesPerDay int `json:"max_stories_per_day"` // 0 = story tính chung vào max_posts_per_day
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -72,18 +74,24 @@ func (h *Handler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Đổi sang long-lived token (nếu đã là long-lived thì Facebook trả lại token dùng được)
+	accessToken := h.extendUserToken(r.Context(), req.AccessToken)
+
 	// Check if account already exists
 	existing, _ := h.store.GetAccountByFbUserID(req.FbUserID)
 	if existing != nil {
 		// Update existing account
 		existing.FbUserName = req.FbUserName
-		existing.AccessToken = req.AccessToken
+		existing.AccessToken = accessToken
 		if req.MaxPages > 0 {
 			existing.MaxPages = req.MaxPages
 		}
 		if req.MaxPostsPerDay > 0 {
 			existing.MaxPostsPerDay = req.MaxPostsPerDay
 		}
+		if req.MaxStoriesPerDay > 0 {
+			existing.MaxStoriesPerDay = req.MaxStoriesPerDay
+		}
 		existing.Notes = req.Notes
 		existing.Status = "active"
 
@@ -91,6 +99,7 @@ func (h *Handler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 			respondError(w, http.StatusInternalServerError, "Failed to update account: "+err.Error())
 			return
 		}
+		h.recordAccountToken(r.Context(), existing)
 
 		respondJSON(w, http.StatusOK, existing)
 		return
@@ -100,7 +109,7 @@ func (h *Handler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 	account := &db.FacebookAccount{
 		FbUserID:       req.FbUserID,
 		FbUserName:     req.FbUserName,
-		AccessToken:    req.AccessToken,
+		AccessToken:    accessToken,
 		MaxPages:       5,
 		MaxPostsPerDay: 20,
 		Notes:          req.Notes,
@@ -113,11 +122,15 @@ func (h *Handler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 	if req.MaxPostsPerDay > 0 {
 		account.MaxPostsPerDay = req.MaxPostsPerDay
 	}
+	if req.MaxStoriesPerDay > 0 {
+		account.MaxStoriesPerDay = req.MaxStoriesPerDay
+	}
 
 	if err := h.store.CreateAccount(account); err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to create account: "+err.Error())
 		return
 	}
+	h.recordAccountToken(r.Context(), account)
 
 	respondJSON(w, http.StatusCreated, account)
 }
@@ -138,6 +151,9 @@ func (h *Handler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
 		MaxPostsPerDay int    `json:"max_posts_per_day"`
 		Status         string `json:"status"`
 		Notes          string `json:"notes"`
+
+		// nil = giữ nguyên, 0 = story tính chung vào max_posts_per_day
+		MaxStoriesPerDay *int `json:"max_stories_per_day"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -154,6 +170,9 @@ func (h *Handler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
 	if req.MaxPostsPerDay > 0 {
 		account.MaxPostsPerDay = req.MaxPostsPerDay
 	}
+	if req.MaxStoriesPerDay != nil && *req.MaxStoriesPerDay >= 0 {
+		account.MaxStoriesPerDay = *req.MaxStoriesPerDay
+	}
 	if req.Status != "" {
 		account.Status = req.Status
 	}
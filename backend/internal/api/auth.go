@@ -45,6 +45,9 @@ func (h *Handler) FacebookCallback(w http.ResponseWriter, r *http.Request) {
 	
 	log.Printf("✅ Got user access token: %s...", userToken[:20])
 	
+	// Đổi sang long-lived token: page token lấy bằng token này sẽ không hết hạn
+	userToken = h.extendUserToken(r.Context(), userToken)
+	
 	// Get Facebook user info
 	fbUser, err := h.fbClient.GetUserInfo(userToken)
 	fbUserID := "unknown"
@@ -89,6 +92,9 @@ func (h *Handler) FacebookCallback(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	
+	// Lưu hạn + quyền của user token (debug_token)
+	h.recordAccountToken(r.Context(), account)
+	
 	// Get user's pages
 	pages, err := h.fbClient.GetUserPages(userToken)
 	if err != nil {
@@ -105,7 +111,7 @@ func (h *Handler) FacebookCallback(w http.ResponseWriter, r *http.Request) {
 		// Check if page has CREATE_CONTENT permission
 		hasCreateContent := false
 		for _, task := range pageInfo.Tasks {
-			if task == "CREATE_CONTENT" {
+			if task == db.PostingTask {
 				hasCreateContent = true
 				break
 			}
@@ -122,6 +128,10 @@ func (h *Handler) FacebookCallback(w http.ResponseWriter, r *http.Request) {
 			"tasks":               pageInfo.Tasks,
 			"can_post":            hasCreateContent,
 		}
+		if ig := pageInfo.InstagramBusinessAccount; ig != nil {
+			pageData["instagram_account_id"] = ig.ID
+			pageData["instagram_username"] = ig.Username
+		}
 		responsePages = append(responsePages, pageData)
 	}
 	
@@ -143,7 +153,11 @@ func (h *Handler) FacebookCallback(w http.ResponseWriter, r *http.Request) {
 			}
 			if err := h.store.CreateOrUpdatePage(page); err != nil {
 				log.Printf("⚠️ Warning: Failed to update token for page %s: %v", page.PageName, err)
+				continue
 			}
+			h.savePageTasks(page, pageInfo.Tasks)
+			h.recordPageToken(r.Context(), page)
+			h.saveInstagramAccount(page, pageInfo.InstagramBusinessAccount)
 		}
 	}
 	
@@ -196,7 +210,8 @@ func (h *Handler) SaveSelectedPages(w http.ResponseWriter, r *http.Request) {
 			PageName          string `json:"page_name"`
 			AccessToken       string `json:"access_token"`
 			Category          string `json:"category"`
-			ProfilePictureURL string `json:"profile_picture_url"`
+			ProfilePictureURL string   `json:"profile_picture_url"`
+			Tasks             []string `json:"tasks"`
 		} `json:"pages"`
 	}
 
@@ -235,6 +250,10 @@ func (h *Handler) SaveSelectedPages(w http.ResponseWriter, r *http.Request) {
 			respondError(w, http.StatusInternalServerError, "Failed to save page: "+err.Error())
 			return
 		}
+		h.savePageTasks(page, pageData.Tasks)
+		h.recordPageToken(r.Context(), page)
+		h.subscribePageWebhooks(r.Context(), page)
+		h.recordInstagramAccount(r.Context(), page)
 
 		// Assign page to account if account_id provided
 		if req.AccountID != "" && page.ID != "" {
@@ -16,9 +16,14 @@ type Handler struct {
 }
 
 func NewHandler(store *db.Store, database *sql.DB) *Handler {
+	return NewHandlerWithClient(store, database, facebook.NewClient())
+}
+
+// NewHandlerWithClient tạo handler với Facebook client tùy chỉnh (vd: fake server khi test)
+func NewHandlerWithClient(store *db.Store, database *sql.DB, fbClient *facebook.Client) *Handler {
 	return &Handler{
 		store:    store,
-		fbClient: facebook.NewClient(),
+		fbClient: fbClient,
 		db:       database,
 	}
 }
@@ -34,6 +39,19 @@ func respondError(w http.ResponseWriter, status int, message string) {
 	respondJSON(w, status, map[string]string{"error": message})
 }
 
+// multiStatus mã HTTP cho thao tác trên nhiều page: 200 nếu tất cả thành công,
+// 207 nếu chỉ một phần thành công, 502 nếu mọi page đều lỗi (lỗi từ Facebook)
+func multiStatus(succeeded, failed int) int {
+	switch {
+	case failed == 0:
+		return http.StatusOK
+	case succeeded == 0:
+		return http.StatusBadGateway
+	default:
+		return http.StatusMultiStatus
+	}
+}
+
 func getQueryInt(r *http.Request, key string, defaultValue int) int {
 	val := r.URL.Query().Get(key)
 	if val == "" {
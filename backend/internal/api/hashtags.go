@@ -1,19 +1,42 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+	"log"
 	"net/http"
+	"strings"
 	"time"
+	"unicode"
+)
+
+// Tìm hashtag: số liệu Instagram được cache lại, tra lại sau hashtagCacheTTL
+const (
+	hashtagCacheTTL        = 6 * time.Hour
+	hashtagSuggestionLimit = 10
 )
 
 type HashtagSearchResult struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	MediaCount int64 `json:"media_count"`
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	MediaCount int64  `json:"media_count"` // instagram/cache: số bài 24h gần nhất (tối đa 50); stats: số bài mình đã đăng
+
+	TopMediaCount    int     `json:"top_media_count,omitempty"`
+	RecentMediaCount int     `json:"recent_media_count,omitempty"`
+	Posts            int     `json:"posts,omitempty"`
+	AvgReach         float64 `json:"avg_reach,omitempty"`
+	Source           string  `json:"source"` // "instagram" | "cache" | "stats"
 }
 
 type HashtagSearchResponse struct {
 	Data []HashtagSearchResult `json:"data"`
+
+	// Tài khoản IG dùng để tra và số hashtag mới còn tra được trong 7 ngày
+	InstagramUsername string `json:"instagram_username,omitempty"`
+	SearchesRemaining *int   `json:"searches_remaining,omitempty"`
+	RateLimited       bool   `json:"rate_limited,omitempty"`
 }
 
 type HashtagSet struct {
@@ -24,56 +47,198 @@ type HashtagSet struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
-// SearchHashtags searches for hashtags using Instagram Graph API
+// SearchHashtags GET /api/hashtags/search?q=&page_id= - Tìm hashtag.
+// Có tài khoản Instagram Business (của page_id hoặc page active đầu tiên có liên kết) thì tra
+// Instagram Graph API (giới hạn 30 hashtag / 7 ngày), kèm hashtag đã tra trước đó;
+// luôn bổ sung thống kê hashtag trong các bài đã đăng của mình.
 func (h *Handler) SearchHashtags(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	if query == "" {
 		http.Error(w, "Query parameter 'q' is required", http.StatusBadRequest)
 		return
 	}
+	name := normalizeHashtag(query)
+	if name == "" {
+		respondError(w, http.StatusBadRequest, "Invalid hashtag: "+query)
+		return
+	}
 
-	// Return mock data for now (Instagram API integration can be added later)
-	mockResults := h.getMockHashtagResults(query)
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(mockResults)
+	resp := HashtagSearchResponse{Data: make([]HashtagSearchResult, 0)}
+	seen := make(map[string]bool)
+	add := func(result HashtagSearchResult) {
+		if !seen[result.Name] {
+			seen[result.Name] = true
+			resp.Data = append(resp.Data, result)
+		}
+	}
+
+	page, err := h.instagramSearchPage(r.URL.Query().Get("page_id"))
+	if err != nil {
+		log.Printf("⚠️ Failed to find an instagram account for hashtag search: %v", err)
+	}
+	if page != nil {
+		resp.InstagramUsername = page.InstagramUsername
+
+		result, rateLimited := h.lookupInstagramHashtag(r.Context(), page, name)
+		resp.RateLimited = rateLimited
+		if result != nil {
+			add(*result)
+		}
+
+		cached, err := h.store.SearchHashtagCache(page.InstagramAccountID, name, hashtagSuggestionLimit)
+		if err != nil {
+			log.Printf("⚠️ Failed to read hashtag cache: %v", err)
+		}
+		for _, c := range cached {
+			add(hashtagFromCache(c, "cache"))
+		}
+
+		if used, err := h.store.CountHashtagSearches(page.InstagramAccountID, facebook.HashtagSearchWindow); err == nil {
+			remaining := facebook.MaxHashtagSearchesPerWeek - used
+			if remaining < 0 {
+				remaining = 0
+			}
+			resp.SearchesRemaining = &remaining
+		}
+	}
+
+	// Thống kê hashtag trong các bài đã đăng (dùng được cả khi không có Instagram)
+	usage, err := h.store.GetHashtagUsage(name, hashtagSuggestionLimit)
+	if err != nil {
+		log.Printf("⚠️ Failed to read hashtag usage: %v", err)
+	}
+	for _, u := range usage {
+		add(HashtagSearchResult{
+			ID:         "stats:" + u.Name,
+			Name:       u.Name,
+			MediaCount: int64(u.Posts),
+			Posts:      u.Posts,
+			AvgReach:   u.AvgReach,
+			Source:     "stats",
+		})
+	}
+
+	respondJSON(w, http.StatusOK, resp)
 }
 
-// Mock data for development/fallback
-func (h *Handler) getMockHashtagResults(query string) HashtagSearchResponse {
-	mockData := map[string][]HashtagSearchResult{
-		"marketing": {
-			{ID: "1", Name: "marketing", MediaCount: 15234567},
-			{ID: "2", Name: "marketingdigital", MediaCount: 8765432},
-			{ID: "3", Name: "marketingtips", MediaCount: 3456789},
-			{ID: "4", Name: "marketingstrategy", MediaCount: 2345678},
-		},
-		"business": {
-			{ID: "5", Name: "business", MediaCount: 25678901},
-			{ID: "6", Name: "businessman", MediaCount: 12345678},
-			{ID: "7", Name: "businessowner", MediaCount: 5678901},
-		},
-		"vietnam": {
-			{ID: "8", Name: "vietnam", MediaCount: 18765432},
-			{ID: "9", Name: "vietnamtravel", MediaCount: 9876543},
-			{ID: "10", Name: "vietnamfood", MediaCount: 4567890},
-		},
-	}
-
-	// Return mock data based on query
-	for key, results := range mockData {
-		if len(query) > 0 && len(key) >= len(query) && key[:len(query)] == query {
-			return HashtagSearchResponse{Data: results}
+// lookupInstagramHashtag lấy số liệu hashtag trên Instagram, ưu tiên cache còn hạn.
+// Hashtag id đã biết thì không cần ig_hashtag_search (không tốn lượt);
+// hết lượt tra hoặc Instagram lỗi thì trả về cache cũ nếu có. nil = không có trên Instagram.
+func (h *Handler) lookupInstagramHashtag(ctx context.Context, page *db.Page, name string) (*HashtagSearchResult, bool) {
+	igID := page.InstagramAccountID
+	cached, err := h.store.GetHashtagCache(igID, name, facebook.HashtagSearchWindow)
+	if err != nil {
+		log.Printf("⚠️ Failed to read hashtag cache for #%s: %v", name, err)
+		cached = nil
+	}
+	if cached != nil && cached.Age < hashtagCacheTTL {
+		return staleHashtag(cached), false
+	}
+
+	entry := &db.HashtagCache{InstagramAccountID: igID, Name: name}
+	searched := false
+	if cached != nil && cached.HashtagID != "" {
+		entry.HashtagID = cached.HashtagID
+	} else {
+		// Hashtag mới (chưa tra trong 7 ngày) tốn 1 lượt trong hạn mức
+		if cached == nil || !cached.InSearchWindow {
+			used, err := h.store.CountHashtagSearches(igID, facebook.HashtagSearchWindow)
+			if err == nil && used >= facebook.MaxHashtagSearchesPerWeek {
+				log.Printf("⏸️ Instagram @%s used all %d hashtag searches this week", page.InstagramUsername, used)
+				return staleHashtag(cached), true
+			}
 		}
+
+		entry.HashtagID, err = h.fbClient.SearchHashtag(ctx, igID, page.AccessToken, name)
+		if err != nil {
+			log.Printf("❌ Instagram hashtag search for #%s failed: %v", name, err)
+			return staleHashtag(cached), facebook.IsRateLimit(err)
+		}
+		searched = true
+	}
+
+	if entry.HashtagID != "" {
+		stats, err := h.fbClient.GetHashtagStats(ctx, igID, page.AccessToken, entry.HashtagID, name)
+		if err != nil {
+			log.Printf("❌ Failed to read instagram media of #%s: %v", name, err)
+			// Vẫn lưu hashtag id vừa tra để lần sau không tốn thêm lượt
+			if searched {
+				if cached != nil {
+					entry.TopMediaCount, entry.RecentMediaCount = cached.TopMediaCount, cached.RecentMediaCount
+				}
+				h.saveHashtagCache(entry, searched)
+			}
+			return staleHashtag(cached), facebook.IsRateLimit(err)
+		}
+		entry.TopMediaCount, entry.RecentMediaCount = stats.TopMediaCount, stats.RecentMediaCount
 	}
 
-	// Default mock results
-	return HashtagSearchResponse{
-		Data: []HashtagSearchResult{
-			{ID: "default1", Name: query, MediaCount: 1234567},
-			{ID: "default2", Name: query + "s", MediaCount: 567890},
-			{ID: "default3", Name: query + "daily", MediaCount: 234567},
-		},
+	h.saveHashtagCache(entry, searched)
+	if entry.HashtagID == "" {
+		return nil, false
+	}
+	result := hashtagFromCache(*entry, "instagram")
+	return &result, false
+}
+
+func (h *Handler) saveHashtagCache(entry *db.HashtagCache, searched bool) {
+	if err := h.store.SaveHashtagCache(entry, searched); err != nil {
+		log.Printf("⚠️ Failed to cache hashtag #%s: %v", entry.Name, err)
+	}
+}
+
+// instagramSearchPage lấy page theo id, hoặc page active đầu tiên có liên kết Instagram.
+// nil nếu không có tài khoản Instagram nào để tra.
+func (h *Handler) instagramSearchPage(id string) (*db.Page, error) {
+	if id != "" {
+		page, err := h.store.GetPageByID(id)
+		if err != nil || page == nil || page.InstagramAccountID == "" {
+			return nil, err
+		}
+		return page, nil
+	}
+
+	pages, err := h.store.GetActivePages()
+	if err != nil {
+		return nil, err
+	}
+	for i := range pages {
+		if pages[i].InstagramAccountID != "" {
+			return &pages[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// staleHashtag trả về hashtag từ cache (nil nếu không có cache hoặc hashtag không có trên Instagram)
+func staleHashtag(cached *db.HashtagCache) *HashtagSearchResult {
+	if cached == nil || cached.HashtagID == "" {
+		return nil
+	}
+	result := hashtagFromCache(*cached, "cache")
+	return &result
+}
+
+func hashtagFromCache(c db.HashtagCache, source string) HashtagSearchResult {
+	return HashtagSearchResult{
+		ID:               c.HashtagID,
+		Name:             c.Name,
+		MediaCount:       int64(c.RecentMediaCount),
+		TopMediaCount:    c.TopMediaCount,
+		RecentMediaCount: c.RecentMediaCount,
+		Source:           source,
+	}
+}
+
+// normalizeHashtag bỏ dấu # và khoảng trắng, chuyển chữ thường; rỗng nếu có ký tự không hợp lệ
+func normalizeHashtag(q string) string {
+	name := strings.ToLower(strings.TrimLeft(strings.TrimSpace(q), "#"))
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return ""
+		}
 	}
+	return name
 }
 
 // GetSavedHashtags returns user's saved hashtag sets
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"fbscheduler/internal/db"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// postPageInsights là insights của bài trên 1 page: snapshot mới nhất + lịch sử
+type postPageInsights struct {
+	PostLogID      string           `json:"post_log_id"`
+	PageID         string           `json:"page_id"`
+	PageName       string           `json:"page_name"`
+	FacebookPostID string           `json:"facebook_post_id"`
+	Latest         db.PostInsight   `json:"latest"`
+	History        []db.PostInsight `json:"history"`
+}
+
+// GetPostInsights trả về insights (time series) của 1 bài trên từng page và tổng theo snapshot mới nhất
+func (h *Handler) GetPostInsights(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	post, err := h.store.GetPostByID(id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch post")
+		return
+	}
+	if post == nil {
+		respondError(w, http.StatusNotFound, "Post not found")
+		return
+	}
+
+	snapshots, err := h.store.GetPostInsights(id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch insights")
+		return
+	}
+
+	// Gom snapshot theo post_log (mỗi page 1 bài), giữ thứ tự cũ → mới
+	pages := make([]*postPageInsights, 0)
+	byLog := make(map[string]*postPageInsights)
+	for _, s := range snapshots {
+		p, ok := byLog[s.PostLogID]
+		if !ok {
+			p = &postPageInsights{
+				PostLogID:      s.PostLogID,
+				PageID:         s.PageID,
+				PageName:       s.PageName,
+				FacebookPostID: s.FacebookPostID,
+			}
+			byLog[s.PostLogID] = p
+			pages = append(pages, p)
+		}
+		p.History = append(p.History, s)
+		p.Latest = s
+	}
+
+	var totals db.PostInsight
+	for _, p := range pages {
+		totals.Impressions += p.Latest.Impressions
+		totals.Reach += p.Latest.Reach
+		totals.Reactions += p.Latest.Reactions
+		totals.Comments += p.Latest.Comments
+		totals.Shares += p.Latest.Shares
+		totals.Clicks += p.Latest.Clicks
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"post_id": id,
+		"totals": map[string]int64{
+			"impressions": totals.Impressions,
+			"reach":       totals.Reach,
+			"reactions":   totals.Reactions,
+			"comments":    totals.Comments,
+			"shares":      totals.Shares,
+			"clicks":      totals.Clicks,
+		},
+		"pages": pages,
+	})
+}
+
+// GetPagesInsights tổng hợp insights theo từng page cho bài đăng trong ?days= ngày gần nhất (mặc định 30)
+func (h *Handler) GetPagesInsights(w http.ResponseWriter, r *http.Request) {
+	days := getQueryInt(r, "days", 30)
+
+	summaries, err := h.store.GetPageInsightsSummaries("", days)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch page insights")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, summaries)
+}
+
+// GetPageInsights tổng hợp insights của 1 page cho bài đăng trong ?days= ngày gần nhất (mặc định 30)
+func (h *Handler) GetPageInsights(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	days := getQueryInt(r, "days", 30)
+
+	summaries, err := h.store.GetPageInsightsSummaries(id, days)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch page insights")
+		return
+	}
+
+	summary := db.PageInsightsSummary{PageID: id}
+	if len(summaries) > 0 {
+		summary = summaries[0]
+	}
+	respondJSON(w, http.StatusOK, summary)
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+	"fbscheduler/internal/scheduler"
+	"fmt"
+	"log"
+	"time"
+)
+
+// publishInstagram đăng bài lên Instagram liên kết với từng page, ghi post_logs (platform instagram).
+// Bài chỉ đăng Instagram thì tạo thêm scheduled_post để hiển thị trong lịch đăng.
+// Trả về kết quả từng page và true nếu có page lỗi.
+func (h *Handler) publishInstagram(ctx context.Context, post *db.Post, pageIDs []string) ([]map[string]interface{}, bool) {
+	results := make([]map[string]interface{}, 0, len(pageIDs))
+	hasError := false
+
+	for _, pageID := range pageIDs {
+		logEntry := &db.PostLog{
+			PostID:   post.ID,
+			PageID:   pageID,
+			Platform: db.PlatformInstagram,
+		}
+		result := map[string]interface{}{
+			"page_id":  pageID,
+			"platform": db.PlatformInstagram,
+		}
+
+		var mediaID string
+		page, err := h.store.GetPageByID(pageID)
+		if err != nil || page == nil {
+			err = fmt.Errorf("page not found")
+		} else {
+			result["page_name"] = page.PageName
+			result["instagram_username"] = page.InstagramUsername
+			account, _ := h.store.GetPrimaryAccountForPage(pageID)
+			if err = scheduler.CheckPostingPermission(page); err == nil {
+				err = scheduler.CheckPageToken(page)
+			}
+			if err == nil {
+				err = scheduler.CheckUsagePause(h.store, pageID, account)
+			}
+			if err == nil {
+				mediaID, err = scheduler.PublishInstagram(ctx, h.fbClient, page, page.AccessToken, post)
+				scheduler.ApplyUsage(h.store, h.fbClient, pageID, page.PageName, account, page.AccessToken)
+			}
+		}
+
+		status := "completed"
+		if err != nil {
+			fmt.Printf("❌ Failed to post to instagram of page %s: %v\n", pageID, err)
+			logEntry.Status = "failed"
+			logEntry.ErrorMessage = err.Error()
+			result["status"] = "failed"
+			result["error"] = err.Error()
+			status = "failed"
+			hasError = true
+		} else {
+			fmt.Printf("✅ Successfully posted to instagram @%s: %s\n", page.InstagramUsername, mediaID)
+			logEntry.Status = "success"
+			logEntry.FacebookPostID = mediaID
+			result["status"] = "success"
+			result["instagram_media_id"] = mediaID
+		}
+		// Page không tồn tại thì không ghi được log (page_id là khóa ngoại)
+		if page != nil {
+			h.store.CreatePostLog(logEntry)
+		}
+
+		if page != nil && !post.TargetsFacebook() {
+			scheduledPost := &db.ScheduledPost{
+				PostID:        post.ID,
+				PageID:        pageID,
+				ScheduledTime: time.Now(),
+				Status:        status,
+				MaxRetries:    0,
+			}
+			if account, _ := h.store.GetPrimaryAccountForPage(pageID); account != nil {
+				scheduledPost.AccountID = &account.ID
+			}
+			h.store.CreateScheduledPost(scheduledPost)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, hasError
+}
+
+// checkInstagramPages kiểm tra các page đã liên kết tài khoản Instagram Business, trả về thông báo lỗi nếu chưa
+func (h *Handler) checkInstagramPages(pageIDs []string) string {
+	for _, pageID := range pageIDs {
+		page, err := h.store.GetPageByID(pageID)
+		if err != nil || page == nil {
+			return "Page not found: " + pageID
+		}
+		if page.InstagramAccountID == "" {
+			return "Page " + page.PageName + " has no linked Instagram Business account"
+		}
+	}
+	return ""
+}
+
+// recordInstagramAccount tra tài khoản Instagram Business liên kết với page (page token) và lưu lại
+func (h *Handler) recordInstagramAccount(ctx context.Context, page *db.Page) {
+	account, err := h.fbClient.GetInstagramAccount(ctx, page.PageID, page.AccessToken)
+	if err != nil {
+		log.Printf("⚠️ Could not look up instagram account of page %s: %v", page.PageName, err)
+		return
+	}
+	h.saveInstagramAccount(page, account)
+}
+
+// saveInstagramAccount lưu tài khoản Instagram liên kết với page (nil = page không còn liên kết)
+func (h *Handler) saveInstagramAccount(page *db.Page, account *facebook.InstagramAccount) {
+	igID, igUsername := "", ""
+	if account != nil {
+		igID, igUsername = account.ID, account.Username
+	}
+	if err := h.store.SetPageInstagramAccount(page.ID, igID, igUsername); err != nil {
+		log.Printf("⚠️ Failed to save instagram account of page %s: %v", page.PageName, err)
+		return
+	}
+	page.InstagramAccountID, page.InstagramUsername = igID, igUsername
+	if igID != "" {
+		log.Printf("📸 Page %s is linked to instagram @%s", page.PageName, igUsername)
+	}
+}
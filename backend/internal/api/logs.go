@@ -3,10 +3,11 @@ package api
 import "net/http"
 
 func (h *Handler) GetPostLogs(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status") // success (gồm partial), partial, failed
 	limit := getQueryInt(r, "limit", 50)
 	offset := getQueryInt(r, "offset", 0)
 	
-	logs, err := h.store.GetPostLogs(limit, offset)
+	logs, err := h.store.GetPostLogs(status, limit, offset)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to fetch logs")
 		return
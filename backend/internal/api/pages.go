@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -39,3 +40,27 @@ func (h *Handler) TogglePage(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Page status updated successfully"})
 }
+
+// SetPageNativeScheduling PATCH /api/pages/:id/native-scheduling - Bật/tắt mặc định hẹn giờ trên Facebook
+func (h *Handler) SetPageNativeScheduling(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req struct {
+		Enabled bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.store.SetPageNativeScheduling(id, req.Enabled); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update page: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"message":           "Page scheduling mode updated",
+		"native_scheduling": req.Enabled,
+	})
+}
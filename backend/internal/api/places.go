@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// SearchPlaces GET /api/places/search?q=&page_id=&lat=&lng=&distance=&limit= - Tìm địa điểm để check-in.
+// Dùng token của page_id, bỏ trống thì dùng page active đầu tiên.
+func (h *Handler) SearchPlaces(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	search := facebook.PlaceSearch{
+		Query:     strings.TrimSpace(query.Get("q")),
+		Latitude:  getQueryFloat(r, "lat"),
+		Longitude: getQueryFloat(r, "lng"),
+		Distance:  getQueryInt(r, "distance", 0),
+		Limit:     getQueryInt(r, "limit", facebook.DefaultPlaceSearchLimit),
+	}
+
+	page, err := h.placeSearchPage(query.Get("page_id"))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch page")
+		return
+	}
+	if page == nil {
+		respondError(w, http.StatusBadRequest, "No page available to search places with")
+		return
+	}
+
+	places, err := h.fbClient.SearchPlaces(r.Context(), search, page.AccessToken)
+	if err != nil {
+		if errors.Is(err, facebook.ErrInvalidRequest) {
+			respondError(w, http.StatusBadRequest, "q or lat/lng is required")
+			return
+		}
+		log.Printf("❌ Place search failed: %v", err)
+		respondError(w, http.StatusBadGateway, "Failed to search places: "+err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, places)
+}
+
+// placeSearchPage lấy page theo id, hoặc page active đầu tiên nếu id rỗng
+func (h *Handler) placeSearchPage(id string) (*db.Page, error) {
+	if id != "" {
+		return h.store.GetPageByID(id)
+	}
+	pages, err := h.store.GetActivePages()
+	if err != nil || len(pages) == 0 {
+		return nil, err
+	}
+	return &pages[0], nil
+}
+
+// GetSavedLocations GET /api/pages/{id}/locations - Địa điểm đã lưu của page
+func (h *Handler) GetSavedLocations(w http.ResponseWriter, r *http.Request) {
+	locations, err := h.store.GetSavedLocations(mux.Vars(r)["id"])
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch locations")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, locations)
+}
+
+// SaveLocation POST /api/pages/{id}/locations - Lưu địa điểm cho page
+func (h *Handler) SaveLocation(w http.ResponseWriter, r *http.Request) {
+	var location db.SavedLocation
+	if err := json.NewDecoder(r.Body).Decode(&location); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if location.PlaceID == "" || location.Name == "" {
+		respondError(w, http.StatusBadRequest, "place_id and name are required")
+		return
+	}
+
+	location.PageID = mux.Vars(r)["id"]
+	if err := h.store.SaveLocation(&location); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to save location")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, location)
+}
+
+// DeleteSavedLocation DELETE /api/pages/{id}/locations/{locationId} - Xóa địa điểm đã lưu
+func (h *Handler) DeleteSavedLocation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	deleted, err := h.store.DeleteSavedLocation(vars["id"], vars["locationId"])
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete location")
+		return
+	}
+	if !deleted {
+		respondError(w, http.StatusNotFound, "Location not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Location deleted"})
+}
+
+// getQueryFloat đọc số thực từ query, 0 nếu thiếu hoặc sai định dạng
+func getQueryFloat(r *http.Request, key string) float64 {
+	val, err := strconv.ParseFloat(r.URL.Query().Get(key), 64)
+	if err != nil {
+		return 0
+	}
+	return val
+}
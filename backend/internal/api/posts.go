@@ -1,11 +1,19 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+	"fbscheduler/internal/scheduler"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -18,6 +26,11 @@ func (h *Handler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	if err := validatePost(&post); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	
 	if post.Status == "" {
 		post.Status = "draft"
 	}
@@ -71,6 +84,11 @@ func (h *Handler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	if err := validatePost(&post); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	
 	post.ID = id
 	if err := h.store.UpdatePost(&post); err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to update post")
@@ -84,6 +102,24 @@ func (h *Handler) DeletePost(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 	
+	// ?facebook=true: xóa luôn bài trên các page đã đăng, lỗi thì giữ bài để thử lại
+	if r.URL.Query().Get("facebook") == "true" {
+		results, err := h.deleteFromFacebook(r.Context(), id)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to fetch published pages")
+			return
+		}
+		for _, result := range results {
+			if result.Status != "success" {
+				respondJSON(w, http.StatusBadGateway, map[string]interface{}{
+					"error":   "Failed to delete post from some pages on Facebook",
+					"results": results,
+				})
+				return
+			}
+		}
+	}
+	
 	if err := h.store.DeletePost(id); err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to delete post")
 		return
@@ -92,6 +128,9 @@ func (h *Handler) DeletePost(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Post deleted"})
 }
 
+// firstCommentConcurrency giới hạn số comment đầu tiên đăng song song sau khi publish
+const firstCommentConcurrency = 5
+
 // PublishPost publishes a post immediately to selected pages
 func (h *Handler) PublishPost(w http.ResponseWriter, r *http.Request) {
 	var req struct {
@@ -101,6 +140,33 @@ func (h *Handler) PublishPost(w http.ResponseWriter, r *http.Request) {
 		PageIDs   []string `json:"page_ids"`
 		Privacy   string   `json:"privacy"`
 		PostMode  string   `json:"post_mode"` // "album" | "individual"
+		
+		// Bài đăng dạng link (không kèm media)
+		LinkURL         string `json:"link_url"`
+		LinkName        string `json:"link_name"`
+		LinkDescription string `json:"link_description"`
+		LinkPicture     string `json:"link_picture"`
+		
+		// Comment đầu tiên tự động đăng sau bài
+		FirstComment      string `json:"first_comment"`
+		FirstCommentImage string `json:"first_comment_image"`
+		
+		// Check-in địa điểm và tag người (cần place_id)
+		PlaceID string   `json:"place_id"`
+		Tags    []string `json:"tags"`
+		
+		// "facebook" | "instagram" (rỗng = chỉ facebook)
+		Platforms []string `json:"platforms"`
+		
+		// Caption riêng cho từng ảnh (album), in caption lên ảnh
+		MediaCaptions []string `json:"media_captions"`
+		BurnCaptions  bool     `json:"burn_captions"`
+		AlbumName     string   `json:"album_name"`
+		
+		// Giới hạn người xem / ưu tiên trên news feed, bài ẩn (dark post)
+		Targeting     *db.Audience `json:"targeting"`
+		FeedTargeting *db.Audience `json:"feed_targeting"`
+		Unpublished   bool         `json:"unpublished"`
 	}
 	
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -116,11 +182,56 @@ func (h *Handler) PublishPost(w http.ResponseWriter, r *http.Request) {
 	fmt.Printf("   PageIDs: %v\n", req.PageIDs)
 	fmt.Printf("   Privacy: %s\n", req.Privacy)
 	fmt.Printf("   PostMode: %s\n", req.PostMode)
+	fmt.Printf("   LinkURL: %s\n", req.LinkURL)
+	fmt.Printf("   PlaceID: %s\n", req.PlaceID)
+	fmt.Printf("   Platforms: %v\n", req.Platforms)
 	
 	// Validate
-	if req.Content == "" && len(req.MediaURLs) == 0 {
+	if req.Content == "" && len(req.MediaURLs) == 0 && req.LinkURL == "" {
 		fmt.Printf("❌ PublishPost: No content or media\n")
-		respondError(w, http.StatusBadRequest, "Content or media is required")
+		respondError(w, http.StatusBadRequest, "Content, media or link is required")
+		return
+	}
+	
+	if msg := validateLinkPost(req.LinkURL, req.LinkName, req.LinkDescription, req.LinkPicture, len(req.MediaURLs)); msg != "" {
+		fmt.Printf("❌ PublishPost: %s\n", msg)
+		respondError(w, http.StatusBadRequest, msg)
+		return
+	}
+	
+	if msg := validateMediaType(req.MediaType, len(req.MediaURLs)); msg != "" {
+		fmt.Printf("❌ PublishPost: %s\n", msg)
+		respondError(w, http.StatusBadRequest, msg)
+		return
+	}
+	
+	if req.FirstCommentImage != "" && !isHTTPURL(req.FirstCommentImage) {
+		respondError(w, http.StatusBadRequest, "first_comment_image must be a valid http(s) URL")
+		return
+	}
+	
+	if msg := validatePlace(req.PlaceID, req.Tags, req.MediaType); msg != "" {
+		fmt.Printf("❌ PublishPost: %s\n", msg)
+		respondError(w, http.StatusBadRequest, msg)
+		return
+	}
+	
+	if msg := validatePlatforms(req.Platforms, req.MediaType, req.MediaURLs, req.LinkURL); msg != "" {
+		fmt.Printf("❌ PublishPost: %s\n", msg)
+		respondError(w, http.StatusBadRequest, msg)
+		return
+	}
+	
+	if msg := validateCaptions(req.MediaCaptions, req.BurnCaptions, req.MediaType, len(req.MediaURLs), req.PlaceID); msg != "" {
+		fmt.Printf("❌ PublishPost: %s\n", msg)
+		respondError(w, http.StatusBadRequest, msg)
+		return
+	}
+	
+	unpublished, msg := privacyUnpublished(req.Privacy)
+	if msg != "" {
+		fmt.Printf("❌ PublishPost: %s\n", msg)
+		respondError(w, http.StatusBadRequest, msg)
 		return
 	}
 	
@@ -135,160 +246,275 @@ func (h *Handler) PublishPost(w http.ResponseWriter, r *http.Request) {
 		Content:   req.Content,
 		MediaURLs: req.MediaURLs,
 		MediaType: req.MediaType,
+		LinkURL:   req.LinkURL,
 		Status:    "published",
+		
+		LinkName:        req.LinkName,
+		LinkDescription: req.LinkDescription,
+		LinkPicture:     req.LinkPicture,
+		
+		FirstComment:      req.FirstComment,
+		FirstCommentImage: req.FirstCommentImage,
+		
+		PlaceID: req.PlaceID,
+		Tags:    req.Tags,
+		
+		Platforms: req.Platforms,
+		
+		MediaCaptions: req.MediaCaptions,
+		BurnCaptions:  req.BurnCaptions,
+		AlbumName:     req.AlbumName,
+		
+		Targeting:     normalizeAudience(req.Targeting),
+		FeedTargeting: normalizeAudience(req.FeedTargeting),
+		Unpublished:   req.Unpublished || unpublished,
 	}
 	
-	fmt.Printf("💾 Creating post record...\n")
-	if err := h.store.CreatePost(post); err != nil {
-		fmt.Printf("❌ Failed to create post: %v\n", err)
-		respondError(w, http.StatusInternalServerError, "Failed to create post")
+	if msg := validateAudience(post); msg != "" {
+		fmt.Printf("❌ PublishPost: %s\n", msg)
+		respondError(w, http.StatusBadRequest, msg)
 		return
 	}
-	fmt.Printf("✅ Post created with ID: %s\n", post.ID)
-	
-	// Publish to each page concurrently
-	fmt.Printf("⚡ Publishing to %d pages concurrently...\n", len(req.PageIDs))
 	
+	type failedImage struct {
+		imageIdx int
+		err      error
+	}
 	type publishResult struct {
 		pageID       string
 		pageName     string
 		fbPostID     string
 		err          error
 		index        int
+		commentID    string
+		commentErr   error // Bài đã đăng nhưng comment đầu tiên lỗi
+		published    []*facebook.PublishResult // Bài đã đăng (individual mode: mỗi ảnh 1 bài)
+		details      []db.PostLog              // Permalink, ID ảnh/video đọc lại của từng bài
+		failed       []failedImage             // Individual mode: ảnh đăng lỗi trong khi ảnh khác đã lên
 	}
 	
-	resultChan := make(chan publishResult, len(req.PageIDs))
+	// Bài chỉ đăng Instagram thì bỏ qua phần đăng Facebook
+	fbPageIDs := req.PageIDs
+	if !post.TargetsFacebook() {
+		fbPageIDs = nil
+	}
 	
-	// Pre-download media if needed (for video/images)
-	var mediaData [][]byte
-	if len(req.MediaURLs) > 0 && req.MediaType == "video" {
+	// Pre-download video ra file tạm để dùng lại cho nhiều page (không giữ trong RAM)
+	var mediaPaths []string
+	if len(fbPageIDs) > 0 && len(req.MediaURLs) > 0 && (req.MediaType == facebook.MediaTypeVideo || req.MediaType == facebook.MediaTypeReel) {
 		fmt.Printf("📥 Pre-downloading video to reuse across pages...\n")
 		for _, mediaURL := range req.MediaURLs {
-			resp, err := http.Get(mediaURL)
+			path, size, err := downloadToTempFile(mediaURL)
 			if err != nil {
 				respondError(w, http.StatusInternalServerError, "Failed to download media: "+err.Error())
 				return
 			}
-			data, err := io.ReadAll(resp.Body)
-			resp.Body.Close()
-			if err != nil {
-				respondError(w, http.StatusInternalServerError, "Failed to read media: "+err.Error())
+			defer os.Remove(path)
+			mediaPaths = append(mediaPaths, path)
+			fmt.Printf("✅ Downloaded %.2f MB\n", float64(size)/(1024*1024))
+		}
+		
+		// Kiểm tra reel 1 lần trước khi đăng lên nhiều page
+		if req.MediaType == facebook.MediaTypeReel {
+			if err := validateVideoFile(mediaPaths[0], req.MediaType); err != nil {
+				fmt.Printf("❌ PublishPost: %v\n", err)
+				respondError(w, http.StatusBadRequest, err.Error())
 				return
 			}
-			mediaData = append(mediaData, data)
-			fmt.Printf("✅ Downloaded %.2f MB\n", float64(len(data))/(1024*1024))
 		}
 	}
 	
-	// Post to all pages in parallel
-	for i, pageID := range req.PageIDs {
-		go func(idx int, pgID string, preloadedMedia [][]byte) {
-			// Get page info
-			page, err := h.store.GetPageByID(pgID)
-			if err != nil || page == nil {
-				resultChan <- publishResult{
-					pageID: pgID,
-					err:    fmt.Errorf("page not found"),
-					index:  idx,
-				}
-				return
-			}
+	// Không dùng r.Context() để việc đăng không bị hủy giữa chừng khi client ngắt kết nối
+	ctx := context.Background()
+	
+	// In caption lên ảnh 1 lần, dùng lại cho mọi page
+	var rendered [][]byte
+	if len(fbPageIDs) > 0 {
+		var err error
+		if rendered, err = scheduler.RenderCaptionOverlays(post); err != nil {
+			fmt.Printf("❌ PublishPost: %v\n", err)
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	
+	// Chỉ lưu bài sau khi tải, kiểm tra video và in caption xong: lỗi 400 ở các bước trên không để lại bài mồ côi
+	fmt.Printf("💾 Creating post record...\n")
+	if err := h.store.CreatePost(post); err != nil {
+		fmt.Printf("❌ Failed to create post: %v\n", err)
+		respondError(w, http.StatusInternalServerError, "Failed to create post")
+		return
+	}
+	fmt.Printf("✅ Post created with ID: %s\n", post.ID)
+	
+	// Ảnh có caption riêng → đăng dạng album (trừ chế độ individual: mỗi ảnh 1 bài)
+	individual := req.PostMode == "individual" && len(req.MediaURLs) > 1
+	albumName := ""
+	if !individual {
+		albumName = scheduler.PostAlbumName(post)
+	}
+	
+	// Gom request của mọi page (chế độ individual: mỗi ảnh 1 request) rồi đăng qua
+	// Graph batch API, thay vì mỗi page 1 goroutine với nhiều HTTP call
+	type requestOwner struct {
+		index    int // vị trí page trong fbPageIDs
+		imageIdx int
+	}
+	publishResults := make([]publishResult, len(fbPageIDs))
+	pageTokens := make([]string, len(fbPageIDs))
+	pageAccounts := make([]*db.FacebookAccount, len(fbPageIDs))
+	var publishReqs []facebook.PublishRequest
+	var owners []requestOwner
+	
+	for i, pgID := range fbPageIDs {
+		publishResults[i] = publishResult{pageID: pgID, index: i}
+		
+		page, err := h.store.GetPageByID(pgID)
+		if err != nil || page == nil {
+			publishResults[i].err = fmt.Errorf("page not found")
+			continue
+		}
+		publishResults[i].pageName = page.PageName
+		if err := scheduler.CheckPostingPermission(page); err != nil {
+			publishResults[i].err = err
+			continue
+		}
+		// Token page đã hỏng → không gọi Graph API, cần đăng nhập lại
+		if err := scheduler.CheckPageToken(page); err != nil {
+			publishResults[i].err = err
+			continue
+		}
+		// Page / nick đang tạm dừng theo usage thì không đăng tay (giống scheduler)
+		account, _ := h.store.GetPrimaryAccountForPage(pgID)
+		if err := scheduler.CheckUsagePause(h.store, pgID, account); err != nil {
+			publishResults[i].err = err
+			continue
+		}
+		pageAccounts[i] = account
+		pageTokens[i] = page.AccessToken
+		
+		pageName := page.PageName
+		publishReq := facebook.PublishRequest{
+			PageID:      page.PageID,
+			AccessToken: page.AccessToken,
+			Message:     req.Content,
+			MediaType:   req.MediaType,
+			Media:       scheduler.CaptionedMedia(post, buildMediaItems(req.MediaURLs, mediaPaths), rendered),
+			AlbumName:   albumName,
 			
-			// Handle individual mode (each image = separate post)
-			if req.PostMode == "individual" && len(req.MediaURLs) > 1 {
-				fmt.Printf("📸 Individual mode: Posting %d images separately to %s\n", len(req.MediaURLs), page.PageName)
-				var fbPostIDs []string
-				for imgIdx, mediaURL := range req.MediaURLs {
-					var singleFbPostID string
-					var singleErr error
-					
-					if len(preloadedMedia) > imgIdx {
-						singleFbPostID, singleErr = h.fbClient.PostToPageWithDataAndPlace(
-							page.PageID,
-							page.AccessToken,
-							req.Content,
-							[][]byte{preloadedMedia[imgIdx]},
-							req.MediaType,
-							"",
-						)
-					} else {
-						singleFbPostID, singleErr = h.fbClient.PostToPageWithPlace(
-							page.PageID,
-							page.AccessToken,
-							req.Content,
-							[]string{mediaURL},
-							req.MediaType,
-							"",
-						)
-					}
-					
-					if singleErr != nil {
-						err = fmt.Errorf("failed to post image %d: %v", imgIdx+1, singleErr)
-						break
-					}
-					fbPostIDs = append(fbPostIDs, singleFbPostID)
-				}
-				
-				resultChan <- publishResult{
-					pageID:   pgID,
-					pageName: page.PageName,
-					fbPostID: fmt.Sprintf("%d posts: %v", len(fbPostIDs), fbPostIDs),
-					err:      err,
-					index:    idx,
-				}
-				return
-			}
+			Link:            req.LinkURL,
+			LinkName:        req.LinkName,
+			LinkDescription: req.LinkDescription,
+			LinkPicture:     req.LinkPicture,
 			
-			// Album mode (default): Post all images in one post
-			var fbPostID string
-			if len(preloadedMedia) > 0 {
-				fbPostID, err = h.fbClient.PostToPageWithDataAndPlace(
-					page.PageID,
-					page.AccessToken,
-					req.Content,
-					preloadedMedia,
-					req.MediaType,
-					"",
-				)
-			} else {
-				fbPostID, err = h.fbClient.PostToPageWithPlace(
-					page.PageID,
-					page.AccessToken,
-					req.Content,
-					req.MediaURLs,
-					req.MediaType,
-					"",
-				)
-			}
+			Place: req.PlaceID,
+			Tags:  strings.Join(req.Tags, ","),
+			
+			Published:     scheduler.PostPublished(post),
+			Targeting:     scheduler.PostAudience(post.Targeting),
+			FeedTargeting: scheduler.PostAudience(post.FeedTargeting),
 			
-			resultChan <- publishResult{
-				pageID:   pgID,
-				pageName: page.PageName,
-				fbPostID: fbPostID,
-				err:      err,
-				index:    idx,
+			OnProgress: func(uploaded, total int64) {
+				fmt.Printf("📤 %s: uploaded %.0f%% of video\n", pageName, float64(uploaded)/float64(total)*100)
+			},
+		}
+		
+		// Handle individual mode (each image = separate post)
+		if individual {
+			fmt.Printf("📸 Individual mode: Posting %d images separately to %s\n", len(req.MediaURLs), page.PageName)
+			for imgIdx, item := range publishReq.Media {
+				singleReq := publishReq
+				singleReq.Media = []facebook.MediaItem{item}
+				publishReqs = append(publishReqs, singleReq)
+				owners = append(owners, requestOwner{index: i, imageIdx: imgIdx})
 			}
-		}(i, pageID, mediaData)
+			continue
+		}
+		
+		// Album mode (default): Post all images in one post
+		publishReqs = append(publishReqs, publishReq)
+		owners = append(owners, requestOwner{index: i})
 	}
 	
-	// Collect results
-	publishResults := make([]publishResult, len(req.PageIDs))
-	for i := 0; i < len(req.PageIDs); i++ {
-		result := <-resultChan
-		publishResults[result.index] = result
+	fmt.Printf("⚡ Publishing %d posts to %d pages in batches...\n", len(publishReqs), len(fbPageIDs))
+	batchResults := h.fbClient.PublishBatch(ctx, publishReqs)
+	
+	// Xử lý usage Graph API Facebook báo về cho từng page như scheduler:
+	// lưu snapshot, tạm dừng page / nick khi sắp bị throttle
+	for i, token := range pageTokens {
+		if token != "" {
+			scheduler.ApplyUsage(h.store, h.fbClient, publishResults[i].pageID, publishResults[i].pageName, pageAccounts[i], token)
+		}
 	}
 	
+	// Gộp kết quả theo page
+	fbPostIDs := make([][]string, len(fbPageIDs))
+	for j, batchResult := range batchResults {
+		owner := owners[j]
+		pr := &publishResults[owner.index]
+		if pr.err != nil {
+			continue
+		}
+		if batchResult.Err != nil {
+			// Individual mode: ảnh khác vẫn có thể đã lên, ghi lỗi riêng cho ảnh này
+			if individual {
+				pr.failed = append(pr.failed, failedImage{imageIdx: owner.imageIdx, err: batchResult.Err})
+				continue
+			}
+			pr.err = batchResult.Err
+			continue
+		}
+		fbPostIDs[owner.index] = append(fbPostIDs[owner.index], batchResult.Result.PostID)
+		pr.published = append(pr.published, batchResult.Result)
+	}
+	// Không ảnh nào lên thì page coi như lỗi
+	for i := range publishResults {
+		pr := &publishResults[i]
+		if pr.err == nil && len(pr.published) == 0 && len(pr.failed) > 0 {
+			first := pr.failed[0]
+			pr.err = fmt.Errorf("failed to post image %d: %w", first.imageIdx+1, first.err)
+			if len(pr.failed) > 1 {
+				pr.err = fmt.Errorf("failed to post %d images, image %d: %w", len(pr.failed), first.imageIdx+1, first.err)
+			}
+		}
+	}
+	
+	// Đọc lại từng bài (permalink, created_time) và comment đầu tiên (individual mode: comment lên bài đầu tiên),
+	// giới hạn số request song song
+	var commentWg sync.WaitGroup
+	commentSem := make(chan struct{}, firstCommentConcurrency)
+	for i := range publishResults {
+		pr := &publishResults[i]
+		if individual {
+			pr.fbPostID = fmt.Sprintf("%d posts: %v", len(fbPostIDs[i]), fbPostIDs[i])
+		} else if len(fbPostIDs[i]) > 0 {
+			pr.fbPostID = fbPostIDs[i][0]
+		}
+		if pr.err != nil || len(fbPostIDs[i]) == 0 {
+			continue
+		}
+		
+		commentWg.Add(1)
+		go func(pr *publishResult, fbPostID, token string) {
+			defer commentWg.Done()
+			commentSem <- struct{}{}
+			defer func() { <-commentSem }()
+			pr.details = make([]db.PostLog, len(pr.published))
+			for j, published := range pr.published {
+				scheduler.RecordPublishedPost(ctx, h.fbClient, &pr.details[j], published, token, post.MediaType)
+			}
+			pr.commentID, pr.commentErr = h.postFirstComment(ctx, post, fbPostID, token)
+		}(pr, fbPostIDs[i][0], pageTokens[i])
+	}
+	commentWg.Wait()
+	
 	// Process results and create logs
 	results := make([]map[string]interface{}, 0)
 	hasError := false
 	
-	for _, result := range publishResults {
-		logEntry := &db.PostLog{
-			PostID: post.ID,
-			PageID: result.pageID,
-		}
-		
+	for i, result := range publishResults {
 		if result.err != nil {
+			logEntry := &db.PostLog{PostID: post.ID, PageID: result.pageID}
 			fmt.Printf("❌ Failed to post to page %s: %v\n", result.pageName, result.err)
 			logEntry.Status = "failed"
 			logEntry.ErrorMessage = result.err.Error()
@@ -317,11 +543,21 @@ func (h *Handler) PublishPost(w http.ResponseWriter, r *http.Request) {
 			hasError = true
 		} else {
 			fmt.Printf("✅ Successfully posted to page %s: %s\n", result.pageName, result.fbPostID)
-			logEntry.Status = "success"
-			logEntry.FacebookPostID = result.fbPostID
-			h.store.CreatePostLog(logEntry)
+			status := "success"
+			if result.commentErr != nil {
+				fmt.Printf("⚠️ First comment failed on page %s: %v\n", result.pageName, result.commentErr)
+				status = "partial"
+				h.store.NotifyFirstCommentFailed(result.pageID, result.pageName, result.commentErr.Error())
+			}
+			// Log bài đầu tiên chỉ partial khi comment lỗi; ảnh lỗi đã có log failed riêng
+			logStatus := status
+			if len(result.failed) > 0 {
+				fmt.Printf("⚠️ %d/%d images failed on page %s\n", len(result.failed), len(result.failed)+len(result.published), result.pageName)
+				status = "partial"
+				hasError = true
+			}
 			
-			// Tạo scheduled_post với status completed để hiển thị trong lịch đăng
+			// Tạo scheduled_post với status completed để hiển thị trong lịch đăng (kèm link bài qua post_logs)
 			account, _ := h.store.GetPrimaryAccountForPage(result.pageID)
 			scheduledPost := &db.ScheduledPost{
 				PostID:        post.ID,
@@ -333,29 +569,388 @@ func (h *Handler) PublishPost(w http.ResponseWriter, r *http.Request) {
 			if account != nil {
 				scheduledPost.AccountID = &account.ID
 			}
-			h.store.CreateScheduledPost(scheduledPost)
+			scheduledPostID := ""
+			if err := h.store.CreateScheduledPost(scheduledPost); err == nil {
+				scheduledPostID = scheduledPost.ID
+			}
 			
-			results = append(results, map[string]interface{}{
+			// Mỗi bài đã đăng 1 log (individual mode: mỗi ảnh 1 bài) kèm permalink, ID ảnh/video, response gốc.
+			// Comment đầu tiên nằm trên bài đầu tiên.
+			permalinks := make([]string, 0, len(result.details))
+			for j := range result.details {
+				logEntry := &result.details[j]
+				logEntry.PostID = post.ID
+				logEntry.PageID = result.pageID
+				logEntry.ScheduledPostID = scheduledPostID
+				logEntry.Status = "success"
+				logEntry.FacebookPostID = result.published[j].PostID
+				if j == 0 {
+					logEntry.Status = logStatus
+					logEntry.CommentID = result.commentID
+					if result.commentErr != nil {
+						logEntry.ErrorMessage = "first comment failed: " + result.commentErr.Error()
+					}
+				}
+				h.store.CreatePostLog(logEntry)
+				if logEntry.PermalinkURL != "" {
+					permalinks = append(permalinks, logEntry.PermalinkURL)
+				}
+			}
+			
+			// Ảnh lỗi ghi log failed riêng, không làm mất log của các bài đã lên
+			failedImages := make([]map[string]interface{}, 0, len(result.failed))
+			for _, failed := range result.failed {
+				errMsg := fmt.Sprintf("failed to post image %d: %v", failed.imageIdx+1, failed.err)
+				h.store.CreatePostLog(&db.PostLog{
+					PostID:          post.ID,
+					PageID:          result.pageID,
+					ScheduledPostID: scheduledPostID,
+					Status:          "failed",
+					ErrorMessage:    errMsg,
+				})
+				failedImages = append(failedImages, map[string]interface{}{
+					"image": failed.imageIdx + 1,
+					"error": failed.err.Error(),
+				})
+			}
+			
+			pageResult := map[string]interface{}{
 				"page_id":         result.pageID,
 				"page_name":       result.pageName,
-				"status":          "success",
+				"status":          status,
 				"facebook_post_id": result.fbPostID,
-			})
+			}
+			if individual {
+				pageResult["facebook_post_ids"] = fbPostIDs[i]
+				if len(permalinks) > 0 {
+					pageResult["permalink_urls"] = permalinks
+				}
+			}
+			if len(permalinks) > 0 {
+				pageResult["permalink_url"] = permalinks[0]
+			}
+			if result.commentID != "" {
+				pageResult["comment_id"] = result.commentID
+			}
+			if result.commentErr != nil {
+				pageResult["comment_error"] = result.commentErr.Error()
+			}
+			if len(failedImages) > 0 {
+				pageResult["failed_images"] = failedImages
+			}
+			results = append(results, pageResult)
 		}
 	}
 	
+	// Đăng lên Instagram liên kết với từng page
+	if post.HasPlatform(db.PlatformInstagram) {
+		igResults, igFailed := h.publishInstagram(ctx, post, req.PageIDs)
+		results = append(results, igResults...)
+		hasError = hasError || igFailed
+	}
+	
 	response := map[string]interface{}{
 		"post_id": post.ID,
 		"results": results,
 	}
 	
 	if hasError {
-		fmt.Printf("⚠️ Post published with some errors\n")
-		response["message"] = "Post published with some errors"
-		respondJSON(w, http.StatusPartialContent, response)
+		succeeded := 0
+		for _, result := range results {
+			if result["status"] != "failed" {
+				succeeded++
+			}
+		}
+		if succeeded == 0 {
+			fmt.Printf("❌ Post failed on every page\n")
+			response["message"] = "Post failed on every page"
+		} else {
+			fmt.Printf("⚠️ Post published with some errors\n")
+			response["message"] = "Post published with some errors"
+		}
+		// Ảnh lỗi trên page đã đăng được (status partial) vẫn tính là 1 phần thành công → 207
+		respondJSON(w, multiStatus(succeeded, max(len(results)-succeeded, 1)), response)
 	} else {
 		fmt.Printf("✅ Post published successfully to all pages\n")
 		response["message"] = "Post published successfully to all pages"
 		respondJSON(w, http.StatusOK, response)
 	}
 }
+
+// buildMediaItems tạo danh sách media cho facebook.PublishRequest.
+// File đã tải sẵn (video) được ưu tiên hơn URL.
+func buildMediaItems(mediaURLs []string, preloadedPaths []string) []facebook.MediaItem {
+	items := facebook.MediaFromURLs(mediaURLs)
+	for i := range items {
+		if i < len(preloadedPaths) {
+			items[i].Path = preloadedPaths[i]
+		}
+	}
+	return items
+}
+
+// downloadToTempFile tải media về file tạm (stream, không đọc hết vào RAM)
+func downloadToTempFile(mediaURL string) (string, int64, error) {
+	resp, err := http.Get(mediaURL)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	tmp, err := os.CreateTemp("", "fbmedia-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, resp.Body)
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", 0, err
+	}
+	return tmp.Name(), size, nil
+}
+
+// validatePost kiểm tra bài nháp trước khi lưu (CreatePost, UpdatePost) và chuẩn hóa audience
+func validatePost(post *db.Post) error {
+	if msg := validateLinkPost(post.LinkURL, post.LinkName, post.LinkDescription, post.LinkPicture, len(post.MediaURLs)); msg != "" {
+		return errors.New(msg)
+	}
+	if msg := validateMediaType(post.MediaType, len(post.MediaURLs)); msg != "" {
+		return errors.New(msg)
+	}
+	if post.FirstCommentImage != "" && !isHTTPURL(post.FirstCommentImage) {
+		return errors.New("first_comment_image must be a valid http(s) URL")
+	}
+	if msg := validatePlace(post.PlaceID, post.Tags, post.MediaType); msg != "" {
+		return errors.New(msg)
+	}
+	if msg := validatePlatforms(post.Platforms, post.MediaType, post.MediaURLs, post.LinkURL); msg != "" {
+		return errors.New(msg)
+	}
+	if msg := validateCaptions(post.MediaCaptions, post.BurnCaptions, post.MediaType, len(post.MediaURLs), post.PlaceID); msg != "" {
+		return errors.New(msg)
+	}
+	post.Targeting, post.FeedTargeting = normalizeAudience(post.Targeting), normalizeAudience(post.FeedTargeting)
+	if msg := validateAudience(post); msg != "" {
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// validateLinkPost kiểm tra bài đăng dạng link, trả về thông báo lỗi (rỗng nếu hợp lệ).
+// Facebook không cho gắn link preview cùng ảnh/video upload.
+func validateLinkPost(linkURL, linkName, linkDescription, linkPicture string, mediaCount int) string {
+	if linkURL == "" {
+		if linkName != "" || linkDescription != "" || linkPicture != "" {
+			return "link_name, link_description and link_picture require link_url"
+		}
+		return ""
+	}
+	
+	if !isHTTPURL(linkURL) {
+		return "link_url must be a valid http(s) URL"
+	}
+	if linkPicture != "" && !isHTTPURL(linkPicture) {
+		return "link_picture must be a valid http(s) URL"
+	}
+	if mediaCount > 0 {
+		return "A link post cannot include uploaded media; use link_picture for a custom preview image"
+	}
+	return ""
+}
+
+// validatePlace kiểm tra check-in / tag người, trả về thông báo lỗi nếu không hợp lệ
+func validatePlace(placeID string, tags []string, mediaType string) string {
+	if len(tags) > 0 && placeID == "" {
+		return "tags require place_id"
+	}
+	if placeID != "" && (mediaType == facebook.MediaTypeReel || mediaType == facebook.MediaTypeStory) {
+		return "Reels and stories cannot be checked in to a place"
+	}
+	return ""
+}
+
+// validatePlatforms kiểm tra nền tảng đăng ("facebook" | "instagram"), trả về thông báo lỗi nếu không hợp lệ.
+// Instagram cần 1-10 ảnh/video có URL public, không đăng được link và story.
+func validatePlatforms(platforms []string, mediaType string, mediaURLs []string, linkURL string) string {
+	instagram := false
+	for _, platform := range platforms {
+		switch platform {
+		case db.PlatformFacebook:
+		case db.PlatformInstagram:
+			instagram = true
+		default:
+			return "Invalid platform: " + platform
+		}
+	}
+	if !instagram {
+		return ""
+	}
+	
+	if len(mediaURLs) == 0 {
+		return "Instagram posts need at least 1 photo or video"
+	}
+	if len(mediaURLs) > facebook.MaxCarouselItems {
+		return fmt.Sprintf("An Instagram carousel holds at most %d photos or videos", facebook.MaxCarouselItems)
+	}
+	if mediaType == facebook.MediaTypeStory {
+		return "Stories cannot be published to Instagram"
+	}
+	if linkURL != "" {
+		return "Link posts cannot be published to Instagram"
+	}
+	for _, mediaURL := range mediaURLs {
+		if !isHTTPURL(mediaURL) {
+			return "Instagram media must be public http(s) URLs"
+		}
+	}
+	return ""
+}
+
+// validateCaptions kiểm tra caption từng ảnh / in caption lên ảnh, trả về thông báo lỗi nếu không hợp lệ
+func validateCaptions(captions []string, burn bool, mediaType string, mediaCount int, placeID string) string {
+	hasCaptions := false
+	for _, caption := range captions {
+		if caption != "" {
+			hasCaptions = true
+		}
+	}
+	if !hasCaptions && !burn {
+		return ""
+	}
+	
+	if len(captions) > mediaCount {
+		return "media_captions cannot have more entries than media_urls"
+	}
+	switch mediaType {
+	case "", "text", facebook.MediaTypePhoto:
+	default:
+		return "Per-image captions are only supported on photo posts"
+	}
+	if burn && !hasCaptions {
+		return "burn_captions requires media_captions"
+	}
+	// Nhiều ảnh có caption được đăng dạng album, album không check-in được
+	if hasCaptions && mediaCount > 1 && placeID != "" {
+		return "Photo albums with captions cannot be checked in to a place"
+	}
+	return ""
+}
+
+// validateAudience kiểm tra targeting / feed_targeting và bài ẩn, trả về thông báo lỗi nếu không hợp lệ.
+// Chỉ áp dụng cho bài Facebook dạng text, link, ảnh, video (không cho reel, story, album).
+func validateAudience(post *db.Post) string {
+	if post.Targeting == nil && post.FeedTargeting == nil && !post.Unpublished {
+		return ""
+	}
+	
+	switch post.MediaType {
+	case facebook.MediaTypeReel, facebook.MediaTypeStory:
+		return "Reels and stories cannot be targeted or unpublished"
+	}
+	if scheduler.PostAlbumName(post) != "" {
+		return "Photo albums with captions cannot be targeted or unpublished"
+	}
+	if !post.TargetsFacebook() {
+		return "Targeting and unpublished posts only apply to Facebook"
+	}
+	if post.Unpublished && post.HasPlatform(db.PlatformInstagram) {
+		return "Instagram posts cannot be unpublished"
+	}
+	for field, audience := range map[string]*db.Audience{"targeting": post.Targeting, "feed_targeting": post.FeedTargeting} {
+		if audience == nil {
+			continue
+		}
+		if err := facebook.Audience(*audience).Validate(); err != nil {
+			return field + ": " + strings.TrimPrefix(err.Error(), facebook.ErrInvalidRequest.Error()+": ")
+		}
+	}
+	return ""
+}
+
+// normalizeAudience bỏ nhóm người xem rỗng (không chọn tiêu chí nào = mọi người)
+func normalizeAudience(a *db.Audience) *db.Audience {
+	if a == nil || facebook.Audience(*a).IsEmpty() {
+		return nil
+	}
+	return a
+}
+
+// privacyUnpublished đổi privacy của bài sang bài ẩn: "public" (mặc định) hoặc "private" (dark post).
+// Bài của page không giới hạn theo bạn bè được, dùng targeting thay thế.
+func privacyUnpublished(privacy string) (bool, string) {
+	switch privacy {
+	case "", "public":
+		return false, ""
+	case "private":
+		return true, ""
+	default:
+		return false, "Page posts cannot use privacy '" + privacy + "'; use 'public', 'private' (unpublished) or targeting"
+	}
+}
+
+// isHTTPURL kiểm tra URL tuyệt đối http/https
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// validateMediaType kiểm tra media_type ("text" | "photo" | "video" | "reel" | "story"), trả về thông báo lỗi nếu không hợp lệ
+func validateMediaType(mediaType string, mediaCount int) string {
+	switch mediaType {
+	case "", "text", facebook.MediaTypePhoto:
+		return ""
+	case facebook.MediaTypeVideo:
+		if mediaCount > 1 {
+			return "Only 1 video per post is supported"
+		}
+		return ""
+	case facebook.MediaTypeReel:
+		if mediaCount != 1 {
+			return "A reel needs exactly 1 video"
+		}
+		return ""
+	case facebook.MediaTypeStory:
+		if mediaCount != 1 {
+			return "A story needs exactly 1 photo or video"
+		}
+		return ""
+	}
+	return "Invalid media_type: " + mediaType
+}
+
+// validateVideoFile kiểm tra thời lượng / độ phân giải của video reel hoặc story trước khi upload
+func validateVideoFile(path, mediaType string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	
+	info, err := facebook.ProbeVideo(f, stat.Size())
+	if err != nil {
+		return fmt.Errorf("cannot read %s video: %w", mediaType, err)
+	}
+	if mediaType == facebook.MediaTypeStory {
+		return facebook.ValidateStoryVideo(info)
+	}
+	return facebook.ValidateReel(info)
+}
+
+// postFirstComment đăng comment đầu tiên nếu bài có cấu hình (story không hỗ trợ comment)
+func (h *Handler) postFirstComment(ctx context.Context, post *db.Post, fbPostID, accessToken string) (string, error) {
+	if (post.FirstComment == "" && post.FirstCommentImage == "") || post.MediaType == facebook.MediaTypeStory {
+		return "", nil
+	}
+	return scheduler.PostFirstComment(ctx, h.fbClient, fbPostID, accessToken, post)
+}
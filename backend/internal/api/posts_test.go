@@ -0,0 +1,80 @@
+package api
+
+import (
+	"testing"
+
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+)
+
+func TestValidatePost(t *testing.T) {
+	photos := []string{"https://example.com/a.jpg", "https://example.com/b.jpg"}
+
+	tests := []struct {
+		name    string
+		post    db.Post
+		wantErr bool
+	}{
+		{"text", db.Post{Content: "hi"}, false},
+		{"link post", db.Post{LinkURL: "https://example.com", LinkName: "Name"}, false},
+		{"link preview without link", db.Post{Content: "hi", LinkName: "Name"}, true},
+		{"relative link", db.Post{LinkURL: "/article"}, true},
+		{"link with media", db.Post{LinkURL: "https://example.com", MediaURLs: photos[:1]}, true},
+
+		{"photos", db.Post{MediaType: facebook.MediaTypePhoto, MediaURLs: photos}, false},
+		{"two videos", db.Post{MediaType: facebook.MediaTypeVideo, MediaURLs: photos}, true},
+		{"reel without video", db.Post{MediaType: facebook.MediaTypeReel, Content: "hi"}, true},
+		{"unknown media type", db.Post{MediaType: "gif", MediaURLs: photos[:1]}, true},
+
+		{"first comment image", db.Post{Content: "hi", FirstCommentImage: "https://example.com/c.jpg"}, false},
+		{"relative first comment image", db.Post{Content: "hi", FirstCommentImage: "c.jpg"}, true},
+
+		{"check-in with tags", db.Post{MediaURLs: photos[:1], PlaceID: "42", Tags: []string{"1"}}, false},
+		{"tags without place", db.Post{Content: "hi", Tags: []string{"1"}}, true},
+		{"story check-in", db.Post{MediaType: facebook.MediaTypeStory, MediaURLs: photos[:1], PlaceID: "42"}, true},
+
+		{"instagram photos", db.Post{MediaType: facebook.MediaTypePhoto, MediaURLs: photos,
+			Platforms: []string{db.PlatformInstagram}}, false},
+		{"instagram text", db.Post{Content: "hi", Platforms: []string{db.PlatformInstagram}}, true},
+		{"instagram story", db.Post{MediaType: facebook.MediaTypeStory, MediaURLs: photos[:1],
+			Platforms: []string{db.PlatformInstagram}}, true},
+		{"unknown platform", db.Post{Content: "hi", Platforms: []string{"tiktok"}}, true},
+
+		{"photo captions", db.Post{MediaURLs: photos, MediaCaptions: []string{"a", "b"}}, false},
+		{"more captions than photos", db.Post{MediaURLs: photos[:1], MediaCaptions: []string{"a", "b"}}, true},
+		{"burn without captions", db.Post{MediaURLs: photos[:1], BurnCaptions: true}, true},
+		{"captioned album check-in", db.Post{MediaURLs: photos, MediaCaptions: []string{"a", "b"}, PlaceID: "42"}, true},
+
+		{"unpublished text", db.Post{Content: "hi", Unpublished: true}, false},
+		{"unpublished reel", db.Post{MediaType: facebook.MediaTypeReel, MediaURLs: photos[:1], Unpublished: true}, true},
+		{"unpublished on instagram", db.Post{MediaURLs: photos, Unpublished: true,
+			Platforms: []string{db.PlatformFacebook, db.PlatformInstagram}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePost(&tt.post)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validatePost() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrivacyUnpublished(t *testing.T) {
+	tests := []struct {
+		privacy         string
+		wantUnpublished bool
+		wantErr         bool
+	}{
+		{"", false, false},
+		{"public", false, false},
+		{"private", true, false},
+		{"friends", false, true},
+	}
+	for _, tt := range tests {
+		unpublished, msg := privacyUnpublished(tt.privacy)
+		if unpublished != tt.wantUnpublished || (msg != "") != tt.wantErr {
+			t.Errorf("privacyUnpublished(%q) = %v, %q", tt.privacy, unpublished, msg)
+		}
+	}
+}
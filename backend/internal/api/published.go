@@ -0,0 +1,262 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// publishedActionResult kết quả sửa / xóa bài đã đăng trên 1 page
+type publishedActionResult struct {
+	PostLogID      string `json:"post_log_id"`
+	PageID         string `json:"page_id"`
+	PageName       string `json:"page_name"`
+	FacebookPostID string `json:"facebook_post_id"`
+	Status         string `json:"status"` // success, failed
+	Error          string `json:"error,omitempty"`
+}
+
+// facebookPostIDs tách ID bài của 1 dòng log; individual mode lưu dạng "N posts: [id1 id2]"
+func facebookPostIDs(fbPostID string) []string {
+	if i := strings.Index(fbPostID, "["); i >= 0 {
+		return strings.Fields(strings.Trim(fbPostID[i:], "[]"))
+	}
+	return []string{fbPostID}
+}
+
+// isLivePublishLog kiểm tra dòng log là bài Facebook đã đăng thành công và chưa bị xóa
+func isLivePublishLog(pl *db.PostLog) bool {
+	return pl.Action == "publish" && pl.Platform == db.PlatformFacebook && (pl.Status == "success" || pl.Status == "partial") &&
+		pl.FacebookPostID != "" && pl.DeletedAt == nil
+}
+
+// publishedMediaType loại media của bài đã đăng (video / reel sửa mô tả thay cho nội dung)
+func publishedMediaType(pl db.PostLog) string {
+	if pl.Post == nil {
+		return ""
+	}
+	return pl.Post.MediaType
+}
+
+// editPublishedLog sửa nội dung bài của 1 dòng publish trên Facebook và ghi dòng log "edit"
+func (h *Handler) editPublishedLog(ctx context.Context, pl db.PostLog, message string) publishedActionResult {
+	var err error
+	for _, fbPostID := range facebookPostIDs(pl.FacebookPostID) {
+		if err = h.fbClient.UpdatePost(ctx, fbPostID, pl.Page.AccessToken, publishedMediaType(pl), message); err != nil {
+			err = fmt.Errorf("failed to edit %s: %w", fbPostID, err)
+			break
+		}
+	}
+	if err == nil {
+		if markErr := h.store.MarkPostLogEdited(pl.ID); markErr != nil {
+			log.Printf("⚠️ Failed to mark post log %s edited: %v", pl.ID, markErr)
+		}
+	}
+	return h.recordPublishedAction(pl, "edit", err)
+}
+
+// deletePublishedLog xóa bài của 1 dòng publish trên Facebook và ghi dòng log "delete".
+// Bài đã bị xóa sẵn trên Facebook coi như thành công.
+func (h *Handler) deletePublishedLog(ctx context.Context, pl db.PostLog) publishedActionResult {
+	var err error
+	for _, fbPostID := range facebookPostIDs(pl.FacebookPostID) {
+		if err = h.fbClient.DeletePost(ctx, fbPostID, pl.Page.AccessToken); err != nil && !facebook.IsNotFound(err) {
+			err = fmt.Errorf("failed to delete %s: %w", fbPostID, err)
+			break
+		}
+		err = nil
+	}
+	if err == nil {
+		if markErr := h.store.MarkPostLogDeleted(pl.ID); markErr != nil {
+			log.Printf("⚠️ Failed to mark post log %s deleted: %v", pl.ID, markErr)
+		}
+	}
+	return h.recordPublishedAction(pl, "delete", err)
+}
+
+// recordPublishedAction ghi thao tác sửa / xóa vào post_logs
+func (h *Handler) recordPublishedAction(pl db.PostLog, action string, err error) publishedActionResult {
+	entry := &db.PostLog{
+		ScheduledPostID: pl.ScheduledPostID,
+		PostID:          pl.PostID,
+		PageID:          pl.PageID,
+		FacebookPostID:  pl.FacebookPostID,
+		Action:          action,
+		Status:          "success",
+	}
+	result := publishedActionResult{
+		PostLogID:      pl.ID,
+		PageID:         pl.PageID,
+		PageName:       pl.Page.PageName,
+		FacebookPostID: pl.FacebookPostID,
+		Status:         "success",
+	}
+	if err != nil {
+		log.Printf("❌ Failed to %s post on page %s: %v", action, pl.Page.PageName, err)
+		entry.Status = "failed"
+		entry.ErrorMessage = err.Error()
+		result.Status = "failed"
+		result.Error = err.Error()
+	} else {
+		log.Printf("✅ Post %s on page %s: %s done", pl.FacebookPostID, pl.Page.PageName, action)
+	}
+	if logErr := h.store.CreatePostLog(entry); logErr != nil {
+		log.Printf("⚠️ Failed to record %s log: %v", action, logErr)
+	}
+	return result
+}
+
+// getLivePublishLog lấy dòng publish còn trên Facebook theo {id}, trả lỗi HTTP nếu không hợp lệ
+func (h *Handler) getLivePublishLog(w http.ResponseWriter, r *http.Request) *db.PostLog {
+	pl, err := h.store.GetPostLogByID(mux.Vars(r)["id"])
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch log")
+		return nil
+	}
+	if pl == nil {
+		respondError(w, http.StatusNotFound, "Log not found")
+		return nil
+	}
+	if !isLivePublishLog(pl) {
+		respondError(w, http.StatusBadRequest, "Log is not a published post that is still on Facebook")
+		return nil
+	}
+	return pl
+}
+
+// respondPublishedActions trả kết quả theo từng page: 200 nếu tất cả thành công, 207 nếu có lỗi, 502 nếu tất cả lỗi
+func respondPublishedActions(w http.ResponseWriter, message string, results []publishedActionResult) {
+	succeeded := 0
+	for _, result := range results {
+		if result.Status == "success" {
+			succeeded++
+		}
+	}
+	status := multiStatus(succeeded, len(results)-succeeded)
+	switch status {
+	case http.StatusMultiStatus:
+		message += " with some errors"
+	case http.StatusBadGateway:
+		message += " failed on every page"
+	}
+	respondJSON(w, status, map[string]interface{}{
+		"message": message,
+		"results": results,
+	})
+}
+
+// EditPostLog PUT /api/logs/{id} - Sửa nội dung bài đã đăng của 1 dòng log trên Facebook
+func (h *Handler) EditPostLog(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Content == "" {
+		respondError(w, http.StatusBadRequest, "content is required")
+		return
+	}
+
+	pl := h.getLivePublishLog(w, r)
+	if pl == nil {
+		return
+	}
+	if publishedMediaType(*pl) == facebook.MediaTypeStory {
+		respondError(w, http.StatusBadRequest, "Stories cannot be edited")
+		return
+	}
+
+	result := h.editPublishedLog(r.Context(), *pl, req.Content)
+	respondPublishedActions(w, "Post edited", []publishedActionResult{result})
+}
+
+// DeletePostLog DELETE /api/logs/{id} - Xóa bài đã đăng của 1 dòng log trên Facebook
+func (h *Handler) DeletePostLog(w http.ResponseWriter, r *http.Request) {
+	pl := h.getLivePublishLog(w, r)
+	if pl == nil {
+		return
+	}
+
+	result := h.deletePublishedLog(r.Context(), *pl)
+	respondPublishedActions(w, "Post deleted from Facebook", []publishedActionResult{result})
+}
+
+// EditPublishedPost PUT /api/posts/{id}/published - Sửa nội dung bài trên mọi page đã đăng
+func (h *Handler) EditPublishedPost(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Content == "" {
+		respondError(w, http.StatusBadRequest, "content is required")
+		return
+	}
+
+	logs, err := h.store.GetLivePostLogs(id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch published pages")
+		return
+	}
+	if len(logs) == 0 {
+		respondError(w, http.StatusNotFound, "Post is not published on any page")
+		return
+	}
+	if publishedMediaType(logs[0]) == facebook.MediaTypeStory {
+		respondError(w, http.StatusBadRequest, "Stories cannot be edited")
+		return
+	}
+
+	results := make([]publishedActionResult, 0, len(logs))
+	edited := 0
+	for _, pl := range logs {
+		result := h.editPublishedLog(r.Context(), pl, req.Content)
+		if result.Status == "success" {
+			edited++
+		}
+		results = append(results, result)
+	}
+
+	// Cập nhật nội dung trong app khi đã sửa được ít nhất 1 page
+	if edited > 0 {
+		if err := h.store.UpdatePostContent(id, req.Content); err != nil {
+			log.Printf("⚠️ Failed to update post content: %v", err)
+		}
+	}
+
+	respondPublishedActions(w, "Post edited", results)
+}
+
+// deleteFromFacebook xóa bài trên mọi page đã đăng, trả về kết quả từng page
+func (h *Handler) deleteFromFacebook(ctx context.Context, postID string) ([]publishedActionResult, error) {
+	logs, err := h.store.GetLivePostLogs(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]publishedActionResult, 0, len(logs))
+	for _, pl := range logs {
+		results = append(results, h.deletePublishedLog(ctx, pl))
+	}
+	return results, nil
+}
+
+// DeletePublishedPost DELETE /api/posts/{id}/published - Xóa bài trên mọi page đã đăng (giữ bài trong app)
+func (h *Handler) DeletePublishedPost(w http.ResponseWriter, r *http.Request) {
+	results, err := h.deleteFromFacebook(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch published pages")
+		return
+	}
+	if len(results) == 0 {
+		respondError(w, http.StatusNotFound, "Post is not published on any page")
+		return
+	}
+
+	respondPublishedActions(w, "Post deleted from Facebook", results)
+}
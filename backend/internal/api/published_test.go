@@ -0,0 +1,22 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestMultiStatus(t *testing.T) {
+	tests := []struct {
+		succeeded, failed int
+		want              int
+	}{
+		{3, 0, http.StatusOK},
+		{2, 1, http.StatusMultiStatus},
+		{0, 3, http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		if got := multiStatus(tt.succeeded, tt.failed); got != tt.want {
+			t.Errorf("multiStatus(%d, %d) = %d, want %d", tt.succeeded, tt.failed, got, tt.want)
+		}
+	}
+}
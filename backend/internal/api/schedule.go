@@ -2,7 +2,11 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+	"fbscheduler/internal/scheduler"
 	"log"
 	"net/http"
 	"sort"
@@ -15,6 +19,9 @@ type ScheduleRequest struct {
 	PostID        string    `json:"post_id"`
 	PageIDs       []string  `json:"page_ids"`
 	ScheduledTime time.Time `json:"scheduled_time"`
+	
+	// "local" | "facebook" | rỗng = theo mặc định của từng page
+	SchedulingMode string `json:"scheduling_mode"`
 }
 
 func (h *Handler) SchedulePost(w http.ResponseWriter, r *http.Request) {
@@ -30,6 +37,42 @@ func (h *Handler) SchedulePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	switch req.SchedulingMode {
+	case "", "local", "facebook":
+	default:
+		respondError(w, http.StatusBadRequest, "scheduling_mode must be 'local' or 'facebook'")
+		return
+	}
+	
+	post, ok := h.loadSchedulablePost(w, req.PostID)
+	if !ok {
+		return
+	}
+	if req.SchedulingMode == "facebook" && post.MediaType == facebook.MediaTypeStory {
+		respondError(w, http.StatusBadRequest, "Stories cannot be scheduled on Facebook")
+		return
+	}
+	instagram := post.HasPlatform(db.PlatformInstagram)
+	if req.SchedulingMode == "facebook" && instagram {
+		respondError(w, http.StatusBadRequest, "Instagram posts cannot be scheduled on Facebook")
+		return
+	}
+	captions := scheduler.NeedsCaptionUpload(post)
+	if req.SchedulingMode == "facebook" && captions {
+		respondError(w, http.StatusBadRequest, "Posts with per-image captions cannot be scheduled on Facebook")
+		return
+	}
+	if req.SchedulingMode == "facebook" && post.Unpublished {
+		respondError(w, http.StatusBadRequest, "Unpublished posts cannot be scheduled on Facebook")
+		return
+	}
+	if instagram {
+		if msg := h.checkInstagramPages(req.PageIDs); msg != "" {
+			respondError(w, http.StatusBadRequest, msg)
+			return
+		}
+	}
+
 	// Chuẩn hóa về UTC để so sánh chính xác
 	scheduledUTC := req.ScheduledTime.UTC()
 	nowUTC := time.Now().UTC()
@@ -47,6 +90,7 @@ func (h *Handler) SchedulePost(w http.ResponseWriter, r *http.Request) {
 	// Create scheduled posts for each page
 	// Lưu thời gian ở UTC
 	var scheduled []db.ScheduledPost
+	handOffErrors := make(map[string]string)
 	for _, pageID := range req.PageIDs {
 		sp := &db.ScheduledPost{
 			PostID:        req.PostID,
@@ -54,6 +98,7 @@ func (h *Handler) SchedulePost(w http.ResponseWriter, r *http.Request) {
 			ScheduledTime: scheduledUTC, // Luôn lưu UTC
 			Status:        "pending",
 			MaxRetries:    3,
+			SchedulingMode: req.SchedulingMode,
 		}
 		
 		// Tìm time_slot_id phù hợp với thời gian đã chọn
@@ -62,8 +107,13 @@ func (h *Handler) SchedulePost(w http.ResponseWriter, r *http.Request) {
 			sp.TimeSlotID = &timeSlotID
 		}
 		
-		// Tự động assign account cho page (lấy primary account)
+		// Tự động assign account cho page (lấy primary account; story ưu tiên nick còn hạn mức story)
 		account, err := h.store.GetPrimaryAccountForPage(pageID)
+		if post.MediaType == facebook.MediaTypeStory {
+			if best, bestErr := h.store.GetBestAccountForStory(pageID); bestErr == nil && best != nil {
+				account, err = best, nil
+			}
+		}
 		if err == nil && account != nil {
 			sp.AccountID = &account.ID
 		}
@@ -73,13 +123,75 @@ func (h *Handler) SchedulePost(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		
+		// Mode "facebook": tạo bài hẹn giờ trên Facebook ngay.
+		// Lỗi thì bài vẫn pending, scheduler sẽ thử lại hoặc tự đăng khi tới giờ.
+		if sp.SchedulingMode == "facebook" && post.MediaType != facebook.MediaTypeStory && !instagram && !captions && !post.Unpublished &&
+			time.Until(scheduledUTC) > facebook.MinScheduleLead {
+			if err := h.handOffScheduledPost(sp); err != nil {
+				handOffErrors[pageID] = err.Error()
+			}
+		}
+		
 		scheduled = append(scheduled, *sp)
 	}
 	
-	respondJSON(w, http.StatusCreated, map[string]interface{}{
+	response := map[string]interface{}{
 		"message":   "Post scheduled successfully",
 		"scheduled": scheduled,
-	})
+	}
+	if len(handOffErrors) > 0 {
+		response["handoff_errors"] = handOffErrors
+	}
+	respondJSON(w, http.StatusCreated, response)
+}
+
+// handOffScheduledPost hẹn giờ 1 scheduled post trên Facebook và cập nhật sp theo kết quả
+func (h *Handler) handOffScheduledPost(sp *db.ScheduledPost) error {
+	full, err := h.store.GetScheduledPostByID(sp.ID)
+	if err != nil || full == nil {
+		return fmt.Errorf("failed to load scheduled post: %v", err)
+	}
+	
+	engine := scheduler.NewPostingEngineWithClient(h.store, h.fbClient)
+	fbObjectID, err := engine.HandOff(*full)
+	if err != nil {
+		return err
+	}
+	
+	sp.Status = "fb_scheduled"
+	sp.FbObjectID = &fbObjectID
+	return nil
+}
+
+// loadSchedulablePost lấy bài cần schedule và kiểm tra media_type (vd: story cần đúng 1 ảnh/video).
+// Trả về false nếu đã ghi response lỗi.
+func (h *Handler) loadSchedulablePost(w http.ResponseWriter, postID string) (*db.Post, bool) {
+	post, err := h.store.GetPostByID(postID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch post")
+		return nil, false
+	}
+	if post == nil {
+		respondError(w, http.StatusNotFound, "Post not found")
+		return nil, false
+	}
+	if msg := validateMediaType(post.MediaType, len(post.MediaURLs)); msg != "" {
+		respondError(w, http.StatusBadRequest, msg)
+		return nil, false
+	}
+	if msg := validatePlatforms(post.Platforms, post.MediaType, post.MediaURLs, post.LinkURL); msg != "" {
+		respondError(w, http.StatusBadRequest, msg)
+		return nil, false
+	}
+	if msg := validateCaptions(post.MediaCaptions, post.BurnCaptions, post.MediaType, len(post.MediaURLs), post.PlaceID); msg != "" {
+		respondError(w, http.StatusBadRequest, msg)
+		return nil, false
+	}
+	if msg := validateAudience(post); msg != "" {
+		respondError(w, http.StatusBadRequest, msg)
+		return nil, false
+	}
+	return post, true
 }
 
 func (h *Handler) GetScheduledPosts(w http.ResponseWriter, r *http.Request) {
@@ -100,6 +212,20 @@ func (h *Handler) DeleteScheduledPost(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 	
+	// Bài đã hẹn giờ trên Facebook → xóa bài bên Facebook trước
+	sp, err := h.store.GetScheduledPostByID(id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch scheduled post")
+		return
+	}
+	if sp != nil && sp.Status == "fb_scheduled" && sp.FbObjectID != nil {
+		err := h.fbClient.DeletePost(r.Context(), *sp.FbObjectID, sp.Page.AccessToken)
+		if err != nil && !facebook.IsNotFound(err) {
+			respondError(w, http.StatusBadGateway, "Failed to delete post on Facebook: "+err.Error())
+			return
+		}
+	}
+	
 	if err := h.store.DeleteScheduledPost(id); err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to delete scheduled post")
 		return
@@ -120,6 +246,62 @@ func (h *Handler) RetryScheduledPost(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Post queued for retry"})
 }
 
+// ReschedulePost PUT /api/schedule/:id/reschedule - Đổi giờ đăng (bài đã hẹn giờ trên Facebook thì đổi cả bên Facebook)
+func (h *Handler) ReschedulePost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	
+	var req struct {
+		ScheduledTime time.Time `json:"scheduled_time"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ScheduledTime.IsZero() {
+		respondError(w, http.StatusBadRequest, "scheduled_time is required")
+		return
+	}
+	scheduledUTC := req.ScheduledTime.UTC()
+	if scheduledUTC.Before(time.Now().UTC()) {
+		respondError(w, http.StatusBadRequest, "scheduled_time must be in the future")
+		return
+	}
+	
+	sp, err := h.store.GetScheduledPostByID(id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch scheduled post")
+		return
+	}
+	if sp == nil {
+		respondError(w, http.StatusNotFound, "Scheduled post not found")
+		return
+	}
+	if sp.Status != "pending" && sp.Status != "fb_scheduled" {
+		respondError(w, http.StatusConflict, "Only pending or Facebook-scheduled posts can be rescheduled")
+		return
+	}
+	
+	if sp.Status == "fb_scheduled" && sp.FbObjectID != nil {
+		err := h.fbClient.ReschedulePost(r.Context(), *sp.FbObjectID, sp.Page.AccessToken, scheduledUTC)
+		if errors.Is(err, facebook.ErrInvalidRequest) {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			respondError(w, http.StatusBadGateway, "Failed to reschedule post on Facebook: "+err.Error())
+			return
+		}
+	}
+	
+	if err := h.store.RescheduleScheduledPost(id, scheduledUTC); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to reschedule post")
+		return
+	}
+	
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"message":        "Post rescheduled",
+		"id":             id,
+		"scheduled_time": scheduledUTC,
+	})
+}
+
 // TestScheduleNow POST /api/schedule/:id/test - Test đăng ngay 1 scheduled post (DEV ONLY)
 // findMatchingTimeSlot tìm time_slot_id phù hợp với thời gian đã chọn
 // Nếu slot đầy, tự động tìm slot tiếp theo còn chỗ
@@ -53,7 +53,7 @@ func (h *Handler) CheckScheduleConflict(w http.ResponseWriter, r *http.Request)
 		JOIN pages pg ON pg.id = sp.page_id
 		WHERE sp.page_id = ANY($1)
 			AND DATE_TRUNC('minute', sp.scheduled_time) = $2
-			AND sp.status IN ('pending', 'processing')
+			AND sp.status IN ('pending', 'processing', 'fb_scheduled')
 	`
 
 	rows, err := h.db.Query(query, pq.Array(req.PageIDs), scheduledUTC)
@@ -71,6 +71,10 @@ func (h *Handler) ScheduleWithPreview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, ok := h.loadSchedulablePost(w, req.PostID); !ok {
+		return
+	}
+
 	// Parse date using Vietnam timezone
 	preferredDate := config.NowVN()
 	if req.PreferredDate != "" {
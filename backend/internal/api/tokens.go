@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/scheduler"
+)
+
+// extendUserToken đổi user token sang long-lived token (~60 ngày).
+// Lỗi thì giữ token cũ để không chặn việc đăng nhập.
+func (h *Handler) extendUserToken(ctx context.Context, userToken string) string {
+	longLived, err := h.fbClient.ExchangeLongLivedToken(ctx, userToken)
+	if err != nil {
+		log.Printf("⚠️ Long-lived token exchange failed, keeping current token: %v", err)
+		return userToken
+	}
+	log.Printf("🔑 Exchanged for long-lived user token (expires %s)", formatTokenExpiry(longLived.ExpiresAt))
+	return longLived.Token
+}
+
+// recordAccountToken kiểm tra user token bằng debug_token và lưu hạn, quyền, trạng thái
+func (h *Handler) recordAccountToken(ctx context.Context, account *db.FacebookAccount) {
+	if account == nil || account.ID == "" {
+		return
+	}
+
+	monitor := scheduler.NewTokenMonitor(h.store, h.fbClient)
+	if err := monitor.CheckAccount(ctx, account); err != nil {
+		log.Printf("⚠️ Could not inspect token of account %s: %v", account.FbUserName, err)
+		return
+	}
+	log.Printf("🔑 Account %s token: valid=%v, expires %s, %d scopes",
+		account.FbUserName, account.TokenIsValid, formatTokenExpiryPtr(account.TokenExpiresAt), len(account.TokenScopes))
+}
+
+// recordPageToken kiểm tra page token bằng debug_token và lưu hạn + quyền (page đã được lưu, có ID).
+// Token dùng được thì các bài bị chặn vì token hỏng (blocked_token) được mở lại.
+func (h *Handler) recordPageToken(ctx context.Context, page *db.Page) {
+	if page == nil || page.ID == "" || page.AccessToken == "" {
+		return
+	}
+
+	monitor := scheduler.NewTokenMonitor(h.store, h.fbClient)
+	if err := monitor.CheckPage(ctx, page); err != nil {
+		log.Printf("⚠️ Could not inspect token of page %s: %v", page.PageName, err)
+	}
+}
+
+// savePageTasks lưu quyền của user trên page (tasks trong me/accounts); rỗng = không rõ, bỏ qua
+func (h *Handler) savePageTasks(page *db.Page, tasks []string) {
+	if len(tasks) == 0 {
+		return
+	}
+	if err := h.store.SavePageTasks(page.PageID, tasks); err != nil {
+		log.Printf("⚠️ Failed to save tasks of page %s: %v", page.PageName, err)
+		return
+	}
+	page.Tasks = tasks
+	if !page.CanPost() {
+		log.Printf("🔒 Page %s: %s", page.PageName, page.MissingPostingPermission())
+	}
+}
+
+func formatTokenExpiry(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format("2006-01-02")
+}
+
+func formatTokenExpiryPtr(t *time.Time) string {
+	if t == nil {
+		return "never"
+	}
+	return formatTokenExpiry(*t)
+}
@@ -6,8 +6,11 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"fbscheduler/internal/facebook"
+
 	"github.com/google/uuid"
 )
 
@@ -48,6 +51,17 @@ func (h *Handler) UploadImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	// media_type (optional): "photo" | "video" | "reel" | "story" - reel chỉ nhận video
+	mediaType := r.FormValue("media_type")
+	if msg := validateMediaType(mediaType, 1); msg != "" {
+		respondError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if mediaType == facebook.MediaTypeReel && !strings.HasPrefix(contentType, "video/") {
+		respondError(w, http.StatusBadRequest, "A reel must be an MP4 or MOV video")
+		return
+	}
+	
 	// Create uploads directory if not exists
 	uploadsDir := "./uploads"
 	if err := os.MkdirAll(uploadsDir, 0755); err != nil {
@@ -73,6 +87,17 @@ func (h *Handler) UploadImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	// Reel / video story: kiểm tra tỉ lệ / thời lượng ngay khi upload để báo lỗi sớm
+	if mediaType == facebook.MediaTypeReel || (mediaType == facebook.MediaTypeStory && strings.HasPrefix(contentType, "video/")) {
+		if err := validateVideoFile(filepath, mediaType); err != nil {
+			fmt.Printf("❌ Invalid %s: %v\n", mediaType, err)
+			dst.Close()
+			os.Remove(filepath)
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	
 	// Return public URL
 	backendURL := os.Getenv("BACKEND_URL")
 	if backendURL == "" {
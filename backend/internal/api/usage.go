@@ -0,0 +1,14 @@
+package api
+
+import "net/http"
+
+// GetAPIUsage trả về snapshot usage Graph API gần nhất và thời điểm tạm dừng của từng page, nick
+func (h *Handler) GetAPIUsage(w http.ResponseWriter, r *http.Request) {
+	usages, err := h.store.GetAPIUsage()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch API usage")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, usages)
+}
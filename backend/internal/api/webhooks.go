@@ -0,0 +1,228 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+	"io"
+	"log"
+	"net/http"
+	"os"
+)
+
+// maxWebhookBodyBytes giới hạn kích thước 1 lần gửi webhook
+const maxWebhookBodyBytes = 1 << 20
+
+// maxNotificationExcerpt số ký tự tối đa của nội dung comment trong thông báo
+const maxNotificationExcerpt = 200
+
+// publishPermission quyền cần để đăng bài; bị thu hồi thì chặn bài pending của page
+const publishPermission = "pages_manage_posts"
+
+// VerifyFacebookWebhook GET /webhooks/facebook - Bắt tay xác minh khi đăng ký webhook trên Facebook App
+func (h *Handler) VerifyFacebookWebhook(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	verifyToken := os.Getenv("FACEBOOK_WEBHOOK_VERIFY_TOKEN")
+
+	if query.Get("hub.mode") != "subscribe" || verifyToken == "" || query.Get("hub.verify_token") != verifyToken {
+		log.Printf("⚠️ Webhook verification rejected (mode=%s)", query.Get("hub.mode"))
+		respondError(w, http.StatusForbidden, "Invalid verify token")
+		return
+	}
+
+	log.Println("✅ Webhook verified by Facebook")
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(query.Get("hub.challenge")))
+}
+
+// ReceiveFacebookWebhook POST /webhooks/facebook - Nhận sự kiện từ Facebook (đã ký X-Hub-Signature-256)
+func (h *Handler) ReceiveFacebookWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Failed to read body")
+		return
+	}
+
+	if err := h.fbClient.VerifyWebhookSignature(body, r.Header.Get(facebook.HeaderHubSignature256)); err != nil {
+		log.Printf("⚠️ Webhook rejected: %v", err)
+		if errors.Is(err, facebook.ErrNoAppSecret) {
+			respondError(w, http.StatusInternalServerError, "Webhook signature cannot be verified")
+			return
+		}
+		respondError(w, http.StatusUnauthorized, "Invalid signature")
+		return
+	}
+
+	event, err := facebook.ParseWebhookEvent(body)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Lỗi xử lý từng thay đổi chỉ ghi log: trả lỗi sẽ làm Facebook gửi lại cả lô
+	for _, entry := range event.Entry {
+		for _, change := range entry.Changes {
+			switch event.Object {
+			case "page":
+				h.handlePageChange(entry, change)
+			case "permissions":
+				h.handlePermissionChange(entry, change)
+			default:
+				h.saveWebhookEvent(&db.WebhookEvent{
+					Object:  event.Object,
+					EntryID: entry.ID,
+					Field:   change.Field,
+					Payload: change.Value,
+				})
+			}
+		}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "EVENT_RECEIVED"})
+}
+
+// handlePageChange lưu sự kiện của page; với bài đăng từ app thì báo bài bị gỡ, comment và reaction mới
+func (h *Handler) handlePageChange(entry facebook.WebhookEntry, change facebook.WebhookChange) {
+	event := &db.WebhookEvent{
+		Object:  "page",
+		EntryID: entry.ID,
+		Field:   change.Field,
+		Payload: change.Value,
+	}
+	if change.Field != "feed" {
+		h.saveWebhookEvent(event)
+		return
+	}
+
+	feed, err := change.Feed()
+	if err != nil {
+		log.Printf("⚠️ Invalid feed change for page %s: %v", entry.ID, err)
+		return
+	}
+	event.Item = feed.Item
+	event.Verb = feed.Verb
+	event.FacebookPostID = feed.PostID
+	event.CommentID = feed.CommentID
+	event.FromID = feed.From.ID
+	event.FromName = feed.From.Name
+	event.Message = feed.Message
+	if !h.saveWebhookEvent(event) || event.PageID == nil || event.PostLogID == nil {
+		return
+	}
+
+	// Bỏ qua hoạt động của chính page (vd: comment đầu tiên do app đăng)
+	fromPage := feed.From.ID == entry.ID
+
+	switch {
+	case feed.IsPostRemoval():
+		removed, err := h.store.MarkPostLogRemoved(*event.PostLogID)
+		if err != nil {
+			log.Printf("⚠️ Failed to mark post %s removed: %v", feed.PostID, err)
+			return
+		}
+		// Đã xóa từ app trước đó thì không cần báo
+		if removed {
+			log.Printf("🗑️ Post %s was removed from page %s on Facebook", feed.PostID, event.PageName)
+			h.store.NotifyPostRemoved(*event.PageID, event.PageName, feed.PostID)
+		}
+
+	case feed.Item == facebook.FeedItemComment && feed.Verb == facebook.FeedVerbAdd && !fromPage:
+		h.store.NotifyNewComment(*event.PageID, event.PageName, feed.From.Name, excerpt(feed.Message, maxNotificationExcerpt))
+
+	case feed.Item == facebook.FeedItemReaction && feed.Verb == facebook.FeedVerbAdd && !fromPage:
+		h.store.NotifyNewReaction(*event.PageID, event.PageName, feed.From.Name, feed.ReactionType)
+	}
+}
+
+// handlePermissionChange lưu thay đổi quyền; quyền bị thu hồi thì báo theo page (target_ids) hoặc theo nick
+func (h *Handler) handlePermissionChange(entry facebook.WebhookEntry, change facebook.WebhookChange) {
+	perm, err := change.Permission()
+	if err != nil {
+		log.Printf("⚠️ Invalid permissions change for user %s: %v", entry.ID, err)
+		return
+	}
+	h.saveWebhookEvent(&db.WebhookEvent{
+		Object:  "permissions",
+		EntryID: entry.ID,
+		Field:   change.Field,
+		Verb:    perm.Verb,
+		Payload: change.Value,
+	})
+	if perm.Verb != "revoked" {
+		return
+	}
+
+	if len(perm.TargetIDs) == 0 {
+		account, err := h.store.GetAccountByFbUserID(entry.ID)
+		if err != nil {
+			log.Printf("⚠️ Permission %s revoked by unknown user %s", change.Field, entry.ID)
+			return
+		}
+		log.Printf("🔒 Account %s revoked permission %s", account.FbUserName, change.Field)
+		h.store.NotifyAccountPermissionRevoked(account.ID, account.FbUserName, change.Field)
+		return
+	}
+
+	for _, pageID := range perm.TargetIDs {
+		page, err := h.store.GetPageByPageID(pageID)
+		if err != nil || page == nil {
+			continue
+		}
+
+		var blocked int64
+		if change.Field == publishPermission {
+			if blocked, err = h.store.BlockPendingPostsForPage(page.ID); err != nil {
+				log.Printf("⚠️ Failed to block pending posts of page %s: %v", page.PageName, err)
+			}
+		}
+		log.Printf("🔒 Permission %s revoked on page %s, blocked %d pending posts", change.Field, page.PageName, blocked)
+		h.store.NotifyPageAccessLost(page.ID, page.PageName, change.Field, int(blocked))
+	}
+}
+
+// saveWebhookEvent lưu sự kiện, trả về false nếu lỗi
+func (h *Handler) saveWebhookEvent(event *db.WebhookEvent) bool {
+	if err := h.store.SaveWebhookEvent(event); err != nil {
+		log.Printf("❌ Failed to save webhook event (%s/%s): %v", event.Object, event.Field, err)
+		return false
+	}
+	return true
+}
+
+// subscribePageWebhooks đăng ký webhook cho page vừa lưu; lỗi chỉ ghi log, không chặn việc lưu page
+func (h *Handler) subscribePageWebhooks(ctx context.Context, page *db.Page) {
+	if err := h.fbClient.SubscribePageWebhooks(ctx, page.PageID, page.AccessToken, facebook.WebhookFields); err != nil {
+		log.Printf("⚠️ Failed to subscribe page %s to webhooks: %v", page.PageName, err)
+		return
+	}
+	if err := h.store.SetPageWebhookSubscribed(page.ID); err != nil {
+		log.Printf("⚠️ Failed to record webhook subscription of page %s: %v", page.PageName, err)
+		return
+	}
+	log.Printf("📡 Subscribed page %s to webhooks", page.PageName)
+}
+
+// GetWebhookEvents GET /api/webhooks/events - Danh sách sự kiện webhook đã nhận
+func (h *Handler) GetWebhookEvents(w http.ResponseWriter, r *http.Request) {
+	limit := getQueryInt(r, "limit", 50)
+	offset := getQueryInt(r, "offset", 0)
+
+	events, err := h.store.GetWebhookEvents(limit, offset)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch webhook events")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, events)
+}
+
+// excerpt cắt s còn tối đa n ký tự
+func excerpt(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
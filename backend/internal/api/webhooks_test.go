@@ -0,0 +1,46 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fbscheduler/internal/facebook"
+)
+
+func TestReceiveFacebookWebhookSignature(t *testing.T) {
+	const appSecret = "secret"
+	// Không có entry nào → handler không cần DB
+	body := []byte(`{"object":"page","entry":[]}`)
+
+	tests := []struct {
+		name       string
+		appSecret  string
+		body       []byte
+		signature  string
+		wantStatus int
+	}{
+		{"valid signature", appSecret, body, facebook.WebhookSignature(body, appSecret), http.StatusOK},
+		{"missing signature", appSecret, body, "", http.StatusUnauthorized},
+		{"wrong secret", appSecret, body, facebook.WebhookSignature(body, "other"), http.StatusUnauthorized},
+		{"body changed after signing", appSecret, []byte(`{"object":"page","entry":[{}]}`), facebook.WebhookSignature(body, appSecret), http.StatusUnauthorized},
+		{"app secret not configured", "", body, facebook.WebhookSignature(body, appSecret), http.StatusInternalServerError},
+		{"signed invalid body", appSecret, []byte("not json"), facebook.WebhookSignature([]byte("not json"), appSecret), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlerWithClient(nil, nil, facebook.NewClient(facebook.WithAppSecret(tt.appSecret)))
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/facebook", bytes.NewReader(tt.body))
+			if tt.signature != "" {
+				req.Header.Set(facebook.HeaderHubSignature256, tt.signature)
+			}
+			rec := httptest.NewRecorder()
+
+			h.ReceiveFacebookWebhook(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
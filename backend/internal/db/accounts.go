@@ -3,6 +3,8 @@ package db
 import (
 	"database/sql"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // ============================================
@@ -21,6 +23,12 @@ type FacebookAccount struct {
 	Status              string     `json:"status"`
 	RateLimitUntil      *time.Time `json:"rate_limit_until"`
 	PostsToday          int        `json:"posts_today"`
+	StoriesToday        int        `json:"stories_today"`
+	MaxStoriesPerDay    int        `json:"max_stories_per_day"` // 0 = story tính chung vào max_posts_per_day
+	TokenScopes         []string   `json:"token_scopes"`
+	TokenIsValid        bool       `json:"token_is_valid"`
+	DataAccessExpiresAt *time.Time `json:"data_access_expires_at"`
+	TokenCheckedAt      *time.Time `json:"token_checked_at"` // Lần cuối kiểm tra bằng debug_token
 	LastPostAt          *time.Time `json:"last_post_at"`
 	LastErrorAt         *time.Time `json:"last_error_at"`
 	ConsecutiveFailures int        `json:"consecutive_failures"`
@@ -70,8 +78,11 @@ func (s *Store) GetAllAccounts() ([]FacebookAccount, error) {
 			fa.id, fa.fb_user_id, fa.fb_user_name, COALESCE(fa.profile_picture_url, ''),
 			fa.access_token, fa.token_expires_at, fa.max_pages, fa.max_posts_per_day,
 			fa.status, fa.rate_limit_until, fa.posts_today,
+			COALESCE(fa.stories_today, 0), COALESCE(fa.max_stories_per_day, 0),
 			fa.last_post_at, fa.last_error_at, fa.consecutive_failures,
 			fa.notes, fa.created_at, fa.updated_at,
+			COALESCE(fa.token_scopes, '{}'), COALESCE(fa.token_is_valid, true),
+			fa.data_access_expires_at, fa.token_checked_at,
 			COUNT(paa.id) as pages_count
 		FROM facebook_accounts fa
 		LEFT JOIN page_account_assignments paa ON paa.account_id = fa.id
@@ -92,8 +103,11 @@ func (s *Store) GetAllAccounts() ([]FacebookAccount, error) {
 			&a.ID, &a.FbUserID, &a.FbUserName, &a.ProfilePictureURL,
 			&a.AccessToken, &a.TokenExpiresAt, &a.MaxPages, &a.MaxPostsPerDay,
 			&a.Status, &a.RateLimitUntil, &a.PostsToday,
+			&a.StoriesToday, &a.MaxStoriesPerDay,
 			&a.LastPostAt, &a.LastErrorAt, &a.ConsecutiveFailures,
 			&a.Notes, &a.CreatedAt, &a.UpdatedAt,
+			pq.Array(&a.TokenScopes), &a.TokenIsValid,
+			&a.DataAccessExpiresAt, &a.TokenCheckedAt,
 			&a.PagesCount,
 		)
 		if err != nil {
@@ -113,8 +127,11 @@ func (s *Store) GetAccountByID(id string) (*FacebookAccount, error) {
 			fa.id, fa.fb_user_id, fa.fb_user_name, COALESCE(fa.profile_picture_url, ''),
 			fa.access_token, fa.token_expires_at, fa.max_pages, fa.max_posts_per_day,
 			fa.status, fa.rate_limit_until, fa.posts_today,
+			COALESCE(fa.stories_today, 0), COALESCE(fa.max_stories_per_day, 0),
 			fa.last_post_at, fa.last_error_at, fa.consecutive_failures,
 			fa.notes, fa.created_at, fa.updated_at,
+			COALESCE(fa.token_scopes, '{}'), COALESCE(fa.token_is_valid, true),
+			fa.data_access_expires_at, fa.token_checked_at,
 			COUNT(paa.id) as pages_count
 		FROM facebook_accounts fa
 		LEFT JOIN page_account_assignments paa ON paa.account_id = fa.id
@@ -127,8 +144,11 @@ func (s *Store) GetAccountByID(id string) (*FacebookAccount, error) {
 		&a.ID, &a.FbUserID, &a.FbUserName, &a.ProfilePictureURL,
 		&a.AccessToken, &a.TokenExpiresAt, &a.MaxPages, &a.MaxPostsPerDay,
 		&a.Status, &a.RateLimitUntil, &a.PostsToday,
+		&a.StoriesToday, &a.MaxStoriesPerDay,
 		&a.LastPostAt, &a.LastErrorAt, &a.ConsecutiveFailures,
 		&a.Notes, &a.CreatedAt, &a.UpdatedAt,
+		pq.Array(&a.TokenScopes), &a.TokenIsValid,
+		&a.DataAccessExpiresAt, &a.TokenCheckedAt,
 		&a.PagesCount,
 	)
 	if err != nil {
@@ -144,8 +164,11 @@ func (s *Store) GetAccountByFbUserID(fbUserID string) (*FacebookAccount, error)
 		SELECT id, fb_user_id, fb_user_name, COALESCE(profile_picture_url, ''),
 			access_token, token_expires_at, max_pages, max_posts_per_day,
 			status, rate_limit_until, posts_today,
+			COALESCE(stories_today, 0), COALESCE(max_stories_per_day, 0),
 			last_post_at, last_error_at, consecutive_failures,
-			notes, created_at, updated_at
+			notes, created_at, updated_at,
+			COALESCE(token_scopes, '{}'), COALESCE(token_is_valid, true),
+			data_access_expires_at, token_checked_at
 		FROM facebook_accounts
 		WHERE fb_user_id = $1
 	`
@@ -155,8 +178,11 @@ func (s *Store) GetAccountByFbUserID(fbUserID string) (*FacebookAccount, error)
 		&a.ID, &a.FbUserID, &a.FbUserName, &a.ProfilePictureURL,
 		&a.AccessToken, &a.TokenExpiresAt, &a.MaxPages, &a.MaxPostsPerDay,
 		&a.Status, &a.RateLimitUntil, &a.PostsToday,
+		&a.StoriesToday, &a.MaxStoriesPerDay,
 		&a.LastPostAt, &a.LastErrorAt, &a.ConsecutiveFailures,
 		&a.Notes, &a.CreatedAt, &a.UpdatedAt,
+		pq.Array(&a.TokenScopes), &a.TokenIsValid,
+		&a.DataAccessExpiresAt, &a.TokenCheckedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -169,15 +195,15 @@ func (s *Store) CreateAccount(a *FacebookAccount) error {
 	query := `
 		INSERT INTO facebook_accounts (
 			fb_user_id, fb_user_name, profile_picture_url, access_token, token_expires_at,
-			max_pages, max_posts_per_day, notes
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			max_pages, max_posts_per_day, notes, max_stories_per_day
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, created_at, updated_at
 	`
 
 	return s.db.QueryRow(
 		query,
 		a.FbUserID, a.FbUserName, a.ProfilePictureURL, a.AccessToken, a.TokenExpiresAt,
-		a.MaxPages, a.MaxPostsPerDay, a.Notes,
+		a.MaxPages, a.MaxPostsPerDay, a.Notes, a.MaxStoriesPerDay,
 	).Scan(&a.ID, &a.CreatedAt, &a.UpdatedAt)
 }
 
@@ -191,14 +217,15 @@ func (s *Store) UpdateAccount(a *FacebookAccount) error {
 			max_pages = $6,
 			max_posts_per_day = $7,
 			status = $8,
-			notes = $9
+			notes = $9,
+			max_stories_per_day = $10
 		WHERE id = $1
 	`
 
 	_, err := s.db.Exec(
 		query,
 		a.ID, a.FbUserName, a.ProfilePictureURL, a.AccessToken, a.TokenExpiresAt,
-		a.MaxPages, a.MaxPostsPerDay, a.Status, a.Notes,
+		a.MaxPages, a.MaxPostsPerDay, a.Status, a.Notes, a.MaxStoriesPerDay,
 	)
 	return err
 }
@@ -208,12 +235,53 @@ func (s *Store) DeleteAccount(id string) error {
 	return err
 }
 
+// UpdateAccountStatus cập nhật trạng thái nick (active, rate_limited, disabled, token_expired)
+func (s *Store) UpdateAccountStatus(id, status string) error {
+	_, err := s.db.Exec("UPDATE facebook_accounts SET status = $1 WHERE id = $2", status, id)
+	return err
+}
+
+// SaveAccountTokenInfo lưu kết quả debug_token của user token (hạn, quyền, còn hiệu lực không)
+func (s *Store) SaveAccountTokenInfo(id string, expiresAt, dataAccessExpiresAt *time.Time, scopes []string, isValid bool) error {
+	query := `
+		UPDATE facebook_accounts SET
+			token_expires_at = $2,
+			data_access_expires_at = $3,
+			token_scopes = $4,
+			token_is_valid = $5,
+			token_checked_at = NOW()
+		WHERE id = $1
+	`
+	_, err := s.db.Exec(query, id, expiresAt, dataAccessExpiresAt, pq.Array(scopes), isValid)
+	return err
+}
+
+// MarkTokenExpiryNotified ghi nhận đã thông báo token sắp hết hạn cho hạn expiresAt.
+// Trả về false nếu hạn này đã được thông báo trước đó (không cần thông báo lại).
+func (s *Store) MarkTokenExpiryNotified(id string, expiresAt time.Time) (bool, error) {
+	result, err := s.db.Exec(`
+		UPDATE facebook_accounts SET token_expiry_notified_at = $2
+		WHERE id = $1 AND token_expiry_notified_at IS DISTINCT FROM $2
+	`, id, expiresAt.UTC())
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
 // RecordSuccessfulPost ghi nhận post thành công
 func (s *Store) RecordSuccessfulPost(accountID, pageID string) error {
 	_, err := s.db.Exec("SELECT record_successful_post($1, $2)", accountID, pageID)
 	return err
 }
 
+// RecordSuccessfulStory ghi nhận story thành công (đếm riêng nếu nick có max_stories_per_day)
+func (s *Store) RecordSuccessfulStory(accountID, pageID string) error {
+	_, err := s.db.Exec("SELECT record_successful_story($1, $2)", accountID, pageID)
+	return err
+}
+
 // RecordPostFailure ghi nhận lỗi
 func (s *Store) RecordPostFailure(accountID string, isRateLimit bool) error {
 	_, err := s.db.Exec("SELECT record_post_failure($1, $2)", accountID, isRateLimit)
@@ -222,11 +290,33 @@ func (s *Store) RecordPostFailure(accountID string, isRateLimit bool) error {
 
 // GetBestAccountForPage lấy account tốt nhất để đăng bài
 func (s *Store) GetBestAccountForPage(pageID string) (*FacebookAccount, error) {
+	return s.getBestAccount(pageID, "fa.posts_today < fa.max_posts_per_day")
+}
+
+// GetBestAccountForStory lấy account tốt nhất để đăng story.
+// Nick có max_stories_per_day > 0 dùng hạn mức story riêng, còn lại dùng chung hạn mức bài đăng.
+func (s *Store) GetBestAccountForStory(pageID string) (*FacebookAccount, error) {
+	return s.getBestAccount(pageID, `CASE WHEN COALESCE(fa.max_stories_per_day, 0) > 0
+				THEN COALESCE(fa.stories_today, 0) < fa.max_stories_per_day
+				ELSE fa.posts_today < fa.max_posts_per_day END`)
+}
+
+// GetBestAccountForMediaType chọn GetBestAccountForStory hoặc GetBestAccountForPage theo loại bài
+func (s *Store) GetBestAccountForMediaType(pageID, mediaType string) (*FacebookAccount, error) {
+	if mediaType == "story" {
+		return s.GetBestAccountForStory(pageID)
+	}
+	return s.GetBestAccountForPage(pageID)
+}
+
+// getBestAccount lấy account active, không bị rate limit và còn hạn mức (limitCondition)
+func (s *Store) getBestAccount(pageID, limitCondition string) (*FacebookAccount, error) {
 	query := `
 		SELECT 
 			fa.id, fa.fb_user_id, fa.fb_user_name, COALESCE(fa.profile_picture_url, ''),
 			fa.access_token, fa.token_expires_at, fa.max_pages, fa.max_posts_per_day,
 			fa.status, fa.rate_limit_until, fa.posts_today,
+			COALESCE(fa.stories_today, 0), COALESCE(fa.max_stories_per_day, 0),
 			fa.last_post_at, fa.last_error_at, fa.consecutive_failures,
 			fa.notes, fa.created_at, fa.updated_at
 		FROM page_account_assignments pa
@@ -234,7 +324,7 @@ func (s *Store) GetBestAccountForPage(pageID string) (*FacebookAccount, error) {
 		WHERE pa.page_id = $1
 			AND fa.status = 'active'
 			AND (fa.rate_limit_until IS NULL OR fa.rate_limit_until < NOW())
-			AND fa.posts_today < fa.max_posts_per_day
+			AND ` + limitCondition + `
 		ORDER BY 
 			pa.is_primary DESC,
 			fa.posts_today ASC,
@@ -247,6 +337,7 @@ func (s *Store) GetBestAccountForPage(pageID string) (*FacebookAccount, error) {
 		&a.ID, &a.FbUserID, &a.FbUserName, &a.ProfilePictureURL,
 		&a.AccessToken, &a.TokenExpiresAt, &a.MaxPages, &a.MaxPostsPerDay,
 		&a.Status, &a.RateLimitUntil, &a.PostsToday,
+		&a.StoriesToday, &a.MaxStoriesPerDay,
 		&a.LastPostAt, &a.LastErrorAt, &a.ConsecutiveFailures,
 		&a.Notes, &a.CreatedAt, &a.UpdatedAt,
 	)
@@ -162,6 +162,7 @@ func (s *Store) GetPrimaryAccountForPage(pageID string) (*FacebookAccount, error
 			fa.id, fa.fb_user_id, fa.fb_user_name, fa.access_token,
 			fa.token_expires_at, fa.max_pages, fa.max_posts_per_day,
 			fa.status, fa.rate_limit_until, fa.posts_today,
+			COALESCE(fa.stories_today, 0), COALESCE(fa.max_stories_per_day, 0),
 			fa.last_post_at, fa.last_error_at, fa.consecutive_failures,
 			fa.notes, fa.created_at, fa.updated_at
 		FROM facebook_accounts fa
@@ -174,6 +175,7 @@ func (s *Store) GetPrimaryAccountForPage(pageID string) (*FacebookAccount, error
 		&a.ID, &a.FbUserID, &a.FbUserName, &a.AccessToken,
 		&a.TokenExpiresAt, &a.MaxPages, &a.MaxPostsPerDay,
 		&a.Status, &a.RateLimitUntil, &a.PostsToday,
+		&a.StoriesToday, &a.MaxStoriesPerDay,
 		&a.LastPostAt, &a.LastErrorAt, &a.ConsecutiveFailures,
 		&a.Notes, &a.CreatedAt, &a.UpdatedAt,
 	)
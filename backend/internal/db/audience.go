@@ -0,0 +1,56 @@
+package db
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Audience là nhóm người xem theo vị trí, ngôn ngữ, tuổi (cùng cấu trúc facebook.Audience).
+// Regions/Cities là key vị trí của Facebook (vd: key của tỉnh/thành Việt Nam).
+type Audience struct {
+	Countries []string `json:"countries,omitempty"` // mã quốc gia ISO, vd "VN"
+	Regions   []string `json:"regions,omitempty"`
+	Cities    []string `json:"cities,omitempty"`
+	Locales   []int    `json:"locales,omitempty"` // ID ngôn ngữ của Facebook
+	AgeMin    int      `json:"age_min,omitempty"`
+	AgeMax    int      `json:"age_max,omitempty"`
+}
+
+// Value lưu Audience vào cột JSONB (nil *Audience = NULL)
+func (a Audience) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
+
+// audienceScanner đọc cột JSONB vào *Audience (NULL = nil)
+type audienceScanner struct {
+	dst **Audience
+}
+
+func (s audienceScanner) Scan(src interface{}) error {
+	*s.dst = nil
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Audience", src)
+	}
+
+	var a Audience
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	*s.dst = &a
+	return nil
+}
+
+// scanAudience dùng trong Scan: rows.Scan(..., scanAudience(&p.Targeting))
+func scanAudience(dst **Audience) sql.Scanner {
+	return audienceScanner{dst: dst}
+}
@@ -0,0 +1,87 @@
+// Package dbtest mở Postgres cho test: mỗi test 1 schema riêng đã chạy mọi migration,
+// xóa khi test xong. Không đặt TEST_DATABASE_URL thì test bị bỏ qua.
+package dbtest
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"fbscheduler/internal/db"
+
+	_ "github.com/lib/pq"
+)
+
+// EnvDatabaseURL là biến môi trường chứa DSN Postgres dùng cho test
+const EnvDatabaseURL = "TEST_DATABASE_URL"
+
+// NewStore tạo Store trên schema mới của TEST_DATABASE_URL
+func NewStore(t testing.TB) *db.Store {
+	t.Helper()
+	dsn := os.Getenv(EnvDatabaseURL)
+	if dsn == "" {
+		t.Skip(EnvDatabaseURL + " is not set")
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		admin.Close()
+		t.Fatalf("create schema: %v", err)
+	}
+
+	conn, err := sql.Open("postgres", withSearchPath(dsn, schema))
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		admin.Close()
+	})
+
+	for _, path := range migrationFiles(t) {
+		migration, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read migration: %v", err)
+		}
+		if _, err := conn.Exec(string(migration)); err != nil {
+			t.Fatalf("migration %s: %v", filepath.Base(path), err)
+		}
+	}
+	return db.NewStore(conn)
+}
+
+// withSearchPath thêm search_path vào DSN (dạng URL hoặc key=value)
+func withSearchPath(dsn, schema string) string {
+	if strings.Contains(dsn, "://") {
+		sep := "?"
+		if strings.Contains(dsn, "?") {
+			sep = "&"
+		}
+		return dsn + sep + "search_path=" + schema + ",public"
+	}
+	return dsn + " search_path=" + schema + ",public"
+}
+
+// migrationFiles liệt kê backend/migrations/*.sql theo thứ tự tên file
+func migrationFiles(t testing.TB) []string {
+	t.Helper()
+	_, file, _, _ := runtime.Caller(0)
+	dir := filepath.Join(filepath.Dir(file), "..", "..", "..", "migrations")
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no migrations found in %s", dir)
+	}
+	sort.Strings(files)
+	return files
+}
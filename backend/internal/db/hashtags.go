@@ -0,0 +1,157 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// HashtagCache là kết quả tra hashtag Instagram đã lưu của 1 tài khoản IG
+type HashtagCache struct {
+	InstagramAccountID string    `json:"instagram_account_id"`
+	Name               string    `json:"name"`
+	HashtagID          string    `json:"hashtag_id"` // rỗng = không tồn tại trên Instagram
+	TopMediaCount      int       `json:"top_media_count"`
+	RecentMediaCount   int       `json:"recent_media_count"`
+	FetchedAt          time.Time `json:"fetched_at"`
+
+	Age            time.Duration `json:"-"` // thời gian từ lần lấy số liệu (tính trong DB)
+	InSearchWindow bool          `json:"-"` // đã tra trong cửa sổ hạn mức, tra lại không tốn lượt
+}
+
+// HashtagUsage là thống kê hashtag trong các bài đã đăng thành công
+type HashtagUsage struct {
+	Name       string    `json:"name"`
+	Posts      int       `json:"posts"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	AvgReach   float64   `json:"avg_reach"` // reach trung bình (snapshot insights mới nhất)
+}
+
+// GetHashtagCache lấy hashtag đã tra của tài khoản IG, nil nếu chưa có.
+// window là cửa sổ hạn mức tra hashtag (7 ngày).
+func (s *Store) GetHashtagCache(igAccountID, name string, window time.Duration) (*HashtagCache, error) {
+	query := `
+		SELECT instagram_account_id, name, COALESCE(hashtag_id, ''),
+			COALESCE(top_media_count, 0), COALESCE(recent_media_count, 0), fetched_at,
+			EXTRACT(EPOCH FROM (NOW() - fetched_at))::float8,
+			COALESCE(searched_at > NOW() - make_interval(secs => $3), false)
+		FROM hashtag_cache
+		WHERE instagram_account_id = $1 AND name = $2
+	`
+
+	var c HashtagCache
+	var age float64
+	err := s.db.QueryRow(query, igAccountID, name, window.Seconds()).Scan(
+		&c.InstagramAccountID, &c.Name, &c.HashtagID,
+		&c.TopMediaCount, &c.RecentMediaCount, &c.FetchedAt,
+		&age, &c.InSearchWindow,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.Age = time.Duration(age * float64(time.Second))
+	return &c, nil
+}
+
+// CountHashtagSearches đếm số hashtag khác nhau tài khoản IG đã tra trong window
+func (s *Store) CountHashtagSearches(igAccountID string, window time.Duration) (int, error) {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM hashtag_cache
+		WHERE instagram_account_id = $1 AND searched_at > NOW() - make_interval(secs => $2)
+	`, igAccountID, window.Seconds()).Scan(&count)
+	return count, err
+}
+
+// SaveHashtagCache lưu số liệu hashtag; searched = vừa gọi ig_hashtag_search (tính lại hạn mức)
+func (s *Store) SaveHashtagCache(c *HashtagCache, searched bool) error {
+	query := `
+		INSERT INTO hashtag_cache (instagram_account_id, name, hashtag_id, top_media_count, recent_media_count,
+			searched_at, fetched_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, NOW(), NOW())
+		ON CONFLICT (instagram_account_id, name)
+		DO UPDATE SET
+			hashtag_id = EXCLUDED.hashtag_id,
+			top_media_count = EXCLUDED.top_media_count,
+			recent_media_count = EXCLUDED.recent_media_count,
+			searched_at = CASE WHEN $6 THEN NOW() ELSE hashtag_cache.searched_at END,
+			fetched_at = NOW()
+		RETURNING fetched_at
+	`
+	return s.db.QueryRow(query, c.InstagramAccountID, c.Name, c.HashtagID,
+		c.TopMediaCount, c.RecentMediaCount, searched).Scan(&c.FetchedAt)
+}
+
+// SearchHashtagCache lấy các hashtag đã tra (có trên Instagram) bắt đầu bằng prefix, nhiều bài gần đây trước
+func (s *Store) SearchHashtagCache(igAccountID, prefix string, limit int) ([]HashtagCache, error) {
+	query := `
+		SELECT instagram_account_id, name, hashtag_id,
+			COALESCE(top_media_count, 0), COALESCE(recent_media_count, 0), fetched_at
+		FROM hashtag_cache
+		WHERE instagram_account_id = $1 AND left(name, length($2)) = $2
+		  AND COALESCE(hashtag_id, '') <> ''
+		ORDER BY recent_media_count DESC, name
+		LIMIT $3
+	`
+
+	rows, err := s.db.Query(query, igAccountID, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cached := make([]HashtagCache, 0)
+	for rows.Next() {
+		var c HashtagCache
+		if err := rows.Scan(&c.InstagramAccountID, &c.Name, &c.HashtagID,
+			&c.TopMediaCount, &c.RecentMediaCount, &c.FetchedAt); err != nil {
+			return nil, err
+		}
+		cached = append(cached, c)
+	}
+	return cached, rows.Err()
+}
+
+// GetHashtagUsage thống kê hashtag (bắt đầu bằng prefix) trong nội dung các bài đã đăng thành công,
+// dùng nhiều nhất trước
+func (s *Store) GetHashtagUsage(prefix string, limit int) ([]HashtagUsage, error) {
+	query := `
+		WITH tags AS (
+			SELECT DISTINCT p.id AS post_id, lower(m[1]) AS name
+			FROM posts p, regexp_matches(p.content, '#([[:alnum:]_]+)', 'g') AS m
+		), published AS (
+			SELECT id, post_id, posted_at FROM post_logs
+			WHERE status IN ('success', 'partial') AND COALESCE(action, 'publish') = 'publish'
+		), latest AS (
+			SELECT DISTINCT ON (post_log_id) post_log_id, reach
+			FROM post_insights
+			ORDER BY post_log_id, collected_at DESC
+		)
+		SELECT t.name, COUNT(DISTINCT pub.post_id), MAX(pub.posted_at), COALESCE(AVG(latest.reach), 0)::float8
+		FROM tags t
+		JOIN published pub ON pub.post_id = t.post_id
+		LEFT JOIN latest ON latest.post_log_id = pub.id
+		WHERE left(t.name, length($1)) = $1
+		GROUP BY t.name
+		ORDER BY COUNT(DISTINCT pub.post_id) DESC, t.name
+		LIMIT $2
+	`
+
+	rows, err := s.db.Query(query, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := make([]HashtagUsage, 0)
+	for rows.Next() {
+		var u HashtagUsage
+		if err := rows.Scan(&u.Name, &u.Posts, &u.LastUsedAt, &u.AvgReach); err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
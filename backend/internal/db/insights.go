@@ -0,0 +1,201 @@
+package db
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PostInsight là 1 snapshot insights của bài đã đăng lên 1 page
+type PostInsight struct {
+	ID             string    `json:"id"`
+	PostLogID      string    `json:"post_log_id"`
+	PostID         string    `json:"post_id"`
+	PageID         string    `json:"page_id"`
+	PageName       string    `json:"page_name,omitempty"`
+	FacebookPostID string    `json:"facebook_post_id"`
+	Impressions    int64     `json:"impressions"`
+	Reach          int64     `json:"reach"`
+	Reactions      int64     `json:"reactions"`
+	Comments       int64     `json:"comments"`
+	Shares         int64     `json:"shares"`
+	Clicks         int64     `json:"clicks"`
+	CollectedAt    time.Time `json:"collected_at"`
+}
+
+// InsightsDue là bài đã đăng tới mốc thu thập insights tiếp theo
+type InsightsDue struct {
+	PostLogID      string
+	PostID         string
+	PageID         string
+	PageName       string
+	FacebookPostID string
+	AccessToken    string
+	Age            time.Duration // thời gian từ lúc đăng (tính trong DB, tránh lệch timezone)
+	Collections    int           // số mốc đã thu thập
+	Failures       int           // số lần lấy insights lỗi ở mốc hiện tại
+}
+
+// PageInsightsSummary tổng hợp insights (snapshot mới nhất của mỗi bài) theo page
+type PageInsightsSummary struct {
+	PageID         string  `json:"page_id"`
+	PageName       string  `json:"page_name"`
+	Posts          int     `json:"posts"`
+	Impressions    int64   `json:"impressions"`
+	Reach          int64   `json:"reach"`
+	Reactions      int64   `json:"reactions"`
+	Comments       int64   `json:"comments"`
+	Shares         int64   `json:"shares"`
+	Clicks         int64   `json:"clicks"`
+	AvgReach       float64 `json:"avg_reach"`
+	EngagementRate float64 `json:"engagement_rate"` // (reactions + comments + shares) / reach
+}
+
+// GetDueInsights lấy tối đa limit bài đã tới mốc thu thập tiếp theo.
+// windows là các mốc tính từ lúc đăng (vd: 1h, 6h, 24h, 7 ngày).
+// Bỏ qua story và bài Instagram.
+func (s *Store) GetDueInsights(windows []time.Duration, limit int) ([]InsightsDue, error) {
+	seconds := make([]float64, len(windows))
+	for i, w := range windows {
+		seconds[i] = w.Seconds()
+	}
+
+	query := `
+		SELECT pl.id, pl.post_id, pl.page_id, pg.page_name, pl.facebook_post_id, pg.access_token,
+			EXTRACT(EPOCH FROM (NOW() - pl.posted_at))::float8, COALESCE(pl.insights_collections, 0),
+			COALESCE(pl.insights_failures, 0)
+		FROM post_logs pl
+		JOIN posts p ON pl.post_id = p.id
+		JOIN pages pg ON pl.page_id = pg.id
+		WHERE pl.status IN ('success', 'partial')
+		  AND COALESCE(pl.action, 'publish') = 'publish'
+		  AND COALESCE(pl.platform, 'facebook') = 'facebook'
+		  AND pl.deleted_at IS NULL
+		  AND COALESCE(pl.facebook_post_id, '') <> ''
+		  AND COALESCE(p.media_type, '') <> 'story'
+		  AND pg.is_active = true
+		  AND COALESCE(pl.insights_collections, 0) < $1
+		  AND pl.posted_at + make_interval(secs => ($2::float8[])[COALESCE(pl.insights_collections, 0) + 1]) <= NOW()
+		ORDER BY pl.posted_at ASC
+		LIMIT $3
+	`
+
+	rows, err := s.db.Query(query, len(windows), pq.Array(seconds), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	due := make([]InsightsDue, 0)
+	for rows.Next() {
+		var d InsightsDue
+		var ageSeconds float64
+		if err := rows.Scan(&d.PostLogID, &d.PostID, &d.PageID, &d.PageName, &d.FacebookPostID, &d.AccessToken,
+			&ageSeconds, &d.Collections, &d.Failures); err != nil {
+			return nil, err
+		}
+		d.Age = time.Duration(ageSeconds * float64(time.Second))
+		due = append(due, d)
+	}
+	return due, rows.Err()
+}
+
+// SavePostInsight lưu 1 snapshot insights
+func (s *Store) SavePostInsight(in *PostInsight) error {
+	query := `
+		INSERT INTO post_insights (post_log_id, post_id, page_id, facebook_post_id,
+			impressions, reach, reactions, comments, shares, clicks)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, collected_at
+	`
+	return s.db.QueryRow(query, in.PostLogID, in.PostID, in.PageID, in.FacebookPostID,
+		in.Impressions, in.Reach, in.Reactions, in.Comments, in.Shares, in.Clicks,
+	).Scan(&in.ID, &in.CollectedAt)
+}
+
+// SetInsightsCollections cập nhật số mốc thu thập đã qua của 1 post_log (đếm lỗi lại từ 0)
+func (s *Store) SetInsightsCollections(postLogID string, collections int) error {
+	_, err := s.db.Exec(`UPDATE post_logs SET insights_collections = $2, insights_failures = 0 WHERE id = $1`, postLogID, collections)
+	return err
+}
+
+// RecordInsightsFailure tăng số lần lấy insights lỗi ở mốc hiện tại của 1 post_log
+func (s *Store) RecordInsightsFailure(postLogID string) error {
+	_, err := s.db.Exec(`UPDATE post_logs SET insights_failures = COALESCE(insights_failures, 0) + 1 WHERE id = $1`, postLogID)
+	return err
+}
+
+// GetPostInsights lấy toàn bộ snapshot insights của 1 bài (mọi page), cũ → mới
+func (s *Store) GetPostInsights(postID string) ([]PostInsight, error) {
+	query := `
+		SELECT pi.id, pi.post_log_id, pi.post_id, pi.page_id, COALESCE(pg.page_name, ''), pi.facebook_post_id,
+			pi.impressions, pi.reach, pi.reactions, pi.comments, pi.shares, pi.clicks, pi.collected_at
+		FROM post_insights pi
+		LEFT JOIN pages pg ON pi.page_id = pg.id
+		WHERE pi.post_id = $1
+		ORDER BY pi.page_id, pi.collected_at ASC
+	`
+
+	rows, err := s.db.Query(query, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	insights := make([]PostInsight, 0)
+	for rows.Next() {
+		var in PostInsight
+		if err := rows.Scan(&in.ID, &in.PostLogID, &in.PostID, &in.PageID, &in.PageName, &in.FacebookPostID,
+			&in.Impressions, &in.Reach, &in.Reactions, &in.Comments, &in.Shares, &in.Clicks, &in.CollectedAt); err != nil {
+			return nil, err
+		}
+		insights = append(insights, in)
+	}
+	return insights, rows.Err()
+}
+
+// GetPageInsightsSummaries tổng hợp insights theo page cho các bài đăng trong days ngày gần nhất.
+// pageID rỗng = mọi page.
+func (s *Store) GetPageInsightsSummaries(pageID string, days int) ([]PageInsightsSummary, error) {
+	query := `
+		WITH latest AS (
+			SELECT DISTINCT ON (pi.post_log_id) pi.*
+			FROM post_insights pi
+			JOIN post_logs pl ON pl.id = pi.post_log_id
+			WHERE ($1 = '' OR pi.page_id::text = $1)
+			  AND pl.posted_at >= NOW() - make_interval(days => $2)
+			ORDER BY pi.post_log_id, pi.collected_at DESC
+		)
+		SELECT l.page_id, pg.page_name, COUNT(*),
+			COALESCE(SUM(l.impressions), 0), COALESCE(SUM(l.reach), 0),
+			COALESCE(SUM(l.reactions), 0), COALESCE(SUM(l.comments), 0),
+			COALESCE(SUM(l.shares), 0), COALESCE(SUM(l.clicks), 0)
+		FROM latest l
+		JOIN pages pg ON pg.id = l.page_id
+		GROUP BY l.page_id, pg.page_name
+		ORDER BY SUM(l.reach) DESC
+	`
+
+	rows, err := s.db.Query(query, pageID, days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := make([]PageInsightsSummary, 0)
+	for rows.Next() {
+		var p PageInsightsSummary
+		if err := rows.Scan(&p.PageID, &p.PageName, &p.Posts,
+			&p.Impressions, &p.Reach, &p.Reactions, &p.Comments, &p.Shares, &p.Clicks); err != nil {
+			return nil, err
+		}
+		if p.Posts > 0 {
+			p.AvgReach = float64(p.Reach) / float64(p.Posts)
+		}
+		if p.Reach > 0 {
+			p.EngagementRate = float64(p.Reactions+p.Comments+p.Shares) / float64(p.Reach)
+		}
+		summaries = append(summaries, p)
+	}
+	return summaries, rows.Err()
+}
@@ -0,0 +1,70 @@
+package db
+
+import "time"
+
+// SavedLocation là địa điểm check-in đã lưu của 1 page
+type SavedLocation struct {
+	ID        string    `json:"id"`
+	PageID    string    `json:"page_id"`
+	PlaceID   string    `json:"place_id"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GetSavedLocations lấy địa điểm đã lưu của page, theo tên
+func (s *Store) GetSavedLocations(pageID string) ([]SavedLocation, error) {
+	query := `
+		SELECT id, page_id, place_id, name, COALESCE(address, ''),
+			COALESCE(latitude, 0), COALESCE(longitude, 0), created_at
+		FROM saved_locations
+		WHERE page_id = $1
+		ORDER BY name
+	`
+
+	rows, err := s.db.Query(query, pageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := make([]SavedLocation, 0)
+	for rows.Next() {
+		var l SavedLocation
+		if err := rows.Scan(&l.ID, &l.PageID, &l.PlaceID, &l.Name, &l.Address,
+			&l.Latitude, &l.Longitude, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		locations = append(locations, l)
+	}
+	return locations, rows.Err()
+}
+
+// SaveLocation lưu địa điểm cho page; đã có place_id thì cập nhật tên, địa chỉ, tọa độ
+func (s *Store) SaveLocation(l *SavedLocation) error {
+	query := `
+		INSERT INTO saved_locations (page_id, place_id, name, address, latitude, longitude)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (page_id, place_id)
+		DO UPDATE SET
+			name = EXCLUDED.name,
+			address = EXCLUDED.address,
+			latitude = EXCLUDED.latitude,
+			longitude = EXCLUDED.longitude
+		RETURNING id, created_at
+	`
+	return s.db.QueryRow(query, l.PageID, l.PlaceID, l.Name, l.Address, l.Latitude, l.Longitude).
+		Scan(&l.ID, &l.CreatedAt)
+}
+
+// DeleteSavedLocation xóa địa điểm đã lưu của page, false nếu không tìm thấy
+func (s *Store) DeleteSavedLocation(pageID, id string) (bool, error) {
+	result, err := s.db.Exec(`DELETE FROM saved_locations WHERE id = $1 AND page_id = $2`, id, pageID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
@@ -4,16 +4,24 @@ import "github.com/lib/pq"
 
 func (s *Store) CreatePostLog(log *PostLog) error {
 	query := `
-		INSERT INTO post_logs (scheduled_post_id, post_id, page_id, facebook_post_id, status, error_message, response_data)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO post_logs (scheduled_post_id, post_id, page_id, facebook_post_id, status, error_message, response_data, comment_id, action, platform,
+			permalink_url, fb_created_time, media_ids)
+		VALUES (NULLIF($1, '')::uuid, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), $12, $13)
 		RETURNING id, posted_at
 	`
 
+	// Đăng ngay / sửa / xóa không có scheduled_post_id → NULL
 	// Đảm bảo response_data là JSON hợp lệ (null nếu empty)
-	responseData := log.ResponseData
+	responseData := string(log.ResponseData)
 	if responseData == "" {
 		responseData = "{}"
 	}
+	if log.Action == "" {
+		log.Action = "publish"
+	}
+	if log.Platform == "" {
+		log.Platform = PlatformFacebook
+	}
 
 	return s.db.QueryRow(
 		query,
@@ -24,24 +32,35 @@ func (s *Store) CreatePostLog(log *PostLog) error {
 		log.Status,
 		log.ErrorMessage,
 		responseData,
+		log.CommentID,
+		log.Action,
+		log.Platform,
+		log.PermalinkURL,
+		log.FbCreatedTime,
+		pq.Array(log.MediaIDs),
 	).Scan(&log.ID, &log.PostedAt)
 }
 
-func (s *Store) GetPostLogs(limit, offset int) ([]PostLog, error) {
+// GetPostLogs lấy lịch sử đăng bài mới nhất trước. status rỗng = mọi trạng thái;
+// "success" gồm cả "partial" (bài đã đăng, chỉ comment đầu tiên lỗi).
+func (s *Store) GetPostLogs(status string, limit, offset int) ([]PostLog, error) {
 	query := `
 		SELECT 
-			pl.id, pl.scheduled_post_id, pl.post_id, pl.page_id, 
-			pl.facebook_post_id, pl.status, pl.error_message, pl.posted_at,
+			pl.id, COALESCE(pl.scheduled_post_id::text, ''), pl.post_id, pl.page_id, 
+			pl.facebook_post_id, pl.status, pl.error_message, pl.posted_at, COALESCE(pl.comment_id, ''),
+			COALESCE(pl.action, 'publish'), COALESCE(pl.platform, 'facebook'), pl.edited_at, pl.deleted_at,
+			COALESCE(pl.permalink_url, ''), pl.fb_created_time, COALESCE(pl.media_ids, '{}'), pl.response_data,
 			p.content, p.media_urls,
 			pg.page_name, pg.profile_picture_url
 		FROM post_logs pl
 		JOIN posts p ON pl.post_id = p.id
 		JOIN pages pg ON pl.page_id = pg.id
+		WHERE $1 = '' OR pl.status = $1 OR ($1 = 'success' AND pl.status = 'partial')
 		ORDER BY pl.posted_at DESC
-		LIMIT $1 OFFSET $2
+		LIMIT $2 OFFSET $3
 	`
 	
-	rows, err := s.db.Query(query, limit, offset)
+	rows, err := s.db.Query(query, status, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -50,20 +69,99 @@ func (s *Store) GetPostLogs(limit, offset int) ([]PostLog, error) {
 	logs := make([]PostLog, 0)
 	for rows.Next() {
 		var log PostLog
+		var responseData []byte
 		log.Post = &Post{}
 		log.Page = &Page{}
 		
 		err := rows.Scan(
 			&log.ID, &log.ScheduledPostID, &log.PostID, &log.PageID,
-			&log.FacebookPostID, &log.Status, &log.ErrorMessage, &log.PostedAt,
+			&log.FacebookPostID, &log.Status, &log.ErrorMessage, &log.PostedAt, &log.CommentID,
+			&log.Action, &log.Platform, &log.EditedAt, &log.DeletedAt,
+			&log.PermalinkURL, &log.FbCreatedTime, pq.Array(&log.MediaIDs), &responseData,
 			&log.Post.Content, pq.Array(&log.Post.MediaURLs),
 			&log.Page.PageName, &log.Page.ProfilePictureURL,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if len(responseData) > 0 && string(responseData) != "{}" {
+			log.ResponseData = responseData
+		}
 		logs = append(logs, log)
 	}
 	
 	return logs, nil
 }
+
+// GetPostLogByID lấy 1 dòng post_logs kèm page (có access token) để sửa / xóa bài trên Facebook
+func (s *Store) GetPostLogByID(id string) (*PostLog, error) {
+	logs, err := s.queryPostLogsWithPage(`pl.id = $1`, id)
+	if err != nil || len(logs) == 0 {
+		return nil, err
+	}
+	return &logs[0], nil
+}
+
+// GetLivePostLogs lấy các dòng publish Facebook thành công, chưa bị xóa của 1 bài (mọi page)
+func (s *Store) GetLivePostLogs(postID string) ([]PostLog, error) {
+	return s.queryPostLogsWithPage(`pl.post_id = $1
+		  AND COALESCE(pl.action, 'publish') = 'publish'
+		  AND COALESCE(pl.platform, 'facebook') = 'facebook'
+		  AND pl.status IN ('success', 'partial')
+		  AND COALESCE(pl.facebook_post_id, '') <> ''
+		  AND pl.deleted_at IS NULL`, postID)
+}
+
+// queryPostLogsWithPage lấy post_logs theo điều kiện where, join page (tên + access token)
+func (s *Store) queryPostLogsWithPage(where string, args ...interface{}) ([]PostLog, error) {
+	query := `
+		SELECT 
+			pl.id, COALESCE(pl.scheduled_post_id::text, ''), pl.post_id, pl.page_id,
+			COALESCE(pl.facebook_post_id, ''), pl.status, COALESCE(pl.error_message, ''), pl.posted_at,
+			COALESCE(pl.comment_id, ''), COALESCE(pl.action, 'publish'), COALESCE(pl.platform, 'facebook'),
+			pl.edited_at, pl.deleted_at, COALESCE(pl.permalink_url, ''),
+			pg.page_id, pg.page_name, pg.access_token, COALESCE(p.media_type, '')
+		FROM post_logs pl
+		JOIN pages pg ON pl.page_id = pg.id
+		LEFT JOIN posts p ON pl.post_id = p.id
+		WHERE ` + where + `
+		ORDER BY pl.posted_at ASC
+	`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := make([]PostLog, 0)
+	for rows.Next() {
+		var log PostLog
+		log.Page = &Page{}
+		log.Post = &Post{}
+		err := rows.Scan(
+			&log.ID, &log.ScheduledPostID, &log.PostID, &log.PageID,
+			&log.FacebookPostID, &log.Status, &log.ErrorMessage, &log.PostedAt,
+			&log.CommentID, &log.Action, &log.Platform, &log.EditedAt, &log.DeletedAt, &log.PermalinkURL,
+			&log.Page.PageID, &log.Page.PageName, &log.Page.AccessToken, &log.Post.MediaType,
+		)
+		if err != nil {
+			return nil, err
+		}
+		log.Page.ID = log.PageID
+		logs = append(logs, log)
+	}
+	return logs, rows.Err()
+}
+
+// MarkPostLogEdited đánh dấu bài của dòng publish đã được sửa trên Facebook
+func (s *Store) MarkPostLogEdited(id string) error {
+	_, err := s.db.Exec(`UPDATE post_logs SET edited_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+// MarkPostLogDeleted đánh dấu bài của dòng publish đã bị xóa trên Facebook (dừng thu thập insights)
+func (s *Store) MarkPostLogDeleted(id string) error {
+	_, err := s.db.Exec(`UPDATE post_logs SET deleted_at = NOW() WHERE id = $1`, id)
+	return err
+}
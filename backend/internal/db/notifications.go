@@ -1,5 +1,7 @@
 package db
 
+import "strconv"
+
 // ============================================
 // NOTIFICATIONS METHODS
 // ============================================
@@ -150,12 +152,34 @@ func (s *Store) NotifyTokenExpiring(accountID string, accountName string, daysLe
 	n := &Notification{
 		Type:      "token_expiring",
 		Title:     "Token sắp hết hạn",
-		Message:   "Token của nick " + accountName + " sẽ hết hạn trong " + string(rune(daysLeft)) + " ngày. Vui lòng gia hạn.",
+		Message:   "Token của nick " + accountName + " sẽ hết hạn trong " + strconv.Itoa(daysLeft) + " ngày. Vui lòng gia hạn.",
+		AccountID: &accountID,
+	}
+	return s.CreateNotification(n)
+}
+
+// NotifyTokenExpired tạo thông báo token đã hết hạn hoặc bị thu hồi
+func (s *Store) NotifyTokenExpired(accountID string, accountName string) error {
+	n := &Notification{
+		Type:      "token_expired",
+		Title:     "Token đã hết hạn",
+		Message:   "Token của nick " + accountName + " đã hết hạn hoặc bị thu hồi. Vui lòng đăng nhập lại.",
 		AccountID: &accountID,
 	}
 	return s.CreateNotification(n)
 }
 
+// NotifyPageTokenInvalid tạo thông báo token page không còn hiệu lực
+func (s *Store) NotifyPageTokenInvalid(pageID string, pageName string, blockedPosts int) error {
+	n := &Notification{
+		Type:    "page_token_invalid",
+		Title:   "Token page không hợp lệ",
+		Message: "Token của page " + pageName + " đã hết hạn hoặc bị thu hồi. " + strconv.Itoa(blockedPosts) + " bài đã lên lịch bị tạm dừng cho đến khi đăng nhập lại.",
+		PageID:  &pageID,
+	}
+	return s.CreateNotification(n)
+}
+
 // NotifyPostFailed tạo thông báo đăng bài thất bại
 func (s *Store) NotifyPostFailed(accountID string, accountName string, pageName string, reason string) error {
 	n := &Notification{
@@ -167,6 +191,39 @@ func (s *Store) NotifyPostFailed(accountID string, accountName string, pageName
 	return s.CreateNotification(n)
 }
 
+// NotifyFirstCommentFailed tạo thông báo bài đã đăng nhưng comment đầu tiên lỗi
+func (s *Store) NotifyFirstCommentFailed(pageID string, pageName string, reason string) error {
+	n := &Notification{
+		Type:    "first_comment_failed",
+		Title:   "Comment đầu tiên thất bại",
+		Message: "Bài đã đăng lên " + pageName + " nhưng không thể đăng comment đầu tiên: " + reason,
+		PageID:  &pageID,
+	}
+	return s.CreateNotification(n)
+}
+
+// NotifyInstagramPostFailed tạo thông báo đăng Instagram thất bại
+func (s *Store) NotifyInstagramPostFailed(pageID string, pageName string, reason string) error {
+	n := &Notification{
+		Type:    "instagram_post_failed",
+		Title:   "Đăng Instagram thất bại",
+		Message: "Không thể đăng bài lên Instagram của " + pageName + ": " + reason,
+		PageID:  &pageID,
+	}
+	return s.CreateNotification(n)
+}
+
+// NotifyNoPostingPermission tạo thông báo bài không đăng được vì page không có quyền đăng bài
+func (s *Store) NotifyNoPostingPermission(pageID string, pageName string, reason string) error {
+	n := &Notification{
+		Type:    "no_posting_permission",
+		Title:   "Không có quyền đăng bài",
+		Message: "Bài lên lịch cho " + pageName + " không được đăng: " + reason + ". Vui lòng cấp lại quyền đăng bài cho page.",
+		PageID:  &pageID,
+	}
+	return s.CreateNotification(n)
+}
+
 // NotifyWarningThreshold tạo thông báo đạt 80% giới hạn
 func (s *Store) NotifyWarningThreshold(accountID string, accountName string, current, max int) error {
 	n := &Notification{
@@ -177,3 +234,58 @@ func (s *Store) NotifyWarningThreshold(accountID string, accountName string, cur
 	}
 	return s.CreateNotification(n)
 }
+
+// NotifyPostRemoved tạo thông báo bài đã đăng bị Facebook gỡ / ẩn
+func (s *Store) NotifyPostRemoved(pageID string, pageName string, facebookPostID string) error {
+	n := &Notification{
+		Type:    "post_removed",
+		Title:   "Bài đăng bị gỡ",
+		Message: "Bài " + facebookPostID + " trên page " + pageName + " đã bị gỡ hoặc ẩn trên Facebook.",
+		PageID:  &pageID,
+	}
+	return s.CreateNotification(n)
+}
+
+// NotifyNewComment tạo thông báo có comment mới trên bài đăng từ app
+func (s *Store) NotifyNewComment(pageID string, pageName string, fromName string, message string) error {
+	n := &Notification{
+		Type:    "new_comment",
+		Title:   "Comment mới",
+		Message: fromName + " đã bình luận bài trên page " + pageName + ": " + message,
+		PageID:  &pageID,
+	}
+	return s.CreateNotification(n)
+}
+
+// NotifyNewReaction tạo thông báo có reaction mới trên bài đăng từ app
+func (s *Store) NotifyNewReaction(pageID string, pageName string, fromName string, reaction string) error {
+	n := &Notification{
+		Type:    "new_reaction",
+		Title:   "Reaction mới",
+		Message: fromName + " đã thả " + reaction + " bài trên page " + pageName + ".",
+		PageID:  &pageID,
+	}
+	return s.CreateNotification(n)
+}
+
+// NotifyPageAccessLost tạo thông báo app mất quyền trên page
+func (s *Store) NotifyPageAccessLost(pageID string, pageName string, permission string, blockedPosts int) error {
+	n := &Notification{
+		Type:    "page_access_lost",
+		Title:   "Mất quyền truy cập page",
+		Message: "App đã bị thu hồi quyền " + permission + " trên page " + pageName + ". " + strconv.Itoa(blockedPosts) + " bài đã lên lịch bị tạm dừng cho đến khi cấp lại quyền.",
+		PageID:  &pageID,
+	}
+	return s.CreateNotification(n)
+}
+
+// NotifyAccountPermissionRevoked tạo thông báo nick thu hồi quyền của app
+func (s *Store) NotifyAccountPermissionRevoked(accountID string, accountName string, permission string) error {
+	n := &Notification{
+		Type:      "permission_revoked",
+		Title:     "Quyền bị thu hồi",
+		Message:   "Nick " + accountName + " đã thu hồi quyền " + permission + " của app. Vui lòng đăng nhập lại để cấp quyền.",
+		AccountID: &accountID,
+	}
+	return s.CreateNotification(n)
+}
@@ -2,6 +2,9 @@ package db
 
 import (
 	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 func (s *Store) CreateOrUpdatePage(page *Page) error {
@@ -30,8 +33,29 @@ func (s *Store) CreateOrUpdatePage(page *Page) error {
 	).Scan(&page.ID, &page.PageID, &page.PageName, &page.Category, &page.ProfilePictureURL, &page.IsActive, &page.CreatedAt, &page.UpdatedAt)
 }
 
+// SavePageTokenInfo lưu kết quả debug_token của page token (expiresAt nil = không hết hạn)
+func (s *Store) SavePageTokenInfo(id string, expiresAt *time.Time, scopes []string, isValid bool) error {
+	query := `
+		UPDATE pages SET
+			token_expires_at = $2,
+			token_scopes = $3,
+			token_is_valid = $4,
+			token_checked_at = NOW()
+		WHERE id = $1
+	`
+	_, err := s.db.Exec(query, id, expiresAt, pq.Array(scopes), isValid)
+	return err
+}
+
+// SavePageTasks lưu quyền của user trên page (tasks trong me/accounts) theo FB page id
+func (s *Store) SavePageTasks(pageID string, tasks []string) error {
+	_, err := s.db.Exec("UPDATE pages SET tasks = $2 WHERE page_id = $1", pageID, pq.Array(tasks))
+	return err
+}
+
 func (s *Store) GetPages() ([]Page, error) {
-	query := `SELECT id, page_id, page_name, category, profile_picture_url, is_active, created_at, updated_at 
+	query := `SELECT id, page_id, page_name, category, profile_picture_url, is_active, created_at, updated_at,
+	                 COALESCE(native_scheduling, false)
 	          FROM pages ORDER BY created_at DESC`
 	
 	rows, err := s.db.Query(query)
@@ -43,7 +67,8 @@ func (s *Store) GetPages() ([]Page, error) {
 	pages := make([]Page, 0)
 	for rows.Next() {
 		var p Page
-		err := rows.Scan(&p.ID, &p.PageID, &p.PageName, &p.Category, &p.ProfilePictureURL, &p.IsActive, &p.CreatedAt, &p.UpdatedAt)
+		err := rows.Scan(&p.ID, &p.PageID, &p.PageName, &p.Category, &p.ProfilePictureURL, &p.IsActive, &p.CreatedAt, &p.UpdatedAt,
+			&p.NativeScheduling)
 		if err != nil {
 			return nil, err
 		}
@@ -54,13 +79,19 @@ func (s *Store) GetPages() ([]Page, error) {
 }
 
 func (s *Store) GetPageByID(id string) (*Page, error) {
-	query := `SELECT id, page_id, page_name, access_token, token_expires_at, category, profile_picture_url, is_active, created_at, updated_at 
+	query := `SELECT id, page_id, page_name, access_token, token_expires_at, category, profile_picture_url, is_active, created_at, updated_at,
+	                 COALESCE(native_scheduling, false), COALESCE(token_scopes, '{}'), token_checked_at,
+	                 COALESCE(instagram_account_id, ''), COALESCE(instagram_username, ''), COALESCE(tasks, '{}'),
+	                 COALESCE(token_is_valid, true)
 	          FROM pages WHERE id = $1`
 	
 	var p Page
 	err := s.db.QueryRow(query, id).Scan(
 		&p.ID, &p.PageID, &p.PageName, &p.AccessToken, &p.TokenExpiresAt, 
 		&p.Category, &p.ProfilePictureURL, &p.IsActive, &p.CreatedAt, &p.UpdatedAt,
+		&p.NativeScheduling, pq.Array(&p.TokenScopes), &p.TokenCheckedAt,
+		&p.InstagramAccountID, &p.InstagramUsername, pq.Array(&p.Tasks),
+		&p.TokenIsValid,
 	)
 	
 	if err == sql.ErrNoRows {
@@ -69,6 +100,19 @@ func (s *Store) GetPageByID(id string) (*Page, error) {
 	return &p, err
 }
 
+// GetPageByPageID lấy page theo FB page id
+func (s *Store) GetPageByPageID(pageID string) (*Page, error) {
+	var id string
+	err := s.db.QueryRow(`SELECT id FROM pages WHERE page_id = $1`, pageID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPageByID(id)
+}
+
 func (s *Store) DeletePage(id string) error {
 	// Lấy account_id của page trước khi xóa
 	var accountID sql.NullString
@@ -103,8 +147,26 @@ func (s *Store) TogglePage(id string) error {
 	return err
 }
 
+// SetPageNativeScheduling bật/tắt hẹn giờ trên Facebook làm mặc định cho page
+func (s *Store) SetPageNativeScheduling(id string, enabled bool) error {
+	_, err := s.db.Exec("UPDATE pages SET native_scheduling = $1 WHERE id = $2", enabled, id)
+	return err
+}
+
+// SetPageInstagramAccount lưu tài khoản Instagram Business liên kết với page (rỗng = bỏ liên kết)
+func (s *Store) SetPageInstagramAccount(id, igAccountID, igUsername string) error {
+	_, err := s.db.Exec(`
+		UPDATE pages SET instagram_account_id = NULLIF($2, ''), instagram_username = NULLIF($3, '')
+		WHERE id = $1
+	`, id, igAccountID, igUsername)
+	return err
+}
+
 func (s *Store) GetActivePages() ([]Page, error) {
-	query := `SELECT id, page_id, page_name, access_token, category, profile_picture_url, created_at, updated_at 
+	query := `SELECT id, page_id, page_name, access_token, category, profile_picture_url, created_at, updated_at,
+	                 COALESCE(token_is_valid, true),
+	                 COALESCE(instagram_account_id, ''), COALESCE(instagram_username, ''),
+	                 COALESCE(tasks, '{}'), COALESCE(token_scopes, '{}')
 	          FROM pages WHERE is_active = true`
 	
 	rows, err := s.db.Query(query)
@@ -116,7 +178,9 @@ func (s *Store) GetActivePages() ([]Page, error) {
 	pages := make([]Page, 0)
 	for rows.Next() {
 		var p Page
-		err := rows.Scan(&p.ID, &p.PageID, &p.PageName, &p.AccessToken, &p.Category, &p.ProfilePictureURL, &p.CreatedAt, &p.UpdatedAt)
+		err := rows.Scan(&p.ID, &p.PageID, &p.PageName, &p.AccessToken, &p.Category, &p.ProfilePictureURL, &p.CreatedAt, &p.UpdatedAt,
+			&p.TokenIsValid, &p.InstagramAccountID, &p.InstagramUsername,
+			pq.Array(&p.Tasks), pq.Array(&p.TokenScopes))
 		if err != nil {
 			return nil, err
 		}
@@ -157,6 +221,11 @@ func (s *Store) GetPagesWithAccount() ([]PageWithAccount, error) {
 		SELECT 
 			p.id, p.page_id, p.page_name, p.category, 
 			p.profile_picture_url, p.is_active, p.created_at, p.updated_at,
+			COALESCE(p.native_scheduling, false),
+			p.token_expires_at, COALESCE(p.token_scopes, '{}'), p.token_checked_at,
+			COALESCE(p.token_is_valid, true),
+			COALESCE(p.instagram_account_id, ''), COALESCE(p.instagram_username, ''),
+			COALESCE(p.tasks, '{}'),
 			fa.id, fa.fb_user_name, fa.profile_picture_url
 		FROM pages p
 		LEFT JOIN page_account_assignments paa ON paa.page_id = p.id AND paa.is_primary = true
@@ -178,6 +247,11 @@ func (s *Store) GetPagesWithAccount() ([]PageWithAccount, error) {
 		err := rows.Scan(
 			&p.ID, &p.PageID, &p.PageName, &p.Category,
 			&p.ProfilePictureURL, &p.IsActive, &p.CreatedAt, &p.UpdatedAt,
+			&p.NativeScheduling,
+			&p.TokenExpiresAt, pq.Array(&p.TokenScopes), &p.TokenCheckedAt,
+			&p.TokenIsValid,
+			&p.InstagramAccountID, &p.InstagramUsername,
+			pq.Array(&p.Tasks),
 			&accountID, &accountName, &accountPicture,
 		)
 		if err != nil {
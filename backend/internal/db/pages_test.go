@@ -0,0 +1,29 @@
+package db_test
+
+import (
+	"testing"
+
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/db/dbtest"
+)
+
+func TestGetPageByIDTokenIsValid(t *testing.T) {
+	store := dbtest.NewStore(t)
+	page := &db.Page{PageID: "123", PageName: "Test Page", AccessToken: "page-token"}
+	if err := store.CreateOrUpdatePage(page); err != nil {
+		t.Fatalf("CreateOrUpdatePage() error = %v", err)
+	}
+
+	for _, valid := range []bool{true, false} {
+		if err := store.SavePageTokenInfo(page.ID, nil, nil, valid); err != nil {
+			t.Fatalf("SavePageTokenInfo() error = %v", err)
+		}
+		got, err := store.GetPageByID(page.ID)
+		if err != nil || got == nil {
+			t.Fatalf("GetPageByID() = %v, %v", got, err)
+		}
+		if got.TokenIsValid != valid {
+			t.Errorf("TokenIsValid = %v, want %v", got.TokenIsValid, valid)
+		}
+	}
+}
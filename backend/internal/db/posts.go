@@ -8,8 +8,10 @@ import (
 
 func (s *Store) CreatePost(post *Post) error {
 	query := `
-		INSERT INTO posts (content, media_urls, media_type, link_url, status)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO posts (content, media_urls, media_type, link_url, status, link_name, link_description, link_picture,
+		                   first_comment, first_comment_image, place_id, tags, platforms,
+		                   media_captions, burn_captions, album_name, targeting, feed_targeting, unpublished)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), $12, $13, $14, $15, NULLIF($16, ''), $17, $18, $19)
 		RETURNING id, created_at, updated_at
 	`
 	
@@ -20,11 +22,30 @@ func (s *Store) CreatePost(post *Post) error {
 		post.MediaType,
 		post.LinkURL,
 		post.Status,
+		post.LinkName,
+		post.LinkDescription,
+		post.LinkPicture,
+		post.FirstComment,
+		post.FirstCommentImage,
+		post.PlaceID,
+		pq.Array(post.Tags),
+		pq.Array(postPlatforms(post)),
+		pq.Array(post.MediaCaptions),
+		post.BurnCaptions,
+		post.AlbumName,
+		post.Targeting,
+		post.FeedTargeting,
+		post.Unpublished,
 	).Scan(&post.ID, &post.CreatedAt, &post.UpdatedAt)
 }
 
 func (s *Store) GetPosts(limit, offset int) ([]Post, error) {
-	query := `SELECT id, content, media_urls, media_type, link_url, status, created_at, updated_at 
+	query := `SELECT id, content, media_urls, media_type, COALESCE(link_url, ''), status, created_at, updated_at,
+	                 COALESCE(link_name, ''), COALESCE(link_description, ''), COALESCE(link_picture, ''),
+	                 COALESCE(first_comment, ''), COALESCE(first_comment_image, ''),
+	                 COALESCE(place_id, ''), COALESCE(tags, '{}'), COALESCE(platforms, '{facebook}'),
+	                 COALESCE(media_captions, '{}'), COALESCE(burn_captions, false), COALESCE(album_name, ''),
+	                 targeting, feed_targeting, COALESCE(unpublished, false)
 	          FROM posts ORDER BY created_at DESC LIMIT $1 OFFSET $2`
 	
 	rows, err := s.db.Query(query, limit, offset)
@@ -36,7 +57,12 @@ func (s *Store) GetPosts(limit, offset int) ([]Post, error) {
 	posts := make([]Post, 0)
 	for rows.Next() {
 		var p Post
-		err := rows.Scan(&p.ID, &p.Content, pq.Array(&p.MediaURLs), &p.MediaType, &p.LinkURL, &p.Status, &p.CreatedAt, &p.UpdatedAt)
+		err := rows.Scan(&p.ID, &p.Content, pq.Array(&p.MediaURLs), &p.MediaType, &p.LinkURL, &p.Status, &p.CreatedAt, &p.UpdatedAt,
+			&p.LinkName, &p.LinkDescription, &p.LinkPicture,
+			&p.FirstComment, &p.FirstCommentImage,
+			&p.PlaceID, pq.Array(&p.Tags), pq.Array(&p.Platforms),
+			pq.Array(&p.MediaCaptions), &p.BurnCaptions, &p.AlbumName,
+			scanAudience(&p.Targeting), scanAudience(&p.FeedTargeting), &p.Unpublished)
 		if err != nil {
 			return nil, err
 		}
@@ -47,12 +73,22 @@ func (s *Store) GetPosts(limit, offset int) ([]Post, error) {
 }
 
 func (s *Store) GetPostByID(id string) (*Post, error) {
-	query := `SELECT id, content, media_urls, media_type, link_url, status, created_at, updated_at 
+	query := `SELECT id, content, media_urls, media_type, COALESCE(link_url, ''), status, created_at, updated_at,
+	                 COALESCE(link_name, ''), COALESCE(link_description, ''), COALESCE(link_picture, ''),
+	                 COALESCE(first_comment, ''), COALESCE(first_comment_image, ''),
+	                 COALESCE(place_id, ''), COALESCE(tags, '{}'), COALESCE(platforms, '{facebook}'),
+	                 COALESCE(media_captions, '{}'), COALESCE(burn_captions, false), COALESCE(album_name, ''),
+	                 targeting, feed_targeting, COALESCE(unpublished, false)
 	          FROM posts WHERE id = $1`
 	
 	var p Post
 	err := s.db.QueryRow(query, id).Scan(
 		&p.ID, &p.Content, pq.Array(&p.MediaURLs), &p.MediaType, &p.LinkURL, &p.Status, &p.CreatedAt, &p.UpdatedAt,
+		&p.LinkName, &p.LinkDescription, &p.LinkPicture,
+		&p.FirstComment, &p.FirstCommentImage,
+		&p.PlaceID, pq.Array(&p.Tags), pq.Array(&p.Platforms),
+		pq.Array(&p.MediaCaptions), &p.BurnCaptions, &p.AlbumName,
+		scanAudience(&p.Targeting), scanAudience(&p.FeedTargeting), &p.Unpublished,
 	)
 	
 	if err == sql.ErrNoRows {
@@ -64,11 +100,35 @@ func (s *Store) GetPostByID(id string) (*Post, error) {
 func (s *Store) UpdatePost(post *Post) error {
 	query := `
 		UPDATE posts 
-		SET content = $1, media_urls = $2, media_type = $3, link_url = $4, status = $5
-		WHERE id = $6
+		SET content = $1, media_urls = $2, media_type = $3, link_url = $4, status = $5,
+		    link_name = $6, link_description = $7, link_picture = $8,
+		    first_comment = $9, first_comment_image = $10,
+		    place_id = NULLIF($12, ''), tags = $13, platforms = $14,
+		    media_captions = $15, burn_captions = $16, album_name = NULLIF($17, ''),
+		    targeting = $18, feed_targeting = $19, unpublished = $20
+		WHERE id = $11
 	`
 	
-	_, err := s.db.Exec(query, post.Content, pq.Array(post.MediaURLs), post.MediaType, post.LinkURL, post.Status, post.ID)
+	_, err := s.db.Exec(query, post.Content, pq.Array(post.MediaURLs), post.MediaType, post.LinkURL, post.Status,
+		post.LinkName, post.LinkDescription, post.LinkPicture,
+		post.FirstComment, post.FirstCommentImage, post.ID,
+		post.PlaceID, pq.Array(post.Tags), pq.Array(postPlatforms(post)),
+		pq.Array(post.MediaCaptions), post.BurnCaptions, post.AlbumName,
+		post.Targeting, post.FeedTargeting, post.Unpublished)
+	return err
+}
+
+// postPlatforms trả về nền tảng đăng của bài, mặc định chỉ facebook
+func postPlatforms(post *Post) []string {
+	if len(post.Platforms) == 0 {
+		return []string{PlatformFacebook}
+	}
+	return post.Platforms
+}
+
+// UpdatePostContent cập nhật nội dung bài (sau khi đã sửa trên Facebook)
+func (s *Store) UpdatePostContent(id, content string) error {
+	_, err := s.db.Exec("UPDATE posts SET content = $1 WHERE id = $2", content, id)
 	return err
 }
 
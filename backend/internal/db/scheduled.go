@@ -1,16 +1,22 @@
 package db
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/lib/pq"
 )
 
 func (s *Store) CreateScheduledPost(sp *ScheduledPost) error {
+	// SchedulingMode rỗng → lấy mặc định của page (pages.native_scheduling)
 	query := `
-		INSERT INTO scheduled_posts (post_id, page_id, account_id, scheduled_time, status, max_retries, time_slot_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, created_at, updated_at, retry_count
+		INSERT INTO scheduled_posts (post_id, page_id, account_id, scheduled_time, status, max_retries, time_slot_id, scheduling_mode)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE(
+			NULLIF($8, ''),
+			(SELECT CASE WHEN native_scheduling THEN 'facebook' ELSE 'local' END FROM pages WHERE id = $2),
+			'local'
+		))
+		RETURNING id, created_at, updated_at, retry_count, scheduling_mode
 	`
 	
 	return s.db.QueryRow(
@@ -22,7 +28,8 @@ func (s *Store) CreateScheduledPost(sp *ScheduledPost) error {
 		sp.Status,
 		sp.MaxRetries,
 		sp.TimeSlotID,
-	).Scan(&sp.ID, &sp.CreatedAt, &sp.UpdatedAt, &sp.RetryCount)
+		sp.SchedulingMode,
+	).Scan(&sp.ID, &sp.CreatedAt, &sp.UpdatedAt, &sp.RetryCount, &sp.SchedulingMode)
 }
 
 func (s *Store) GetScheduledPosts(status string, limit, offset int) ([]ScheduledPost, error) {
@@ -30,6 +37,8 @@ func (s *Store) GetScheduledPosts(status string, limit, offset int) ([]Scheduled
 		SELECT 
 			sp.id, sp.post_id, sp.page_id, sp.account_id, sp.scheduled_time, sp.status, 
 			sp.retry_count, sp.max_retries, sp.created_at, sp.updated_at,
+			COALESCE(sp.scheduling_mode, 'local'), sp.fb_object_id,
+			COALESCE(pl.facebook_post_id, ''), COALESCE(pl.permalink_url, ''),
 			p.content, p.media_urls, p.media_type, p.link_url,
 			pg.id, pg.page_name, pg.profile_picture_url,
 			fa.id, fa.fb_user_name, fa.profile_picture_url
@@ -37,6 +46,13 @@ func (s *Store) GetScheduledPosts(status string, limit, offset int) ([]Scheduled
 		JOIN posts p ON sp.post_id = p.id
 		JOIN pages pg ON sp.page_id = pg.id
 		LEFT JOIN facebook_accounts fa ON sp.account_id = fa.id
+		LEFT JOIN LATERAL (
+			SELECT facebook_post_id, permalink_url FROM post_logs
+			WHERE scheduled_post_id = sp.id AND status IN ('success', 'partial')
+			  AND COALESCE(action, 'publish') = 'publish' AND COALESCE(platform, 'facebook') = 'facebook'
+			ORDER BY posted_at DESC
+			LIMIT 1
+		) pl ON true
 		WHERE ($1 = '' OR sp.status = $1)
 		ORDER BY sp.scheduled_time DESC
 		LIMIT $2 OFFSET $3
@@ -60,6 +76,8 @@ func (s *Store) GetScheduledPosts(status string, limit, offset int) ([]Scheduled
 		err := rows.Scan(
 			&sp.ID, &sp.PostID, &sp.PageID, &sp.AccountID, &sp.ScheduledTime, &sp.Status,
 			&sp.RetryCount, &sp.MaxRetries, &sp.CreatedAt, &sp.UpdatedAt,
+			&sp.SchedulingMode, &sp.FbObjectID,
+			&sp.FacebookPostID, &sp.PermalinkURL,
 			&sp.Post.Content, pq.Array(&sp.Post.MediaURLs), &sp.Post.MediaType, &linkURL,
 			&sp.Page.ID, &sp.Page.PageName, &sp.Page.ProfilePictureURL,
 			&accountID, &accountName, &accountPicture,
@@ -95,22 +113,67 @@ func (s *Store) GetPendingScheduledPosts() ([]ScheduledPost, error) {
 	// Không phụ thuộc vào timezone của PostgreSQL server
 	nowUTC := time.Now().UTC()
 
+	// Page đang tạm dừng vì sắp bị throttle thì chờ tới lượt sau
+	return s.queryPublishablePosts(`sp.status = 'pending' AND sp.scheduled_time <= $1
+		  AND (pg.rate_limit_until IS NULL OR pg.rate_limit_until <= $1)`, nowUTC)
+}
+
+// GetNativeHandoffPosts lấy bài mode "facebook" chưa giao cho Facebook và còn đủ thời gian hẹn giờ (minLead).
+// Story, bài đăng Instagram, bài có caption từng ảnh (album / in caption lên ảnh) và bài ẩn
+// không hẹn giờ được trên Facebook nên luôn do scheduler tự đăng.
+func (s *Store) GetNativeHandoffPosts(minLead time.Duration) ([]ScheduledPost, error) {
+	earliest := time.Now().UTC().Add(minLead)
+
+	return s.queryPublishablePosts(`sp.status = 'pending' AND sp.scheduling_mode = 'facebook' AND sp.scheduled_time > $1
+		  AND p.media_type <> 'story'
+		  AND NOT ('instagram' = ANY(COALESCE(p.platforms, '{facebook}')))
+		  AND NOT COALESCE(p.burn_captions, false)
+		  AND NOT EXISTS (SELECT 1 FROM unnest(p.media_captions) AS c WHERE c <> '')
+		  AND NOT COALESCE(p.unpublished, false)
+		  AND (sp.handoff_retry_at IS NULL OR sp.handoff_retry_at <= NOW())`, earliest)
+}
+
+// GetDueNativeScheduledPosts lấy bài đã hẹn giờ trên Facebook và đã tới giờ đăng (cần đối soát)
+func (s *Store) GetDueNativeScheduledPosts() ([]ScheduledPost, error) {
+	nowUTC := time.Now().UTC()
+
+	return s.queryPublishablePosts(`sp.status = 'fb_scheduled' AND sp.scheduled_time <= $1`, nowUTC)
+}
+
+// GetScheduledPostByID lấy 1 scheduled post kèm nội dung bài và page (có access token)
+func (s *Store) GetScheduledPostByID(id string) (*ScheduledPost, error) {
+	posts, err := s.queryPublishablePosts(`sp.id = $1`, id)
+	if err != nil || len(posts) == 0 {
+		return nil, err
+	}
+	return &posts[0], nil
+}
+
+// queryPublishablePosts lấy scheduled posts kèm đủ dữ liệu để đăng (nội dung bài + page token).
+// Chỉ lấy page đang active.
+func (s *Store) queryPublishablePosts(where string, args ...interface{}) ([]ScheduledPost, error) {
 	query := `
 		SELECT 
-			sp.id, sp.post_id, sp.page_id, sp.scheduled_time, sp.status, 
+			sp.id, sp.post_id, sp.page_id, sp.account_id, sp.scheduled_time, sp.status, 
 			sp.retry_count, sp.max_retries,
+			COALESCE(sp.scheduling_mode, 'local'), sp.fb_object_id, COALESCE(sp.handoff_attempts, 0),
 			p.content, p.media_urls, p.media_type, p.link_url,
-			pg.page_id, pg.access_token
+			COALESCE(p.link_name, ''), COALESCE(p.link_description, ''), COALESCE(p.link_picture, ''),
+			COALESCE(p.first_comment, ''), COALESCE(p.first_comment_image, ''),
+			COALESCE(p.place_id, ''), COALESCE(p.tags, '{}'), COALESCE(p.platforms, '{facebook}'),
+			COALESCE(p.media_captions, '{}'), COALESCE(p.burn_captions, false), COALESCE(p.album_name, ''),
+			p.targeting, p.feed_targeting, COALESCE(p.unpublished, false),
+			pg.page_id, pg.page_name, pg.access_token, COALESCE(pg.instagram_account_id, ''),
+			COALESCE(pg.tasks, '{}'), COALESCE(pg.token_scopes, '{}'), COALESCE(pg.token_is_valid, true)
 		FROM scheduled_posts sp
 		JOIN posts p ON sp.post_id = p.id
 		JOIN pages pg ON sp.page_id = pg.id
-		WHERE sp.status = 'pending' 
-		  AND sp.scheduled_time <= $1
+		WHERE ` + where + `
 		  AND pg.is_active = true
 		ORDER BY sp.scheduled_time ASC
 	`
 
-	rows, err := s.db.Query(query, nowUTC)
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -125,10 +188,17 @@ func (s *Store) GetPendingScheduledPosts() ([]ScheduledPost, error) {
 		var linkURL *string
 		
 		err := rows.Scan(
-			&sp.ID, &sp.PostID, &sp.PageID, &sp.ScheduledTime, &sp.Status,
+			&sp.ID, &sp.PostID, &sp.PageID, &sp.AccountID, &sp.ScheduledTime, &sp.Status,
 			&sp.RetryCount, &sp.MaxRetries,
+			&sp.SchedulingMode, &sp.FbObjectID, &sp.HandOffAttempts,
 			&sp.Post.Content, pq.Array(&sp.Post.MediaURLs), &sp.Post.MediaType, &linkURL,
-			&sp.Page.PageID, &sp.Page.AccessToken,
+			&sp.Post.LinkName, &sp.Post.LinkDescription, &sp.Post.LinkPicture,
+			&sp.Post.FirstComment, &sp.Post.FirstCommentImage,
+			&sp.Post.PlaceID, pq.Array(&sp.Post.Tags), pq.Array(&sp.Post.Platforms),
+			pq.Array(&sp.Post.MediaCaptions), &sp.Post.BurnCaptions, &sp.Post.AlbumName,
+			scanAudience(&sp.Post.Targeting), scanAudience(&sp.Post.FeedTargeting), &sp.Post.Unpublished,
+			&sp.Page.PageID, &sp.Page.PageName, &sp.Page.AccessToken, &sp.Page.InstagramAccountID,
+			pq.Array(&sp.Page.Tasks), pq.Array(&sp.Page.TokenScopes), &sp.Page.TokenIsValid,
 		)
 		if err != nil {
 			return nil, err
@@ -165,3 +235,136 @@ func (s *Store) UpdateScheduledPostAccount(id, accountID string) error {
 	_, err := s.db.Exec("UPDATE scheduled_posts SET account_id = $1 WHERE id = $2", accountID, id)
 	return err
 }
+
+// BlockPendingPostsForPage chuyển bài pending của page sang 'blocked_token' (token page hỏng),
+// tránh tốn lượt retry. Trả về số bài bị chặn.
+func (s *Store) BlockPendingPostsForPage(pageID string) (int64, error) {
+	result, err := s.db.Exec(
+		"UPDATE scheduled_posts SET status = 'blocked_token' WHERE page_id = $1 AND status = 'pending'",
+		pageID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// UnblockPostsForPage đưa bài 'blocked_token' của page về pending khi token dùng lại được
+func (s *Store) UnblockPostsForPage(pageID string) (int64, error) {
+	result, err := s.db.Exec(
+		"UPDATE scheduled_posts SET status = 'pending' WHERE page_id = $1 AND status = 'blocked_token'",
+		pageID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// PublishAttempt là khóa idempotency của scheduled post và thời điểm lần đăng đầu tiên bắt đầu
+type PublishAttempt struct {
+	Key       string
+	StartedAt time.Time
+	// Resumed: đã có lần đăng trước (lỗi mơ hồ, crash giữa chừng) → có thể bài đã lên Facebook
+	Resumed bool
+}
+
+// StartPublishAttempt lưu khóa idempotency trước khi gọi Graph API.
+// Khóa và thời điểm của lần đăng trước được đọc trước khi ghi và giữ nguyên: lần đăng lại
+// đối chiếu feed từ lần đăng đầu tiên và claim bài theo khóa cũ. Chưa có thì lưu key và NOW().
+func (s *Store) StartPublishAttempt(id, key string) (*PublishAttempt, error) {
+	attempt := &PublishAttempt{}
+	err := s.db.QueryRow(`
+		WITH previous AS (
+			SELECT id, publish_key, publish_started_at FROM scheduled_posts WHERE id = $1 FOR UPDATE
+		)
+		UPDATE scheduled_posts sp
+		SET publish_key = COALESCE(previous.publish_key, $2),
+		    publish_started_at = COALESCE(previous.publish_started_at, NOW())
+		FROM previous
+		WHERE sp.id = previous.id
+		RETURNING sp.publish_key, sp.publish_started_at, previous.publish_key IS NOT NULL
+	`, id, key).Scan(&attempt.Key, &attempt.StartedAt, &attempt.Resumed)
+	if err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+// GetPublishAttempt đọc khóa idempotency đã lưu của scheduled post (nil nếu chưa đăng lần nào)
+func (s *Store) GetPublishAttempt(id string) (*PublishAttempt, error) {
+	var key sql.NullString
+	var startedAt sql.NullTime
+	err := s.db.QueryRow(
+		"SELECT publish_key, publish_started_at FROM scheduled_posts WHERE id = $1", id,
+	).Scan(&key, &startedAt)
+	if err == sql.ErrNoRows || (err == nil && (!key.Valid || !startedAt.Valid)) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &PublishAttempt{Key: key.String, StartedAt: startedAt.Time, Resumed: true}, nil
+}
+
+// GetProcessingScheduledPosts lấy bài còn "processing" (server dừng giữa lúc đăng) để đối soát khi khởi động
+func (s *Store) GetProcessingScheduledPosts() ([]ScheduledPost, error) {
+	return s.queryPublishablePosts(`sp.status = 'processing'`)
+}
+
+// IsFacebookPostLogged kiểm tra bài Facebook đã được ghi nhận đăng thành công (post_logs) chưa
+func (s *Store) IsFacebookPostLogged(facebookPostID string) (bool, error) {
+	var exists bool
+	err := s.db.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM post_logs WHERE facebook_post_id = $1 AND status IN ('success', 'partial'))",
+		facebookPostID,
+	).Scan(&exists)
+	return exists, err
+}
+
+// ClaimFacebookPost ghi nhận bài Facebook thuộc lần đăng có khóa idempotency key.
+// Trả về true nếu claim được (bài chưa thuộc lần đăng nào hoặc đã là của key này);
+// insert-or-fail trong 1 câu lệnh nên 2 lần đăng không claim được cùng 1 bài.
+func (s *Store) ClaimFacebookPost(facebookPostID, pageID, key string) (bool, error) {
+	var owner string
+	err := s.db.QueryRow(`
+		INSERT INTO facebook_post_claims (facebook_post_id, page_id, publish_key)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (facebook_post_id) DO UPDATE SET facebook_post_id = EXCLUDED.facebook_post_id
+		RETURNING publish_key
+	`, facebookPostID, pageID, key).Scan(&owner)
+	if err != nil {
+		return false, err
+	}
+	return owner == key, nil
+}
+
+// MarkScheduledPostHandedOff đánh dấu bài đã hẹn giờ trên Facebook
+func (s *Store) MarkScheduledPostHandedOff(id, fbObjectID string) error {
+	_, err := s.db.Exec(
+		"UPDATE scheduled_posts SET status = 'fb_scheduled', scheduling_mode = 'facebook', fb_object_id = $1 WHERE id = $2",
+		fbObjectID, id,
+	)
+	return err
+}
+
+// DeferHandOff ghi nhận 1 lần giao bài cho Facebook lỗi tạm thời, lượt quét trước retryAt bỏ qua bài này
+func (s *Store) DeferHandOff(id string, retryAt time.Time) error {
+	_, err := s.db.Exec(
+		"UPDATE scheduled_posts SET handoff_attempts = handoff_attempts + 1, handoff_retry_at = $1 WHERE id = $2",
+		retryAt.UTC(), id,
+	)
+	return err
+}
+
+// UpdateSchedulingMode đổi mode hẹn giờ ("local" | "facebook")
+func (s *Store) UpdateSchedulingMode(id, mode string) error {
+	_, err := s.db.Exec("UPDATE scheduled_posts SET scheduling_mode = $1 WHERE id = $2", mode, id)
+	return err
+}
+
+// RescheduleScheduledPost đổi thời gian đăng
+func (s *Store) RescheduleScheduledPost(id string, scheduledTime time.Time) error {
+	_, err := s.db.Exec("UPDATE scheduled_posts SET scheduled_time = $1 WHERE id = $2", scheduledTime.UTC(), id)
+	return err
+}
@@ -0,0 +1,79 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/db/dbtest"
+)
+
+func TestClaimFacebookPost(t *testing.T) {
+	store := dbtest.NewStore(t)
+	page := &db.Page{PageID: "123", PageName: "Test Page", AccessToken: "page-token"}
+	if err := store.CreateOrUpdatePage(page); err != nil {
+		t.Fatalf("CreateOrUpdatePage() error = %v", err)
+	}
+
+	steps := []struct {
+		name   string
+		postID string
+		key    string
+		want   bool
+	}{
+		{"first claim", "123_1", "attempt-a", true},
+		{"same attempt claims again", "123_1", "attempt-a", true},
+		{"other attempt is refused", "123_1", "attempt-b", false},
+		{"other attempt claims another post", "123_2", "attempt-b", true},
+	}
+	for _, step := range steps {
+		claimed, err := store.ClaimFacebookPost(step.postID, page.ID, step.key)
+		if err != nil {
+			t.Fatalf("%s: ClaimFacebookPost() error = %v", step.name, err)
+		}
+		if claimed != step.want {
+			t.Errorf("%s: ClaimFacebookPost(%s, %s) = %v, want %v", step.name, step.postID, step.key, claimed, step.want)
+		}
+	}
+}
+
+func TestStartPublishAttempt(t *testing.T) {
+	store := dbtest.NewStore(t)
+	page := &db.Page{PageID: "123", PageName: "Test Page", AccessToken: "page-token"}
+	if err := store.CreateOrUpdatePage(page); err != nil {
+		t.Fatalf("CreateOrUpdatePage() error = %v", err)
+	}
+	post := &db.Post{Content: "hello", Status: "scheduled"}
+	if err := store.CreatePost(post); err != nil {
+		t.Fatalf("CreatePost() error = %v", err)
+	}
+	sp := &db.ScheduledPost{PostID: post.ID, PageID: page.ID, ScheduledTime: time.Now(), Status: "pending", MaxRetries: 3}
+	if err := store.CreateScheduledPost(sp); err != nil {
+		t.Fatalf("CreateScheduledPost() error = %v", err)
+	}
+
+	first, err := store.StartPublishAttempt(sp.ID, "attempt-a")
+	if err != nil {
+		t.Fatalf("StartPublishAttempt() error = %v", err)
+	}
+	if first.Key != "attempt-a" || first.Resumed {
+		t.Errorf("first attempt = %+v, want a new attempt with key attempt-a", first)
+	}
+	if time.Since(first.StartedAt) > time.Minute || time.Until(first.StartedAt) > time.Minute {
+		t.Errorf("StartedAt = %v, want the current database time", first.StartedAt)
+	}
+
+	// Lần retry giữ khóa và thời điểm của lần đăng đầu tiên
+	retry, err := store.StartPublishAttempt(sp.ID, "attempt-b")
+	if err != nil {
+		t.Fatalf("StartPublishAttempt() error = %v", err)
+	}
+	if retry.Key != "attempt-a" || !retry.StartedAt.Equal(first.StartedAt) || !retry.Resumed {
+		t.Errorf("retry = %+v, want the first attempt %+v resumed", retry, first)
+	}
+
+	saved, err := store.GetPublishAttempt(sp.ID)
+	if err != nil || saved == nil || saved.Key != "attempt-a" {
+		t.Errorf("GetPublishAttempt() = %+v, %v, want key attempt-a", saved, err)
+	}
+}
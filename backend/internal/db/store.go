@@ -2,6 +2,7 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 )
 
@@ -28,10 +29,51 @@ type Page struct {
 	Category          string     `json:"category"`
 	ProfilePictureURL string     `json:"profile_picture_url"`
 	IsActive          bool       `json:"is_active"`
+	NativeScheduling  bool       `json:"native_scheduling"` // Mặc định hẹn giờ trên Facebook
+	TokenScopes       []string   `json:"token_scopes"`
+	Tasks             []string   `json:"tasks"` // Quyền của user trên page (CREATE_CONTENT, MODERATE...)
+	TokenCheckedAt    *time.Time `json:"token_checked_at"`
+	TokenIsValid      bool       `json:"token_is_valid"`
+
+	// Tài khoản Instagram Business liên kết (rỗng = chưa liên kết)
+	InstagramAccountID string `json:"instagram_account_id,omitempty"`
+	InstagramUsername  string `json:"instagram_username,omitempty"`
 	CreatedAt         time.Time  `json:"created_at"`
 	UpdatedAt         time.Time  `json:"updated_at"`
 }
 
+// Quyền cần để đăng bài lên page
+const (
+	PostingTask       = "CREATE_CONTENT"     // task của user trên page (me/accounts)
+	PostingPermission = "pages_manage_posts" // quyền của page token (debug_token)
+)
+
+// MissingPostingPermission trả về lý do page không có quyền đăng bài (rỗng = có quyền).
+// Tasks/scopes chưa kiểm tra (rỗng) thì coi như có quyền.
+func (p *Page) MissingPostingPermission() string {
+	if len(p.Tasks) > 0 && !containsString(p.Tasks, PostingTask) {
+		return "your role on page " + p.PageName + " does not include " + PostingTask
+	}
+	if len(p.TokenScopes) > 0 && !containsString(p.TokenScopes, PostingPermission) {
+		return "page " + p.PageName + " has not granted " + PostingPermission
+	}
+	return ""
+}
+
+// CanPost cho biết page có quyền đăng bài không
+func (p *Page) CanPost() bool {
+	return p.MissingPostingPermission() == ""
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 type Post struct {
 	ID        string    `json:"id"`
 	Content   string    `json:"content"`
@@ -41,6 +83,73 @@ type Post struct {
 	Status    string    `json:"status"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	
+	// Tùy chỉnh preview cho bài đăng dạng link (để trống = Facebook tự lấy từ trang đích)
+	LinkName        string `json:"link_name,omitempty"`
+	LinkDescription string `json:"link_description,omitempty"`
+	LinkPicture     string `json:"link_picture,omitempty"`
+	
+	// Comment đầu tiên tự động đăng ngay sau bài (text + ảnh tùy chọn)
+	FirstComment      string `json:"first_comment,omitempty"`
+	FirstCommentImage string `json:"first_comment_image,omitempty"`
+	
+	// Check-in địa điểm (FB place page ID) và người được tag (FB user ID, cần place_id)
+	PlaceID string   `json:"place_id,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	
+	// Nền tảng đăng: "facebook", "instagram" (rỗng = chỉ facebook)
+	Platforms []string `json:"platforms"`
+	
+	// Caption riêng cho từng ảnh (MediaCaptions[i] ứng với MediaURLs[i]), BurnCaptions = in caption lên ảnh.
+	// Bài nhiều ảnh có caption được đăng thành album AlbumName (rỗng = lấy dòng đầu nội dung).
+	MediaCaptions []string `json:"media_captions,omitempty"`
+	BurnCaptions  bool     `json:"burn_captions"`
+	AlbumName     string   `json:"album_name,omitempty"`
+	
+	// Targeting giới hạn người xem bài, FeedTargeting ưu tiên hiển thị trên news feed (nil = mọi người).
+	// Unpublished = bài ẩn (dark post): có trên page nhưng không hiện trên dòng thời gian.
+	Targeting     *Audience `json:"targeting,omitempty"`
+	FeedTargeting *Audience `json:"feed_targeting,omitempty"`
+	Unpublished   bool      `json:"unpublished"`
+}
+
+// Nền tảng đăng bài
+const (
+	PlatformFacebook  = "facebook"
+	PlatformInstagram = "instagram"
+)
+
+// MediaCaption trả về caption của media thứ i (rỗng nếu không có)
+func (p *Post) MediaCaption(i int) string {
+	if i < len(p.MediaCaptions) {
+		return p.MediaCaptions[i]
+	}
+	return ""
+}
+
+// HasMediaCaptions cho biết bài có caption riêng cho ít nhất 1 ảnh không
+func (p *Post) HasMediaCaptions() bool {
+	for _, caption := range p.MediaCaptions {
+		if caption != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// TargetsFacebook cho biết bài có đăng lên Facebook không (mặc định có)
+func (p *Post) TargetsFacebook() bool {
+	return len(p.Platforms) == 0 || p.HasPlatform(PlatformFacebook)
+}
+
+// HasPlatform cho biết bài có chọn nền tảng platform không
+func (p *Post) HasPlatform(platform string) bool {
+	for _, pl := range p.Platforms {
+		if pl == platform {
+			return true
+		}
+	}
+	return false
 }
 
 type ScheduledPost struct {
@@ -56,6 +165,16 @@ type ScheduledPost struct {
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 	
+	// "local" = scheduler tự đăng, "facebook" = hẹn giờ trên Facebook (fb_object_id)
+	SchedulingMode string  `json:"scheduling_mode"`
+	FbObjectID     *string `json:"fb_object_id,omitempty"`
+	// Số lần giao cho Facebook lỗi tạm thời (giãn thời gian thử lại)
+	HandOffAttempts int `json:"-"`
+	
+	// Bài Facebook đã đăng (dòng publish thành công mới nhất trong post_logs)
+	FacebookPostID string `json:"facebook_post_id,omitempty"`
+	PermalinkURL   string `json:"permalink_url,omitempty"`
+	
 	// Joined fields
 	Post    *Post            `json:"post,omitempty"`
 	Page    *Page            `json:"page,omitempty"`
@@ -68,10 +187,20 @@ type PostLog struct {
 	PostID           string    `json:"post_id"`
 	PageID           string    `json:"page_id"`
 	FacebookPostID   string    `json:"facebook_post_id"`
-	Status           string    `json:"status"`
+	CommentID        string    `json:"comment_id,omitempty"`
+	Status           string    `json:"status"` // success, partial (bài đã đăng, comment lỗi), failed
+	Action           string    `json:"action"` // publish (mặc định), edit, delete
+	Platform         string    `json:"platform"` // facebook (mặc định), instagram (facebook_post_id = IG media id)
 	ErrorMessage     string    `json:"error_message"`
-	ResponseData     string    `json:"response_data"`
+	ResponseData     json.RawMessage `json:"response_data,omitempty"` // Response gốc của Graph API (đăng bài + đọc lại bài)
 	PostedAt         time.Time `json:"posted_at"`
+	EditedAt         *time.Time `json:"edited_at,omitempty"`  // Bài đã sửa trên Facebook (dòng publish)
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"` // Bài đã xóa trên Facebook (dòng publish)
+	
+	// Thông tin bài đã đăng Facebook báo về: link bài, thời điểm tạo, ID ảnh/video đã upload
+	PermalinkURL  string     `json:"permalink_url,omitempty"`
+	FbCreatedTime *time.Time `json:"fb_created_time,omitempty"`
+	MediaIDs      []string   `json:"media_ids,omitempty"`
 	
 	// Joined fields
 	Post *Post `json:"post,omitempty"`
@@ -154,7 +154,7 @@ func (s *Store) IsSlotAvailable(slotID string, date time.Time) (bool, error) {
 		FROM page_time_slots pts
 		LEFT JOIN scheduled_posts sp ON sp.time_slot_id = pts.id
 			AND DATE(sp.scheduled_time) = $2
-			AND sp.status IN ('pending', 'processing')
+			AND sp.status IN ('pending', 'processing', 'fb_scheduled')
 		WHERE pts.id = $1
 		GROUP BY pts.slot_capacity
 	`
@@ -177,7 +177,7 @@ func (s *Store) GetPostsCountInSlot(slotID string, date time.Time) (int, error)
 		FROM scheduled_posts
 		WHERE time_slot_id = $1
 			AND DATE(scheduled_time) = $2
-			AND status IN ('pending', 'processing', 'completed')
+			AND status IN ('pending', 'processing', 'fb_scheduled', 'completed')
 	`
 
 	var count int
@@ -193,7 +193,7 @@ func (s *Store) GetSlotRemainingCapacity(slotID string, date time.Time) (int, er
 		FROM page_time_slots pts
 		LEFT JOIN scheduled_posts sp ON sp.time_slot_id = pts.id
 			AND DATE(sp.scheduled_time) = $2
-			AND sp.status IN ('pending', 'processing')
+			AND sp.status IN ('pending', 'processing', 'fb_scheduled')
 		WHERE pts.id = $1
 		GROUP BY pts.slot_capacity
 	`
@@ -352,7 +352,7 @@ func (s *Store) FindNextAvailableSlot(pageID string, startDate time.Time, maxDay
 			LEFT JOIN scheduled_posts sp 
 				ON sp.time_slot_id = pts.id 
 				AND DATE(sp.scheduled_time) = ds.check_date
-				AND sp.status IN ('pending', 'processing')
+				AND sp.status IN ('pending', 'processing', 'fb_scheduled')
 			WHERE pts.page_id = $1 
 				AND pts.is_active = true
 			GROUP BY pts.id, ds.check_date, pts.start_time, pts.end_time, 
@@ -421,7 +421,7 @@ func (s *Store) FindNextAvailableSlotsForPages(pageIDs []string, startDate time.
 			LEFT JOIN scheduled_posts sp 
 				ON sp.time_slot_id = pts.id 
 				AND DATE(sp.scheduled_time) = ds.check_date
-				AND sp.status IN ('pending', 'processing')
+				AND sp.status IN ('pending', 'processing', 'fb_scheduled')
 			WHERE pts.page_id = ANY($1)
 				AND pts.is_active = true
 				AND EXTRACT(ISODOW FROM ds.check_date)::int = ANY(pts.days_of_week)
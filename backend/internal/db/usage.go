@@ -0,0 +1,93 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// APIUsage snapshot usage Graph API gần nhất của 1 page hoặc 1 nick
+type APIUsage struct {
+	Kind           string          `json:"kind"` // "page" | "account"
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	Usage          json.RawMessage `json:"usage"`
+	UpdatedAt      *time.Time      `json:"updated_at"`
+	RateLimitUntil *time.Time      `json:"rate_limit_until"`
+}
+
+// SavePageAPIUsage lưu snapshot usage (JSON) của page token
+func (s *Store) SavePageAPIUsage(id string, usage []byte) error {
+	_, err := s.db.Exec(`UPDATE pages SET api_usage = $2, api_usage_updated_at = NOW() WHERE id = $1`, id, string(usage))
+	return err
+}
+
+// SaveAccountAPIUsage lưu snapshot usage (JSON) gần nhất của nick
+func (s *Store) SaveAccountAPIUsage(id string, usage []byte) error {
+	_, err := s.db.Exec(`UPDATE facebook_accounts SET api_usage = $2, api_usage_updated_at = NOW() WHERE id = $1`, id, string(usage))
+	return err
+}
+
+// PausePage tạm dừng đăng lên page trong d (không rút ngắn lần tạm dừng đang có)
+func (s *Store) PausePage(id string, d time.Duration) error {
+	query := `
+		UPDATE pages SET rate_limit_until = GREATEST(rate_limit_until, NOW() + make_interval(secs => $2))
+		WHERE id = $1
+	`
+	_, err := s.db.Exec(query, id, d.Seconds())
+	return err
+}
+
+// PauseAccount tạm dừng nick trong d; nick vẫn active và tự đăng lại khi hết hạn
+func (s *Store) PauseAccount(id string, d time.Duration) error {
+	query := `
+		UPDATE facebook_accounts SET rate_limit_until = GREATEST(rate_limit_until, NOW() + make_interval(secs => $2))
+		WHERE id = $1
+	`
+	_, err := s.db.Exec(query, id, d.Seconds())
+	return err
+}
+
+// IsPaused kiểm tra page ("page") hoặc nick ("account") đang bị tạm dừng (rate_limit_until chưa hết)
+func (s *Store) IsPaused(kind, id string) (bool, error) {
+	table := "pages"
+	if kind == "account" {
+		table = "facebook_accounts"
+	}
+	var paused bool
+	err := s.db.QueryRow(`SELECT COALESCE(rate_limit_until > NOW(), false) FROM `+table+` WHERE id = $1`, id).Scan(&paused)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return paused, err
+}
+
+// GetAPIUsage lấy snapshot usage của mọi page và nick
+func (s *Store) GetAPIUsage() ([]APIUsage, error) {
+	query := `
+		SELECT 'page', id, page_name, COALESCE(api_usage, '{}'), api_usage_updated_at, rate_limit_until
+		FROM pages
+		UNION ALL
+		SELECT 'account', id, fb_user_name, COALESCE(api_usage, '{}'), api_usage_updated_at, rate_limit_until
+		FROM facebook_accounts
+		ORDER BY 1 DESC, 3
+	`
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usages := make([]APIUsage, 0)
+	for rows.Next() {
+		var u APIUsage
+		var usage []byte
+		if err := rows.Scan(&u.Kind, &u.ID, &u.Name, &usage, &u.UpdatedAt, &u.RateLimitUntil); err != nil {
+			return nil, err
+		}
+		u.Usage = usage
+		usages = append(usages, u)
+	}
+	return usages, rows.Err()
+}
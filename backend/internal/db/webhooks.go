@@ -0,0 +1,107 @@
+package db
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEvent là 1 thay đổi nhận từ webhook Facebook
+type WebhookEvent struct {
+	ID             string          `json:"id"`
+	Object         string          `json:"object"`
+	EntryID        string          `json:"entry_id"`
+	Field          string          `json:"field"`
+	Item           string          `json:"item,omitempty"`
+	Verb           string          `json:"verb,omitempty"`
+	FacebookPostID string          `json:"facebook_post_id,omitempty"`
+	CommentID      string          `json:"comment_id,omitempty"`
+	FromID         string          `json:"from_id,omitempty"`
+	FromName       string          `json:"from_name,omitempty"`
+	Message        string          `json:"message,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	ReceivedAt     time.Time       `json:"received_at"`
+
+	// Liên kết tìm được khi lưu: page của app và dòng publish của bài (nil = không phải bài đăng từ app)
+	PageID    *string `json:"page_id"`
+	PageName  string  `json:"page_name,omitempty"`
+	PostLogID *string `json:"post_log_id"`
+}
+
+// SaveWebhookEvent lưu sự kiện và gắn page / dòng publish theo FB page id và facebook_post_id.
+// Chỉ gắn dòng publish đã đăng (success hoặc partial); bài individual mode (facebook_post_id gộp nhiều bài) không được gắn.
+func (s *Store) SaveWebhookEvent(e *WebhookEvent) error {
+	query := `
+		WITH pg AS (
+			SELECT id, page_name FROM pages WHERE page_id = $2
+		), pl AS (
+			SELECT id FROM post_logs
+			WHERE $6 <> '' AND facebook_post_id = $6 AND COALESCE(action, 'publish') = 'publish'
+			  AND status IN ('success', 'partial')
+			ORDER BY posted_at DESC
+			LIMIT 1
+		)
+		INSERT INTO webhook_events (object, entry_id, field, item, verb, facebook_post_id, comment_id,
+			from_id, from_name, message, payload, page_id, post_log_id)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''),
+			NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), $11,
+			(SELECT id FROM pg), (SELECT id FROM pl))
+		RETURNING id, received_at, page_id, COALESCE((SELECT page_name FROM pg), ''), post_log_id
+	`
+
+	return s.db.QueryRow(query,
+		e.Object, e.EntryID, e.Field, e.Item, e.Verb, e.FacebookPostID, e.CommentID,
+		e.FromID, e.FromName, e.Message, string(e.Payload),
+	).Scan(&e.ID, &e.ReceivedAt, &e.PageID, &e.PageName, &e.PostLogID)
+}
+
+// GetWebhookEvents lấy sự kiện webhook mới nhất (có phân trang)
+func (s *Store) GetWebhookEvents(limit, offset int) ([]WebhookEvent, error) {
+	query := `
+		SELECT we.id, we.object, we.entry_id, we.field, COALESCE(we.item, ''), COALESCE(we.verb, ''),
+			COALESCE(we.facebook_post_id, ''), COALESCE(we.comment_id, ''), COALESCE(we.from_id, ''),
+			COALESCE(we.from_name, ''), COALESCE(we.message, ''), we.payload, we.received_at,
+			we.page_id, COALESCE(pg.page_name, ''), we.post_log_id
+		FROM webhook_events we
+		LEFT JOIN pages pg ON we.page_id = pg.id
+		ORDER BY we.received_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := s.db.Query(query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]WebhookEvent, 0)
+	for rows.Next() {
+		var e WebhookEvent
+		var payload []byte
+		if err := rows.Scan(&e.ID, &e.Object, &e.EntryID, &e.Field, &e.Item, &e.Verb,
+			&e.FacebookPostID, &e.CommentID, &e.FromID,
+			&e.FromName, &e.Message, &payload, &e.ReceivedAt,
+			&e.PageID, &e.PageName, &e.PostLogID); err != nil {
+			return nil, err
+		}
+		e.Payload = payload
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// MarkPostLogRemoved đánh dấu bài bị gỡ trên Facebook; false nếu đã đánh dấu xóa trước đó
+// (vd: xóa từ app rồi Facebook gửi lại sự kiện remove)
+func (s *Store) MarkPostLogRemoved(id string) (bool, error) {
+	result, err := s.db.Exec(`UPDATE post_logs SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// SetPageWebhookSubscribed ghi nhận page đã đăng ký webhook (subscribed_apps)
+func (s *Store) SetPageWebhookSubscribed(id string) error {
+	_, err := s.db.Exec(`UPDATE pages SET webhook_subscribed_at = NOW() WHERE id = $1`, id)
+	return err
+}
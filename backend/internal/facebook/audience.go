@@ -0,0 +1,130 @@
+package facebook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Age limits accepted by targeting and feed_targeting
+const (
+	MinTargetingAge = 13
+	MaxTargetingAge = 65
+)
+
+// Audience selects people by location, language and age. As
+// PublishRequest.Targeting it restricts who can see a page post; as
+// FeedTargeting it only decides whose news feed the post is promoted in.
+// Regions and cities are Facebook location keys (search type=adgeolocation),
+// e.g. the key of a Vietnamese province.
+type Audience struct {
+	Countries []string `json:"countries,omitempty"` // ISO 3166 country codes, e.g. "VN"
+	Regions   []string `json:"regions,omitempty"`
+	Cities    []string `json:"cities,omitempty"`
+	Locales   []int    `json:"locales,omitempty"` // Facebook locale IDs
+	AgeMin    int      `json:"age_min,omitempty"`
+	AgeMax    int      `json:"age_max,omitempty"`
+}
+
+// IsEmpty reports whether the audience selects nobody in particular
+func (a Audience) IsEmpty() bool {
+	return len(a.Countries) == 0 && len(a.Regions) == 0 && len(a.Cities) == 0 &&
+		len(a.Locales) == 0 && a.AgeMin == 0 && a.AgeMax == 0
+}
+
+// Validate checks the audience before it is sent to Facebook. Errors wrap
+// ErrInvalidRequest.
+func (a Audience) Validate() error {
+	if a.IsEmpty() {
+		return fmt.Errorf("%w: audience needs a location, locale or age", ErrInvalidRequest)
+	}
+	for _, country := range a.Countries {
+		if len(country) != 2 || country[0] < 'A' || country[0] > 'Z' || country[1] < 'A' || country[1] > 'Z' {
+			return fmt.Errorf("%w: country %q must be a 2-letter ISO code such as VN", ErrInvalidRequest, country)
+		}
+	}
+	for _, key := range append(append([]string{}, a.Regions...), a.Cities...) {
+		if _, err := strconv.ParseInt(key, 10, 64); err != nil {
+			return fmt.Errorf("%w: location key %q must be a numeric Facebook location key", ErrInvalidRequest, key)
+		}
+	}
+	for _, age := range []int{a.AgeMin, a.AgeMax} {
+		if age != 0 && (age < MinTargetingAge || age > MaxTargetingAge) {
+			return fmt.Errorf("%w: age must be between %d and %d", ErrInvalidRequest, MinTargetingAge, MaxTargetingAge)
+		}
+	}
+	if a.AgeMin != 0 && a.AgeMax != 0 && a.AgeMin > a.AgeMax {
+		return fmt.Errorf("%w: age_min cannot be greater than age_max", ErrInvalidRequest)
+	}
+	return nil
+}
+
+// targetingParam encodes the audience as the "targeting" field of a post,
+// with locations under geo_locations
+func (a Audience) targetingParam() string {
+	geo := map[string]interface{}{}
+	if len(a.Countries) > 0 {
+		geo["countries"] = a.Countries
+	}
+	if len(a.Regions) > 0 {
+		geo["regions"] = locationKeys(a.Regions)
+	}
+	if len(a.Cities) > 0 {
+		geo["cities"] = locationKeys(a.Cities)
+	}
+
+	targeting := map[string]interface{}{}
+	if len(geo) > 0 {
+		targeting["geo_locations"] = geo
+	}
+	a.addLocaleAndAge(targeting)
+	data, _ := json.Marshal(targeting)
+	return string(data)
+}
+
+// feedTargetingParam encodes the audience as the "feed_targeting" field of
+// a post, which lists locations directly by key
+func (a Audience) feedTargetingParam() string {
+	targeting := map[string]interface{}{}
+	if len(a.Countries) > 0 {
+		targeting["countries"] = a.Countries
+	}
+	if len(a.Regions) > 0 {
+		targeting["regions"] = numericKeys(a.Regions)
+	}
+	if len(a.Cities) > 0 {
+		targeting["cities"] = numericKeys(a.Cities)
+	}
+	a.addLocaleAndAge(targeting)
+	data, _ := json.Marshal(targeting)
+	return string(data)
+}
+
+func (a Audience) addLocaleAndAge(targeting map[string]interface{}) {
+	if len(a.Locales) > 0 {
+		targeting["locales"] = a.Locales
+	}
+	if a.AgeMin != 0 {
+		targeting["age_min"] = a.AgeMin
+	}
+	if a.AgeMax != 0 {
+		targeting["age_max"] = a.AgeMax
+	}
+}
+
+func locationKeys(keys []string) []map[string]string {
+	out := make([]map[string]string, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, map[string]string{"key": key})
+	}
+	return out
+}
+
+func numericKeys(keys []string) []int64 {
+	out := make([]int64, 0, len(keys))
+	for _, key := range keys {
+		n, _ := strconv.ParseInt(key, 10, 64)
+		out = append(out, n)
+	}
+	return out
+}
@@ -0,0 +1,372 @@
+package facebook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sync"
+)
+
+// MaxBatchSize is the Graph API limit on operations in one batch request
+const MaxBatchSize = 50
+
+// ErrBatchFailed wraps the error of a batch request that failed as a whole,
+// e.g. a rejected batch token or a timeout: it says nothing about the
+// operations' own tokens and the requests should simply be retried
+var ErrBatchFailed = errors.New("batch request failed")
+
+// ErrOperationNotExecuted is returned for an operation Facebook answered
+// with null: it may or may not have run, see IsAmbiguous
+var ErrOperationNotExecuted = errors.New("batch operation was not executed")
+
+// batchFallbackConcurrency caps the Publish calls PublishBatch makes for
+// requests that cannot go through the batch endpoint
+const batchFallbackConcurrency = 4
+
+// BatchResult is the outcome of one request passed to PublishBatch
+type BatchResult struct {
+	Result *PublishResult
+	Err    error
+}
+
+// Batchable reports whether the request can go through the batch endpoint:
+// text, link, single-photo and multi-photo posts. Videos, reels, stories
+// and albums need several round trips that depend on each other's
+// responses and are published with Publish instead.
+func (r PublishRequest) Batchable() bool {
+	switch {
+	case r.MediaType == MediaTypeVideo || r.MediaType == MediaTypeReel || r.MediaType == MediaTypeStory:
+		return false
+	case r.AlbumName != "":
+		return false
+	}
+	// Every photo is one operation plus the feed post; they must all fit
+	// in the same batch
+	return len(r.Media)+1 <= MaxBatchSize
+}
+
+// batchOp is one operation of a batch request
+type batchOp struct {
+	Method                string `json:"method"`
+	RelativeURL           string `json:"relative_url"`
+	Name                  string `json:"name,omitempty"`
+	Body                  string `json:"body,omitempty"`
+	AttachedFiles         string `json:"attached_files,omitempty"`
+	OmitResponseOnSuccess *bool  `json:"omit_response_on_success,omitempty"`
+
+	// file is uploaded as the multipart field named by AttachedFiles
+	file *MediaItem
+	// accessToken is the page token in Body, for usage tracking
+	accessToken string
+}
+
+// batchResponse is one entry of the batch response array. Facebook returns
+// null for operations it did not run (timeout or a failed dependency).
+type batchResponse struct {
+	Code    int    `json:"code"`
+	Body    string `json:"body"`
+	Headers []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"headers"`
+}
+
+// header returns the operation's response headers
+func (r *batchResponse) header() http.Header {
+	header := http.Header{}
+	for _, h := range r.Headers {
+		header.Add(h.Name, h.Value)
+	}
+	return header
+}
+
+// batchGroup is the operations of one PublishRequest; a group is never
+// split across batches because its feed post references the photo uploads
+type batchGroup struct {
+	index int
+	ops   []batchOp
+}
+
+// resultRef matches JSONPath references once url-encoded, e.g.
+// %7Bresult%3Dr0_p1%3A%24.id%7D
+var resultRef = regexp.MustCompile(`%7Bresult%3D([A-Za-z0-9_]+)%3A%24\.id%7D`)
+
+// PublishBatch publishes many posts, usually to different pages, with as
+// few HTTP calls as possible. Batchable requests are packed into Graph
+// batch requests of up to MaxBatchSize operations; a multi-photo post
+// uploads its photos unpublished and attaches them to the feed post through
+// dependent operations in the same batch. Other requests fall back to
+// Publish, a few at a time.
+//
+// The result slice has one entry per request, in request order. Each
+// operation carries its request's page token; requests are batched by token
+// so the batch itself is sent with a token that belongs to every operation
+// in it. A batch that fails as a whole reports an ErrBatchFailed error for
+// each of its requests.
+func (c *Client) PublishBatch(ctx context.Context, reqs []PublishRequest) []BatchResult {
+	results := make([]BatchResult, len(reqs))
+
+	type tokenBatches struct {
+		batches    [][]batchGroup
+		currentOps int
+	}
+	byToken := make(map[string]*tokenBatches)
+	var tokens []string
+	var fallback []int
+	for i, req := range reqs {
+		if err := req.validate(); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if !req.Batchable() {
+			fallback = append(fallback, i)
+			continue
+		}
+		group := batchGroup{index: i, ops: c.batchOps(i, req)}
+		tb, ok := byToken[req.AccessToken]
+		if !ok {
+			tb = &tokenBatches{}
+			byToken[req.AccessToken] = tb
+			tokens = append(tokens, req.AccessToken)
+		}
+		if len(tb.batches) == 0 || tb.currentOps+len(group.ops) > MaxBatchSize {
+			tb.batches = append(tb.batches, nil)
+			tb.currentOps = 0
+		}
+		last := len(tb.batches) - 1
+		tb.batches[last] = append(tb.batches[last], group)
+		tb.currentOps += len(group.ops)
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, batchFallbackConcurrency)
+	for _, i := range fallback {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			result, err := c.Publish(ctx, reqs[i])
+			results[i] = BatchResult{Result: result, Err: err}
+		}(i)
+	}
+
+	// Media shared by several pages is downloaded once
+	downloads := make(map[string][]byte)
+	for _, token := range tokens {
+		batches := byToken[token].batches
+		for n, batch := range batches {
+			fmt.Printf("📦 Sending batch %d/%d (%d posts)...\n", n+1, len(batches), len(batch))
+			c.runBatch(ctx, token, batch, downloads, results)
+		}
+	}
+
+	wg.Wait()
+	return results
+}
+
+// batchOps turns a batchable request into operations: one feed or photos
+// post, preceded by an unpublished upload per photo for multi-photo posts
+func (c *Client) batchOps(index int, req PublishRequest) []batchOp {
+	switch len(req.Media) {
+	case 0:
+		return []batchOp{c.newBatchOp(req, "feed", req.feedParams(nil), nil)}
+	case 1:
+		return []batchOp{c.newBatchOp(req, "photos", req.singlePhotoParams(), &req.Media[0])}
+	}
+
+	ops := make([]batchOp, 0, len(req.Media)+1)
+	refs := make([]string, 0, len(req.Media))
+	keep := false
+	for j := range req.Media {
+		params := url.Values{}
+		params.Set("published", "false")
+		op := c.newBatchOp(req, "photos", params, &req.Media[j])
+		op.Name = fmt.Sprintf("r%d_p%d", index, j)
+		op.OmitResponseOnSuccess = &keep
+		ops = append(ops, op)
+		refs = append(refs, fmt.Sprintf("{result=%s:$.id}", op.Name))
+	}
+	return append(ops, c.newBatchOp(req, "feed", req.feedParams(refs), nil))
+}
+
+// newBatchOp builds a POST to the request's page edge. The page token and
+// its appsecret_proof go in the operation body, since every operation may
+// belong to a different page.
+func (c *Client) newBatchOp(req PublishRequest, edge string, params url.Values, file *MediaItem) batchOp {
+	params.Set("access_token", req.AccessToken)
+	if c.appSecret != "" {
+		params.Set("appsecret_proof", AppSecretProof(req.AccessToken, c.appSecret))
+	}
+	// Keep the JSONPath references readable to Facebook
+	body := resultRef.ReplaceAllString(params.Encode(), "{result=$1:$.id}")
+	return batchOp{
+		Method:      http.MethodPost,
+		RelativeURL: fmt.Sprintf("%s/%s/%s", c.version, req.PageID, edge),
+		Body:        body,
+		file:        file,
+		accessToken: req.AccessToken,
+	}
+}
+
+// runBatch sends one batch and stores the outcome of every group in results
+func (c *Client) runBatch(ctx context.Context, accessToken string, batch []batchGroup, downloads map[string][]byte, results []BatchResult) {
+	var ops []batchOp
+	for _, group := range batch {
+		ops = append(ops, group.ops...)
+	}
+
+	responses, err := c.sendBatch(ctx, accessToken, ops, downloads)
+	if err != nil {
+		for _, group := range batch {
+			results[group.index].Err = fmt.Errorf("%w: %w", ErrBatchFailed, err)
+		}
+		return
+	}
+
+	// Every operation reports the usage of its own page token
+	for i, resp := range responses {
+		if resp != nil && i < len(ops) {
+			c.recordUsage(ops[i].accessToken, resp.header())
+		}
+	}
+
+	offset := 0
+	for _, group := range batch {
+		var groupResponses []*batchResponse
+		if offset < len(responses) {
+			groupResponses = responses[offset:min(offset+len(group.ops), len(responses))]
+		}
+		offset += len(group.ops)
+		result, err := groupResult(group.ops, groupResponses)
+		results[group.index] = BatchResult{Result: result, Err: err}
+	}
+}
+
+// sendBatch posts the operations to the Graph root and returns the
+// response array, one entry per operation
+func (c *Client) sendBatch(ctx context.Context, accessToken string, ops []batchOp, downloads map[string][]byte) ([]*batchResponse, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	files := 0
+	for i := range ops {
+		if ops[i].file == nil {
+			continue
+		}
+		files++
+		ops[i].AttachedFiles = fmt.Sprintf("file%d", files)
+		data, err := c.readBatchMedia(ctx, *ops[i].file, downloads)
+		if err != nil {
+			return nil, err
+		}
+		filename := ops[i].file.Filename
+		if filename == "" {
+			filename = "image.jpg"
+		}
+		part, err := writer.CreateFormFile(ops[i].AttachedFiles, filename)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := part.Write(data); err != nil {
+			return nil, err
+		}
+	}
+
+	batchJSON, err := json.Marshal(ops)
+	if err != nil {
+		return nil, err
+	}
+	writer.WriteField("batch", string(batchJSON))
+	writer.WriteField("include_headers", "true")
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	httpReq, err := c.newGraphRequest(ctx, http.MethodPost, "/", accessToken, nil, body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", writer.FormDataContentType())
+	respBody, err := c.doGraph(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []*batchResponse
+	if err := json.Unmarshal(respBody, &responses); err != nil {
+		return nil, fmt.Errorf("failed to parse batch response: %s", string(respBody))
+	}
+	return responses, nil
+}
+
+// readBatchMedia returns an item's bytes; URL downloads are cached so a
+// photo posted to many pages is fetched once
+func (c *Client) readBatchMedia(ctx context.Context, item MediaItem, downloads map[string][]byte) ([]byte, error) {
+	cacheable := item.Reader == nil && item.Path == ""
+	if cacheable {
+		if data, ok := downloads[item.URL]; ok {
+			return data, nil
+		}
+	}
+
+	src, err := c.openMedia(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read media: %w", err)
+	}
+	if cacheable {
+		downloads[item.URL] = data
+	}
+	return data, nil
+}
+
+// groupResult turns the responses of one group into a PublishResult. The
+// first failed operation decides the error; the last operation is the post.
+func groupResult(ops []batchOp, responses []*batchResponse) (*PublishResult, error) {
+	var mediaIDs []string
+	for i, op := range ops {
+		var resp *batchResponse
+		if i < len(responses) {
+			resp = responses[i]
+		}
+		if resp == nil {
+			return nil, fmt.Errorf("%w: %s", ErrOperationNotExecuted, op.RelativeURL)
+		}
+		body := []byte(resp.Body)
+		if err := parseGraphError(resp.Code, body); err != nil {
+			if op.Name != "" {
+				return nil, fmt.Errorf("failed to upload media %d: %w", i+1, err)
+			}
+			return nil, err
+		}
+
+		if i < len(ops)-1 {
+			id, err := parseObjectID(body)
+			if err != nil {
+				return nil, err
+			}
+			mediaIDs = append(mediaIDs, id)
+			continue
+		}
+		if op.file != nil {
+			return parsePublishResult(body)
+		}
+		postID, err := parsePostID(body)
+		if err != nil {
+			return nil, err
+		}
+		return &PublishResult{PostID: postID, MediaIDs: mediaIDs, Response: body}, nil
+	}
+	return nil, fmt.Errorf("empty batch group")
+}
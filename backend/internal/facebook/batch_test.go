@@ -0,0 +1,187 @@
+package facebook_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"fbscheduler/internal/facebook"
+	"fbscheduler/internal/facebook/fake"
+)
+
+// batchOps decodes the operations of every batch request the server received
+func batchOps(t *testing.T, srv *fake.Server) []map[string]interface{} {
+	t.Helper()
+	var ops []map[string]interface{}
+	for _, call := range srv.CallsTo("batch") {
+		var batch []map[string]interface{}
+		if err := json.Unmarshal([]byte(call.Param("batch")), &batch); err != nil {
+			t.Fatalf("invalid batch parameter: %v", err)
+		}
+		ops = append(ops, batch...)
+	}
+	return ops
+}
+
+func TestPublishBatchResultRefs(t *testing.T) {
+	srv, client := newTestServer(t)
+	req := facebook.PublishRequest{
+		PageID: testPageID, AccessToken: testPageToken, Message: "two photos",
+		Media: facebook.MediaFromURLs([]string{
+			srv.AddMedia("a.jpg", []byte("jpeg-a")),
+			srv.AddMedia("b.jpg", []byte("jpeg-b")),
+		}),
+	}
+
+	results := client.PublishBatch(context.Background(), []facebook.PublishRequest{req})
+	if results[0].Err != nil {
+		t.Fatalf("PublishBatch() error = %v", results[0].Err)
+	}
+
+	ops := batchOps(t, srv)
+	if len(ops) != 3 {
+		t.Fatalf("batch has %d operations, want 2 photo uploads and the feed post", len(ops))
+	}
+	for i, name := range []string{"r0_p0", "r0_p1"} {
+		if ops[i]["name"] != name {
+			t.Errorf("operation %d name = %v, want %s", i, ops[i]["name"], name)
+		}
+	}
+	feedBody, _ := ops[2]["body"].(string)
+	for _, ref := range []string{"{result=r0_p0:$.id}", "{result=r0_p1:$.id}"} {
+		if !strings.Contains(feedBody, ref) {
+			t.Errorf("feed body %q does not reference %s", feedBody, ref)
+		}
+	}
+
+	// The fake resolves the references: the feed post carries the uploaded photo IDs
+	feeds := srv.CallsTo("feed")
+	if len(feeds) != 1 {
+		t.Fatalf("got %d feed calls, want 1", len(feeds))
+	}
+	var attached []string
+	for _, media := range feeds[0].Form["attached_media[]"] {
+		var m struct {
+			MediaFbid string `json:"media_fbid"`
+		}
+		json.Unmarshal([]byte(media), &m)
+		attached = append(attached, m.MediaFbid)
+	}
+	if !reflect.DeepEqual(attached, results[0].Result.MediaIDs) {
+		t.Errorf("attached media = %v, want uploaded photos %v", attached, results[0].Result.MediaIDs)
+	}
+}
+
+func TestPublishBatchResults(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.AddPage("456", "Other Page", "other-token")
+	reel := buildMP4(testVideo{width: 1080, height: 1920, duration: 10 * time.Second, padding: 512})
+
+	reqs := []facebook.PublishRequest{
+		{PageID: testPageID, AccessToken: testPageToken, Message: "first page"},
+		{PageID: "456", AccessToken: "other-token", Message: "second page"},
+		{PageID: testPageID, AccessToken: testPageToken}, // invalid: nothing to post
+		{PageID: testPageID, AccessToken: testPageToken, MediaType: facebook.MediaTypeReel,
+			Media: []facebook.MediaItem{{URL: srv.AddMedia("reel.mp4", reel)}}},
+	}
+	results := client.PublishBatch(context.Background(), reqs)
+
+	if len(results) != len(reqs) {
+		t.Fatalf("got %d results, want %d", len(results), len(reqs))
+	}
+	tests := []struct {
+		name     string
+		index    int
+		wantErr  error
+		idPrefix string
+	}{
+		{"first page", 0, nil, testPageID + "_"},
+		{"second page", 1, nil, "456_"},
+		{"invalid request", 2, facebook.ErrInvalidRequest, ""},
+		{"reel falls back to Publish", 3, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := results[tt.index]
+			if tt.wantErr != nil {
+				if !errors.Is(result.Err, tt.wantErr) {
+					t.Fatalf("Err = %v, want %v", result.Err, tt.wantErr)
+				}
+				return
+			}
+			if result.Err != nil {
+				t.Fatalf("Err = %v", result.Err)
+			}
+			if !strings.HasPrefix(result.Result.PostID, tt.idPrefix) || result.Result.PostID == "" {
+				t.Errorf("PostID = %q, want prefix %q", result.Result.PostID, tt.idPrefix)
+			}
+		})
+	}
+
+	// One batch per page token, sent with the token of its operations
+	batches := srv.CallsTo("batch")
+	if len(batches) != 2 {
+		t.Errorf("sent %d batch requests, want 1 per page token", len(batches))
+	}
+	for _, call := range batches {
+		var ops []map[string]interface{}
+		if err := json.Unmarshal([]byte(call.Param("batch")), &ops); err != nil {
+			t.Fatalf("invalid batch parameter: %v", err)
+		}
+		for _, op := range ops {
+			body, _ := op["body"].(string)
+			if !strings.Contains(body, "access_token="+call.AccessToken()) {
+				t.Errorf("operation %v does not carry the batch token %s", op["relative_url"], call.AccessToken())
+			}
+		}
+	}
+}
+
+func TestPublishBatchRequestError(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.FailNext("batch", fake.Error{Status: 400, Code: 190, Message: "Error validating access token"})
+
+	results := client.PublishBatch(context.Background(), []facebook.PublishRequest{
+		{PageID: testPageID, AccessToken: testPageToken, Message: "first"},
+		{PageID: testPageID, AccessToken: testPageToken, Message: "second"},
+	})
+	for i, result := range results {
+		if !errors.Is(result.Err, facebook.ErrBatchFailed) {
+			t.Errorf("result %d error = %v, want ErrBatchFailed", i, result.Err)
+		}
+	}
+}
+
+func TestPublishBatchOperationNotExecuted(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.Handle("batch", func(call fake.Call) (int, interface{}) {
+		return 200, []interface{}{nil}
+	})
+
+	results := client.PublishBatch(context.Background(), []facebook.PublishRequest{
+		{PageID: testPageID, AccessToken: testPageToken, Message: "timed out"},
+	})
+	if !errors.Is(results[0].Err, facebook.ErrOperationNotExecuted) || !facebook.IsAmbiguous(results[0].Err) {
+		t.Errorf("error = %v, want an ambiguous ErrOperationNotExecuted", results[0].Err)
+	}
+}
+
+func TestPublishBatchOperationError(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.FailNext("feed", fake.Error{Status: 400, Code: 368, Message: "blocked"})
+
+	results := client.PublishBatch(context.Background(), []facebook.PublishRequest{
+		{PageID: testPageID, AccessToken: testPageToken, Message: "blocked"},
+		{PageID: testPageID, AccessToken: testPageToken, Message: "fine"},
+	})
+	if graphErr, ok := facebook.AsGraphError(results[0].Err); !ok || graphErr.Code != 368 {
+		t.Errorf("first result error = %v, want Graph error 368", results[0].Err)
+	}
+	if results[1].Err != nil || results[1].Result == nil {
+		t.Errorf("second result = %+v, want success", results[1])
+	}
+}
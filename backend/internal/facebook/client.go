@@ -1,31 +1,106 @@
 package facebook
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"mime/multipart"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
+	"sync"
 	"time"
 )
 
 const (
-	GraphAPIURL = "https://graph.facebook.com/v18.0"
+	// DefaultBaseURL and DefaultVersion together form the Graph API root
+	DefaultBaseURL = "https://graph.facebook.com"
+	DefaultVersion = "v18.0"
 )
 
 type Client struct {
 	httpClient *http.Client
+	// mediaClient shares httpClient's transport without its total timeout:
+	// media downloads and upload chunks are bounded by their own context
+	// deadline instead, so large videos are not cut off after 30s
+	mediaClient *http.Client
+	baseURL     string
+	version     string
+	// appSecret signs every token with appsecret_proof and is used for
+	// the oauth and debug_token calls
+	appSecret string
+
+	// usage is the latest X-App-Usage / X-Page-Usage /
+	// X-Business-Use-Case-Usage snapshot per access token
+	usageMu sync.Mutex
+	usage   map[string]Usage
+
+	// containerPollInterval paces Instagram container status checks
+	containerPollInterval time.Duration
+}
+
+// Option configures a Client (base URL, version, transport)
+type Option func(*Client)
+
+// WithBaseURL points the client at another Graph API host, e.g. the fake server
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithVersion overrides the Graph API version (default v18.0)
+func WithVersion(version string) Option {
+	return func(c *Client) {
+		c.version = version
+	}
+}
+
+// WithHTTPClient replaces the http.Client used for every request
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
 }
 
-func NewClient() *Client {
-	return &Client{
+// WithAppSecret overrides FACEBOOK_APP_SECRET; an empty secret disables
+// appsecret_proof
+func WithAppSecret(appSecret string) Option {
+	return func(c *Client) {
+		c.appSecret = appSecret
+	}
+}
+
+// NewClient creates a Graph API client. FACEBOOK_GRAPH_BASE_URL, when set,
+// replaces graph.facebook.com so the server can run against a local fake.
+// FACEBOOK_APP_SECRET is used for appsecret_proof.
+func NewClient(opts ...Option) *Client {
+	c := &Client{
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		baseURL:   DefaultBaseURL,
+		version:   DefaultVersion,
+		appSecret: os.Getenv("FACEBOOK_APP_SECRET"),
+		usage:     make(map[string]Usage),
+
+		containerPollInterval: DefaultContainerPollInterval,
+	}
+	if baseURL := os.Getenv("FACEBOOK_GRAPH_BASE_URL"); baseURL != "" {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	mediaClient := *c.httpClient
+	mediaClient.Timeout = 0
+	c.mediaClient = &mediaClient
+	return c
+}
+
+// apiURL returns the versioned Graph API root
+func (c *Client) apiURL() string {
+	return c.baseURL + "/" + c.version
 }
 
 // GetAuthURL generates Facebook OAuth URL
@@ -33,14 +108,14 @@ func (c *Client) GetAuthURL(redirectURI string) string {
 	params := url.Values{}
 	params.Add("client_id", os.Getenv("FACEBOOK_APP_ID"))
 	params.Add("redirect_uri", redirectURI)
-	params.Add("scope", "pages_show_list,pages_read_engagement,pages_manage_posts,pages_manage_metadata,business_management")
+	params.Add("scope", "pages_show_list,pages_read_engagement,pages_manage_posts,pages_manage_metadata,read_insights,business_management,instagram_basic,instagram_content_publish")
 	params.Add("response_type", "code")
 	params.Add("auth_type", "rerequest") // Force Facebook to show permission dialog again
 	params.Add("display", "popup")
-	
-	authURL := fmt.Sprintf("https://www.facebook.com/v18.0/dialog/oauth?%s", params.Encode())
+
+	authURL := fmt.Sprintf("https://www.facebook.com/%s/dialog/oauth?%s", c.version, params.Encode())
 	fmt.Printf("🔗 Generated Auth URL: %s\n", authURL)
-	
+
 	return authURL
 }
 
@@ -48,50 +123,30 @@ func (c *Client) GetAuthURL(redirectURI string) string {
 func (c *Client) ExchangeCodeForToken(code, redirectURI string) (string, error) {
 	params := url.Values{}
 	params.Add("client_id", os.Getenv("FACEBOOK_APP_ID"))
-	params.Add("client_secret", os.Getenv("FACEBOOK_APP_SECRET"))
+	params.Add("client_secret", c.appSecret)
 	params.Add("redirect_uri", redirectURI)
 	params.Add("code", code)
-	
-	apiURL := fmt.Sprintf("%s/oauth/access_token?%s", GraphAPIURL, params.Encode())
-	fmt.Printf("Exchanging code for token: %s\n", apiURL)
-	
-	resp, err := c.httpClient.Get(apiURL)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-	
-	body, err := io.ReadAll(resp.Body)
+
+	fmt.Printf("Exchanging code for token (redirect_uri: %s)\n", redirectURI)
+
+	body, err := c.getGraph(context.Background(), "/oauth/access_token", "", params)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("facebook token exchange error: %w", err)
 	}
-	
-	fmt.Printf("Token exchange response: %s\n", string(body))
-	
+
 	var result struct {
 		AccessToken string `json:"access_token"`
 		TokenType   string `json:"token_type"`
-		Error *struct {
-			Message string `json:"message"`
-			Type    string `json:"type"`
-			Code    int    `json:"code"`
-		} `json:"error"`
 	}
-	
+
 	if err := json.Unmarshal(body, &result); err != nil {
 		return "", err
 	}
-	
-	if result.Error != nil {
-		return "", fmt.Errorf("facebook token exchange error: %s (code: %d)", result.Error.Message, result.Error.Code)
-	}
-	
+
 	if result.AccessToken == "" {
 		return "", fmt.Errorf("no access token in response")
 	}
-	
-	fmt.Printf("Successfully got access token: %s...\n", result.AccessToken[:20])
-	
+
 	return result.AccessToken, nil
 }
 
@@ -104,13 +159,13 @@ type UserInfo struct {
 
 // GetUserInfo lấy thông tin user từ access token
 func (c *Client) GetUserInfo(accessToken string) (*UserInfo, error) {
-	apiURL := fmt.Sprintf("%s/me?access_token=%s&fields=id,name,picture.width(200).height(200)", GraphAPIURL, accessToken)
+	params := url.Values{}
+	params.Set("fields", "id,name,picture.width(200).height(200)")
 
-	resp, err := c.httpClient.Get(apiURL)
+	body, err := c.getGraph(context.Background(), "/me", accessToken, params)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
 	var result struct {
 		ID      string `json:"id"`
@@ -120,19 +175,12 @@ func (c *Client) GetUserInfo(accessToken string) (*UserInfo, error) {
 				URL string `json:"url"`
 			} `json:"data"`
 		} `json:"picture"`
-		Error *struct {
-			Message string `json:"message"`
-		} `json:"error"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := json.Unmarshal(body, &result); err != nil {
 		return nil, err
 	}
 
-	if result.Error != nil {
-		return nil, fmt.Errorf("facebook error: %s", result.Error.Message)
-	}
-
 	return &UserInfo{
 		ID:         result.ID,
 		Name:       result.Name,
@@ -143,627 +191,43 @@ func (c *Client) GetUserInfo(accessToken string) (*UserInfo, error) {
 // GetUserPages retrieves all pages managed by the user
 func (c *Client) GetUserPages(userAccessToken string) ([]PageInfo, error) {
 	allPages := []PageInfo{}
-	url := fmt.Sprintf("%s/me/accounts?access_token=%s&fields=id,name,access_token,category,picture,tasks&limit=100", 
-		GraphAPIURL, userAccessToken)
-	
-	for url != "" {
-		resp, err := c.httpClient.Get(url)
-		if err != nil {
-			return nil, err
-		}
-		
-		// Read response body for logging
-		body, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
+	params := url.Values{}
+	params.Set("fields", "id,name,access_token,category,picture,tasks,instagram_business_account{id,username,profile_picture_url}")
+	params.Set("limit", "100")
+
+	// Later pages follow the paging.next URL, which already has the parameters
+	next := "/me/accounts"
+	for next != "" {
+		body, err := c.getGraph(context.Background(), next, userAccessToken, params)
 		if err != nil {
 			return nil, err
 		}
-		
-		fmt.Printf("Facebook API Response: %s\n", string(body))
-		
+		params = nil
+
+		fmt.Printf("Facebook API Response: %d bytes\n", len(body))
+
 		var result struct {
-			Data []PageInfo `json:"data"`
+			Data   []PageInfo `json:"data"`
 			Paging *struct {
 				Next string `json:"next"`
 			} `json:"paging"`
-			Error *struct {
-				Message string `json:"message"`
-				Type    string `json:"type"`
-				Code    int    `json:"code"`
-			} `json:"error"`
 		}
-		
+
 		if err := json.Unmarshal(body, &result); err != nil {
 			return nil, err
 		}
-		
-		if result.Error != nil {
-			return nil, fmt.Errorf("facebook API error: %s (code: %d)", result.Error.Message, result.Error.Code)
-		}
-		
+
 		allPages = append(allPages, result.Data...)
-		
+
 		// Check if there's a next page
 		if result.Paging != nil && result.Paging.Next != "" {
-			url = result.Paging.Next
+			next = result.Paging.Next
 		} else {
-			url = ""
-		}
-	}
-	
-	return allPages, nil
-}
-
-// PostToPage posts content to a Facebook page
-func (c *Client) PostToPage(pageID, accessToken, message string, mediaURLs []string, mediaType string) (string, error) {
-	if len(mediaURLs) == 0 {
-		// Text-only post
-		return c.postTextOnly(pageID, accessToken, message)
-	}
-	
-	// Check if it's video
-	if mediaType == "video" {
-		if len(mediaURLs) > 1 {
-			return "", fmt.Errorf("facebook only supports 1 video per post")
+			next = ""
 		}
-		return c.postWithVideo(pageID, accessToken, message, mediaURLs[0])
-	}
-	
-	// Image posts
-	if len(mediaURLs) == 1 {
-		return c.postWithSingleImage(pageID, accessToken, message, mediaURLs[0])
-	} else {
-		return c.postWithMultipleImages(pageID, accessToken, message, mediaURLs)
-	}
-}
-
-func (c *Client) postTextOnly(pageID, accessToken, message string) (string, error) {
-	data := url.Values{}
-	data.Set("message", message)
-	data.Set("access_token", accessToken)
-	
-	resp, err := c.httpClient.PostForm(fmt.Sprintf("%s/%s/feed", GraphAPIURL, pageID), data)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-	
-	return c.parsePostResponse(resp)
-}
-
-// PostToPageWithData posts with preloaded media data (optimized for multiple pages)
-func (c *Client) PostToPageWithData(pageID, accessToken, message string, mediaData [][]byte, mediaType string) (string, error) {
-	if mediaType == "video" && len(mediaData) > 0 {
-		return c.postVideoWithData(pageID, accessToken, message, mediaData[0])
-	}
-	// Fallback to URL-based upload
-	return "", fmt.Errorf("preloaded data only supported for video")
-}
-
-func (c *Client) postVideoWithData(pageID, accessToken, message string, videoData []byte) (string, error) {
-	fmt.Printf("📹 Uploading video to Facebook (%.2f MB)...\n", float64(len(videoData))/(1024*1024))
-	
-	// Upload to Facebook using multipart form
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	
-	// Add video file
-	part, err := writer.CreateFormFile("source", "video.mp4")
-	if err != nil {
-		return "", err
-	}
-	part.Write(videoData)
-	
-	// Add other fields
-	writer.WriteField("description", message)
-	writer.WriteField("access_token", accessToken)
-	writer.Close()
-	
-	// Post to Facebook
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/%s/videos", GraphAPIURL, pageID), body)
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	
-	fbResp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer fbResp.Body.Close()
-	
-	return c.parsePostResponse(fbResp)
-}
-
-func (c *Client) postWithVideo(pageID, accessToken, message, videoURL string) (string, error) {
-	fmt.Printf("📹 Downloading and uploading video...\n")
-	
-	// Download video from local URL
-	resp, err := c.httpClient.Get(videoURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to download video: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	// Read video data
-	videoData, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read video: %w", err)
-	}
-	
-	return c.postVideoWithData(pageID, accessToken, message, videoData)
-}
-
-func (c *Client) postWithSingleImage(pageID, accessToken, message, imageURL string) (string, error) {
-	// Download image from local URL
-	resp, err := c.httpClient.Get(imageURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to download image: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	// Read image data
-	imageData, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read image: %w", err)
-	}
-	
-	// Upload to Facebook using multipart form
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	
-	// Add image file
-	part, err := writer.CreateFormFile("source", "image.jpg")
-	if err != nil {
-		return "", err
-	}
-	part.Write(imageData)
-	
-	// Add other fields
-	writer.WriteField("message", message)
-	writer.WriteField("access_token", accessToken)
-	writer.Close()
-	
-	// Post to Facebook
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/%s/photos", GraphAPIURL, pageID), body)
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	
-	fbResp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer fbResp.Body.Close()
-	
-	return c.parsePostResponse(fbResp)
-}
-
-func (c *Client) postWithMultipleImages(pageID, accessToken, message string, imageURLs []string) (string, error) {
-	fmt.Printf("⚡ Uploading %d images concurrently...\n", len(imageURLs))
-	startTime := time.Now()
-	
-	// Step 1: Upload images concurrently
-	type uploadResult struct {
-		mediaID string
-		index   int
-		err     error
-	}
-	
-	resultChan := make(chan uploadResult, len(imageURLs))
-	
-	// Upload all images in parallel
-	for i, imageURL := range imageURLs {
-		go func(idx int, url string) {
-			mediaID, err := c.uploadPhoto(pageID, accessToken, url, "")
-			resultChan <- uploadResult{mediaID: mediaID, index: idx, err: err}
-		}(i, imageURL)
-	}
-	
-	// Collect results in order
-	results := make([]uploadResult, len(imageURLs))
-	for i := 0; i < len(imageURLs); i++ {
-		result := <-resultChan
-		results[result.index] = result
-	}
-	
-	// Check for errors and build mediaIDs array
-	var mediaIDs []string
-	for i, result := range results {
-		if result.err != nil {
-			return "", fmt.Errorf("failed to upload image %d: %w", i+1, result.err)
-		}
-		mediaIDs = append(mediaIDs, result.mediaID)
-	}
-	
-	uploadDuration := time.Since(startTime)
-	fmt.Printf("✅ Uploaded %d images in %.2f seconds (%.2f seconds per image)\n", 
-		len(imageURLs), uploadDuration.Seconds(), uploadDuration.Seconds()/float64(len(imageURLs)))
-	
-	// Step 2: Create post with multiple images
-	data := url.Values{}
-	data.Set("message", message)
-	for _, mediaID := range mediaIDs {
-		data.Add("attached_media[]", fmt.Sprintf(`{"media_fbid":"%s"}`, mediaID))
-	}
-	data.Set("access_token", accessToken)
-	
-	resp, err := c.httpClient.PostForm(fmt.Sprintf("%s/%s/feed", GraphAPIURL, pageID), data)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-	
-	return c.parsePostResponse(resp)
-}
-
-// ImageWithCaption represents an image with optional caption
-type ImageWithCaption struct {
-	URL     string `json:"url"`
-	Caption string `json:"caption,omitempty"`
-}
-
-// PostAlbumWithCaptions creates an album and uploads photos with individual captions
-func (c *Client) PostAlbumWithCaptions(pageID, accessToken, albumName, albumMessage string, images []ImageWithCaption) (string, error) {
-	fmt.Printf("📸 Creating album with %d photos (each with caption)...\n", len(images))
-	
-	// Step 1: Create album
-	albumID, err := c.createAlbum(pageID, accessToken, albumName, albumMessage)
-	if err != nil {
-		return "", fmt.Errorf("failed to create album: %w", err)
-	}
-	fmt.Printf("✅ Album created: %s\n", albumID)
-	
-	// Step 2: Upload photos concurrently
-	type uploadResult struct {
-		photoID string
-		index   int
-		err     error
-	}
-	
-	resultChan := make(chan uploadResult, len(images))
-	
-	for i, img := range images {
-		go func(idx int, image ImageWithCaption) {
-			photoID, err := c.uploadPhotoToAlbum(albumID, accessToken, image.URL, image.Caption)
-			resultChan <- uploadResult{photoID: photoID, index: idx, err: err}
-		}(i, img)
-	}
-	
-	// Collect results
-	results := make([]uploadResult, len(images))
-	for i := 0; i < len(images); i++ {
-		result := <-resultChan
-		results[result.index] = result
-	}
-	
-	// Check for errors
-	for i, result := range results {
-		if result.err != nil {
-			return "", fmt.Errorf("failed to upload photo %d: %w", i+1, result.err)
-		}
-		fmt.Printf("✅ Photo %d uploaded with caption\n", i+1)
-	}
-	
-	fmt.Printf("✅ Album posted successfully: %s\n", albumID)
-	return albumID, nil
-}
-
-func (c *Client) createAlbum(pageID, accessToken, name, message string) (string, error) {
-	data := url.Values{}
-	data.Set("name", name)
-	data.Set("message", message)
-	data.Set("access_token", accessToken)
-	
-	resp, err := c.httpClient.PostForm(fmt.Sprintf("%s/%s/albums", GraphAPIURL, pageID), data)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-	
-	bodyBytes, _ := io.ReadAll(resp.Body)
-	
-	var result struct {
-		ID    string `json:"id"`
-		Error *struct {
-			Message string `json:"message"`
-			Code    int    `json:"code"`
-		} `json:"error"`
-	}
-	
-	if err := json.Unmarshal(bodyBytes, &result); err != nil {
-		return "", fmt.Errorf("failed to parse album response: %s", string(bodyBytes))
-	}
-	
-	if result.Error != nil {
-		return "", fmt.Errorf("facebook error: %s (code: %d)", result.Error.Message, result.Error.Code)
-	}
-	
-	return result.ID, nil
-}
-
-func (c *Client) uploadPhotoToAlbum(albumID, accessToken, imageURL, caption string) (string, error) {
-	// Download image
-	resp, err := c.httpClient.Get(imageURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to download image: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	imageData, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read image: %w", err)
-	}
-	
-	// Upload to album
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	
-	part, err := writer.CreateFormFile("source", "image.jpg")
-	if err != nil {
-		return "", err
-	}
-	part.Write(imageData)
-	
-	writer.WriteField("message", caption)
-	writer.WriteField("published", "true")
-	writer.WriteField("access_token", accessToken)
-	writer.Close()
-	
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/%s/photos", GraphAPIURL, albumID), body)
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	
-	fbResp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer fbResp.Body.Close()
-	
-	bodyBytes, _ := io.ReadAll(fbResp.Body)
-	
-	var result struct {
-		ID    string `json:"id"`
-		PostID string `json:"post_id"`
-		Error *struct {
-			Message string `json:"message"`
-		} `json:"error"`
-	}
-	
-	if err := json.Unmarshal(bodyBytes, &result); err != nil {
-		return "", fmt.Errorf("failed to parse photo response: %s", string(bodyBytes))
-	}
-	
-	if result.Error != nil {
-		return "", fmt.Errorf("facebook error: %s", result.Error.Message)
-	}
-	
-	if result.PostID != "" {
-		return result.PostID, nil
-	}
-	return result.ID, nil
-}
-
-// PostToPageWithCaptions posts multiple images with individual captions
-func (c *Client) PostToPageWithCaptions(pageID, accessToken, message string, images []ImageWithCaption, mediaType string) (string, error) {
-	if len(images) == 0 {
-		return c.postTextOnly(pageID, accessToken, message)
-	}
-	
-	if len(images) == 1 {
-		return c.postWithSingleImage(pageID, accessToken, message, images[0].URL)
-	}
-	
-	fmt.Printf("⚡ Uploading %d images with captions concurrently...\n", len(images))
-	startTime := time.Now()
-	
-	// Step 1: Upload images concurrently with captions
-	type uploadResult struct {
-		mediaID string
-		caption string
-		index   int
-		err     error
-	}
-	
-	resultChan := make(chan uploadResult, len(images))
-	
-	// Upload all images in parallel
-	for i, img := range images {
-		go func(idx int, image ImageWithCaption) {
-			mediaID, err := c.uploadPhoto(pageID, accessToken, image.URL, image.Caption)
-			resultChan <- uploadResult{
-				mediaID: mediaID,
-				caption: image.Caption,
-				index:   idx,
-				err:     err,
-			}
-		}(i, img)
-	}
-	
-	// Collect results in order
-	results := make([]uploadResult, len(images))
-	for i := 0; i < len(images); i++ {
-		result := <-resultChan
-		results[result.index] = result
-	}
-	
-	// Check for errors and build attached_media array
-	// NOTE: Facebook API does NOT support "description" field in attached_media[]
-	// Captions can only be used in Individual mode (separate posts)
-	var attachedMedia []string
-	for i, result := range results {
-		if result.err != nil {
-			return "", fmt.Errorf("failed to upload image %d: %w", i+1, result.err)
-		}
-		
-		// Build attached_media JSON - only media_fbid is supported
-		mediaJSON := map[string]string{
-			"media_fbid": result.mediaID,
-		}
-		jsonBytes, err := json.Marshal(mediaJSON)
-		if err != nil {
-			return "", fmt.Errorf("failed to marshal media JSON: %w", err)
-		}
-		attachedMedia = append(attachedMedia, string(jsonBytes))
-	}
-	
-	uploadDuration := time.Since(startTime)
-	fmt.Printf("✅ Uploaded %d images with captions in %.2f seconds\n", 
-		len(images), uploadDuration.Seconds())
-	
-	// Step 2: Create post with multiple images and captions
-	data := url.Values{}
-	data.Set("message", message)
-	
-	fmt.Printf("📝 Building attached_media array:\n")
-	for i, media := range attachedMedia {
-		fmt.Printf("   [%d] %s\n", i, media)
-		data.Add("attached_media[]", media)
-	}
-	data.Set("access_token", accessToken)
-	
-	fmt.Printf("🚀 Posting to Facebook API: %s/%s/feed\n", GraphAPIURL, pageID)
-	fmt.Printf("   Message: %s\n", message)
-	fmt.Printf("   Attached media count: %d\n", len(attachedMedia))
-	fmt.Printf("⚠️  NOTE: Facebook does NOT support individual captions in album mode\n")
-	fmt.Printf("   Use Individual mode if you need different captions per image\n")
-	
-	resp, err := c.httpClient.PostForm(fmt.Sprintf("%s/%s/feed", GraphAPIURL, pageID), data)
-	if err != nil {
-		fmt.Printf("❌ Facebook API request failed: %v\n", err)
-		return "", err
-	}
-	defer resp.Body.Close()
-	
-	// Read response for debugging
-	bodyBytes, _ := io.ReadAll(resp.Body)
-	fmt.Printf("📥 Facebook API Response (status %d): %s\n", resp.StatusCode, string(bodyBytes))
-	
-	// Parse response
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("facebook API error (status %d): %s", resp.StatusCode, string(bodyBytes))
-	}
-	
-	var result struct {
-		ID    string `json:"id"`
-		PostID string `json:"post_id"`
-		Error *struct {
-			Message string `json:"message"`
-			Type    string `json:"type"`
-			Code    int    `json:"code"`
-		} `json:"error"`
-	}
-	
-	if err := json.Unmarshal(bodyBytes, &result); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
-	}
-	
-	if result.Error != nil {
-		return "", fmt.Errorf("facebook API error: %s (code: %d)", result.Error.Message, result.Error.Code)
-	}
-	
-	if result.PostID != "" {
-		fmt.Printf("✅ Post created successfully: %s\n", result.PostID)
-		return result.PostID, nil
-	}
-	fmt.Printf("✅ Post created successfully: %s\n", result.ID)
-	return result.ID, nil
-}
-
-func (c *Client) uploadPhoto(pageID, accessToken, imageURL, caption string) (string, error) {
-	// Download image from local URL
-	resp, err := c.httpClient.Get(imageURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to download image: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	// Read image data
-	imageData, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read image: %w", err)
-	}
-	
-	// Upload to Facebook using multipart form
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	
-	// Add image file
-	part, err := writer.CreateFormFile("source", "image.jpg")
-	if err != nil {
-		return "", err
-	}
-	part.Write(imageData)
-	
-	// Add other fields
-	writer.WriteField("published", "false")
-	writer.WriteField("access_token", accessToken)
-	
-	// NOTE: Caption is NOT used here because:
-	// - For unpublished photos, caption is ignored by Facebook
-	// - For album mode, only the post message is shown
-	// - For individual mode, we post each image separately with its own message
-	
-	writer.Close()
-	
-	// Post to Facebook
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/%s/photos", GraphAPIURL, pageID), body)
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	
-	fbResp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer fbResp.Body.Close()
-	
-	var result struct {
-		ID string `json:"id"`
-		Error *struct {
-			Message string `json:"message"`
-		} `json:"error"`
-	}
-	
-	bodyBytes, _ := io.ReadAll(fbResp.Body)
-	if err := json.Unmarshal(bodyBytes, &result); err != nil {
-		return "", fmt.Errorf("failed to parse upload response: %s", string(bodyBytes))
-	}
-	
-	if result.Error != nil {
-		return "", fmt.Errorf("facebook upload error: %s", result.Error.Message)
 	}
-	
-	return result.ID, nil
-}
 
-func (c *Client) parsePostResponse(resp *http.Response) (string, error) {
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
-	}
-	
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("facebook API error: %s", string(body))
-	}
-	
-	var result struct {
-		ID    string `json:"id"`
-		PostID string `json:"post_id"`
-	}
-	
-	if err := json.Unmarshal(body, &result); err != nil {
-		return "", err
-	}
-	
-	if result.PostID != "" {
-		return result.PostID, nil
-	}
-	return result.ID, nil
+	return allPages, nil
 }
 
 // PageInfo represents a Facebook page
@@ -778,504 +242,6 @@ type PageInfo struct {
 			URL string `json:"url"`
 		} `json:"data"`
 	} `json:"picture"`
-}
-
-
-// PostToPageWithPlace posts content with optional place parameter
-func (c *Client) PostToPageWithPlace(pageID, accessToken, message string, mediaURLs []string, mediaType, placeID string) (string, error) {
-	// Delegate to existing functions but add place parameter
-	if len(mediaURLs) == 0 {
-		return c.postTextOnlyWithPlace(pageID, accessToken, message, placeID)
-	}
-	
-	if mediaType == "video" {
-		return c.postWithVideoAndPlace(pageID, accessToken, message, mediaURLs[0], placeID)
-	}
-	
-	if len(mediaURLs) == 1 {
-		return c.postWithSingleImageAndPlace(pageID, accessToken, message, mediaURLs[0], placeID)
-	}
-	
-	return c.postWithMultipleImagesAndPlace(pageID, accessToken, message, mediaURLs, placeID)
-}
-
-// PostToPageWithDataAndPlace posts with preloaded media data and place
-func (c *Client) PostToPageWithDataAndPlace(pageID, accessToken, message string, mediaData [][]byte, mediaType, placeID string) (string, error) {
-	if len(mediaData) == 0 {
-		return c.postTextOnlyWithPlace(pageID, accessToken, message, placeID)
-	}
-	
-	if mediaType == "video" {
-		return c.postVideoWithDataAndPlace(pageID, accessToken, message, mediaData[0], placeID)
-	}
-	
-	if len(mediaData) == 1 {
-		return c.postSingleImageWithDataAndPlace(pageID, accessToken, message, mediaData[0], placeID)
-	}
-	
-	return c.postMultipleImagesWithDataAndPlace(pageID, accessToken, message, mediaData, placeID)
-}
-
-// Helper functions with place parameter
-func (c *Client) postTextOnlyWithPlace(pageID, accessToken, message, tags string) (string, error) {
-	data := url.Values{}
-	data.Set("message", message)
-	data.Set("access_token", accessToken)
-	if tags != "" {
-		data.Set("tags", tags)
-	}
-	
-	resp, err := c.httpClient.PostForm(fmt.Sprintf("%s/%s/feed", GraphAPIURL, pageID), data)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-	
-	return c.parsePostResponse(resp)
-}
-
-func (c *Client) postWithSingleImageAndPlace(pageID, accessToken, message, imageURL, placeID string) (string, error) {
-	resp, err := http.Get(imageURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to download image: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	imageData, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read image: %w", err)
-	}
-	
-	return c.postSingleImageWithDataAndPlace(pageID, accessToken, message, imageData, placeID)
-}
-
-func (c *Client) postSingleImageWithDataAndPlace(pageID, accessToken, message string, imageData []byte, placeID string) (string, error) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	
-	part, err := writer.CreateFormFile("source", "image.jpg")
-	if err != nil {
-		return "", err
-	}
-	part.Write(imageData)
-	
-	writer.WriteField("message", message)
-	writer.WriteField("access_token", accessToken)
-	if placeID != "" {
-		writer.WriteField("tags", placeID)
-	}
-	writer.Close()
-	
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/%s/photos", GraphAPIURL, pageID), body)
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	
-	fbResp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer fbResp.Body.Close()
-	
-	return c.parsePostResponse(fbResp)
-}
-
-func (c *Client) postWithMultipleImagesAndPlace(pageID, accessToken, message string, imageURLs []string, placeID string) (string, error) {
-	// Download all images first
-	var imageDataList [][]byte
-	for _, imageURL := range imageURLs {
-		resp, err := http.Get(imageURL)
-		if err != nil {
-			return "", fmt.Errorf("failed to download image: %w", err)
-		}
-		imageData, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		if err != nil {
-			return "", fmt.Errorf("failed to read image: %w", err)
-		}
-		imageDataList = append(imageDataList, imageData)
-	}
-	
-	return c.postMultipleImagesWithDataAndPlace(pageID, accessToken, message, imageDataList, placeID)
-}
-
-func (c *Client) postMultipleImagesWithDataAndPlace(pageID, accessToken, message string, imageDataList [][]byte, placeID string) (string, error) {
-	// Upload all images first
-	type uploadResult struct {
-		mediaID string
-		index   int
-		err     error
-	}
-	
-	resultChan := make(chan uploadResult, len(imageDataList))
-	
-	for i, imageData := range imageDataList {
-		go func(idx int, data []byte) {
-			mediaID, err := c.uploadPhotoData(pageID, accessToken, data)
-			resultChan <- uploadResult{
-				mediaID: mediaID,
-				index:   idx,
-				err:     err,
-			}
-		}(i, imageData)
-	}
-	
-	results := make([]uploadResult, len(imageDataList))
-	for i := 0; i < len(imageDataList); i++ {
-		result := <-resultChan
-		results[result.index] = result
-	}
-	
-	var attachedMedia []string
-	for i, result := range results {
-		if result.err != nil {
-			return "", fmt.Errorf("failed to upload image %d: %w", i+1, result.err)
-		}
-		mediaJSON := map[string]string{"media_fbid": result.mediaID}
-		jsonBytes, _ := json.Marshal(mediaJSON)
-		attachedMedia = append(attachedMedia, string(jsonBytes))
-	}
-	
-	// Create post with attached media
-	data := url.Values{}
-	data.Set("message", message)
-	for _, media := range attachedMedia {
-		data.Add("attached_media[]", media)
-	}
-	data.Set("access_token", accessToken)
-	if placeID != "" {
-		data.Set("tags", placeID)
-	}
-	
-	resp, err := c.httpClient.PostForm(fmt.Sprintf("%s/%s/feed", GraphAPIURL, pageID), data)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-	
-	return c.parsePostResponse(resp)
-}
-
-func (c *Client) postWithVideoAndPlace(pageID, accessToken, message, videoURL, placeID string) (string, error) {
-	resp, err := http.Get(videoURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to download video: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	videoData, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read video: %w", err)
-	}
-	
-	return c.postVideoWithDataAndPlace(pageID, accessToken, message, videoData, placeID)
-}
-
-func (c *Client) postVideoWithDataAndPlace(pageID, accessToken, message string, videoData []byte, placeID string) (string, error) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	
-	part, err := writer.CreateFormFile("source", "video.mp4")
-	if err != nil {
-		return "", err
-	}
-	part.Write(videoData)
-	
-	writer.WriteField("description", message)
-	writer.WriteField("access_token", accessToken)
-	if placeID != "" {
-		writer.WriteField("tags", placeID)
-	}
-	writer.Close()
-	
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/%s/videos", GraphAPIURL, pageID), body)
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	
-	fbResp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer fbResp.Body.Close()
-	
-	return c.parsePostResponse(fbResp)
-}
-
-func (c *Client) uploadPhotoData(pageID, accessToken string, imageData []byte) (string, error) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	
-	part, err := writer.CreateFormFile("source", "image.jpg")
-	if err != nil {
-		return "", err
-	}
-	part.Write(imageData)
-	
-	writer.WriteField("published", "false")
-	writer.WriteField("access_token", accessToken)
-	writer.Close()
-	
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/%s/photos", GraphAPIURL, pageID), body)
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	
-	fbResp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer fbResp.Body.Close()
-	
-	var result struct {
-		ID string `json:"id"`
-		Error *struct {
-			Message string `json:"message"`
-		} `json:"error"`
-	}
-	
-	bodyBytes, _ := io.ReadAll(fbResp.Body)
-	if err := json.Unmarshal(bodyBytes, &result); err != nil {
-		return "", fmt.Errorf("failed to parse upload response: %s", string(bodyBytes))
-	}
-	
-	if result.Error != nil {
-		return "", fmt.Errorf("facebook upload error: %s", result.Error.Message)
-	}
-	
-	return result.ID, nil
-}
-
-
-// PostToPageWithTagsAndPlace posts with both tags and place
-func (c *Client) PostToPageWithTagsAndPlaceURLs(pageID, accessToken, message string, mediaURLs []string, mediaType, tags, place string) (string, error) {
-	if len(mediaURLs) == 0 {
-		return c.postTextOnlyWithTagsAndPlace(pageID, accessToken, message, tags, place)
-	}
-	
-	if mediaType == "video" {
-		return c.postWithVideoAndTagsPlace(pageID, accessToken, message, mediaURLs[0], tags, place)
-	}
-	
-	if len(mediaURLs) == 1 {
-		return c.postWithSingleImageAndTagsPlace(pageID, accessToken, message, mediaURLs[0], tags, place)
-	}
-	
-	return c.postWithMultipleImagesAndTagsPlace(pageID, accessToken, message, mediaURLs, tags, place)
-}
-
-// PostToPageWithTagsAndPlace posts with preloaded data
-func (c *Client) PostToPageWithTagsAndPlace(pageID, accessToken, message string, mediaData [][]byte, mediaType, tags, place string) (string, error) {
-	if len(mediaData) == 0 {
-		return c.postTextOnlyWithTagsAndPlace(pageID, accessToken, message, tags, place)
-	}
-	
-	if mediaType == "video" {
-		return c.postVideoWithDataAndTagsPlace(pageID, accessToken, message, mediaData[0], tags, place)
-	}
-	
-	if len(mediaData) == 1 {
-		return c.postSingleImageWithDataAndTagsPlace(pageID, accessToken, message, mediaData[0], tags, place)
-	}
-	
-	return c.postMultipleImagesWithDataAndTagsPlace(pageID, accessToken, message, mediaData, tags, place)
-}
-
-func (c *Client) postTextOnlyWithTagsAndPlace(pageID, accessToken, message, tags, place string) (string, error) {
-	data := url.Values{}
-	data.Set("message", message)
-	data.Set("access_token", accessToken)
-	if tags != "" {
-		data.Set("tags", tags)
-	}
-	if place != "" {
-		data.Set("place", place)
-	}
-	
-	resp, err := c.httpClient.PostForm(fmt.Sprintf("%s/%s/feed", GraphAPIURL, pageID), data)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-	
-	return c.parsePostResponse(resp)
-}
-
-func (c *Client) postWithSingleImageAndTagsPlace(pageID, accessToken, message, imageURL, tags, place string) (string, error) {
-	resp, err := http.Get(imageURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to download image: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	imageData, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read image: %w", err)
-	}
-	
-	return c.postSingleImageWithDataAndTagsPlace(pageID, accessToken, message, imageData, tags, place)
-}
-
-func (c *Client) postSingleImageWithDataAndTagsPlace(pageID, accessToken, message string, imageData []byte, tags, place string) (string, error) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	
-	part, err := writer.CreateFormFile("source", "image.jpg")
-	if err != nil {
-		return "", err
-	}
-	part.Write(imageData)
-	
-	writer.WriteField("message", message)
-	writer.WriteField("access_token", accessToken)
-	if tags != "" {
-		writer.WriteField("tags", tags)
-	}
-	if place != "" {
-		writer.WriteField("place", place)
-	}
-	writer.Close()
-	
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/%s/photos", GraphAPIURL, pageID), body)
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	
-	fbResp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer fbResp.Body.Close()
-	
-	return c.parsePostResponse(fbResp)
-}
-
-func (c *Client) postWithMultipleImagesAndTagsPlace(pageID, accessToken, message string, imageURLs []string, tags, place string) (string, error) {
-	var imageDataList [][]byte
-	for _, imageURL := range imageURLs {
-		resp, err := http.Get(imageURL)
-		if err != nil {
-			return "", fmt.Errorf("failed to download image: %w", err)
-		}
-		imageData, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		if err != nil {
-			return "", fmt.Errorf("failed to read image: %w", err)
-		}
-		imageDataList = append(imageDataList, imageData)
-	}
-	
-	return c.postMultipleImagesWithDataAndTagsPlace(pageID, accessToken, message, imageDataList, tags, place)
-}
-
-func (c *Client) postMultipleImagesWithDataAndTagsPlace(pageID, accessToken, message string, imageDataList [][]byte, tags, place string) (string, error) {
-	type uploadResult struct {
-		mediaID string
-		index   int
-		err     error
-	}
-	
-	resultChan := make(chan uploadResult, len(imageDataList))
-	
-	for i, imageData := range imageDataList {
-		go func(idx int, data []byte) {
-			mediaID, err := c.uploadPhotoData(pageID, accessToken, data)
-			resultChan <- uploadResult{
-				mediaID: mediaID,
-				index:   idx,
-				err:     err,
-			}
-		}(i, imageData)
-	}
-	
-	results := make([]uploadResult, len(imageDataList))
-	for i := 0; i < len(imageDataList); i++ {
-		result := <-resultChan
-		results[result.index] = result
-	}
-	
-	var attachedMedia []string
-	for i, result := range results {
-		if result.err != nil {
-			return "", fmt.Errorf("failed to upload image %d: %w", i+1, result.err)
-		}
-		mediaJSON := map[string]string{"media_fbid": result.mediaID}
-		jsonBytes, _ := json.Marshal(mediaJSON)
-		attachedMedia = append(attachedMedia, string(jsonBytes))
-	}
-	
-	data := url.Values{}
-	data.Set("message", message)
-	for _, media := range attachedMedia {
-		data.Add("attached_media[]", media)
-	}
-	data.Set("access_token", accessToken)
-	if tags != "" {
-		data.Set("tags", tags)
-	}
-	if place != "" {
-		data.Set("place", place)
-	}
-	
-	resp, err := c.httpClient.PostForm(fmt.Sprintf("%s/%s/feed", GraphAPIURL, pageID), data)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-	
-	return c.parsePostResponse(resp)
-}
-
-func (c *Client) postWithVideoAndTagsPlace(pageID, accessToken, message, videoURL, tags, place string) (string, error) {
-	resp, err := http.Get(videoURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to download video: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	videoData, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read video: %w", err)
-	}
-	
-	return c.postVideoWithDataAndTagsPlace(pageID, accessToken, message, videoData, tags, place)
-}
-
-func (c *Client) postVideoWithDataAndTagsPlace(pageID, accessToken, message string, videoData []byte, tags, place string) (string, error) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	
-	part, err := writer.CreateFormFile("source", "video.mp4")
-	if err != nil {
-		return "", err
-	}
-	part.Write(videoData)
-	
-	writer.WriteField("description", message)
-	writer.WriteField("access_token", accessToken)
-	if tags != "" {
-		writer.WriteField("tags", tags)
-	}
-	if place != "" {
-		writer.WriteField("place", place)
-	}
-	writer.Close()
-	
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/%s/videos", GraphAPIURL, pageID), body)
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	
-	fbResp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer fbResp.Body.Close()
-	
-	return c.parsePostResponse(fbResp)
+	// InstagramBusinessAccount is set when an Instagram Business account is linked
+	InstagramBusinessAccount *InstagramAccount `json:"instagram_business_account,omitempty"`
 }
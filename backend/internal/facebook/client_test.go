@@ -0,0 +1,76 @@
+package facebook_test
+
+import (
+	"testing"
+
+	"fbscheduler/internal/facebook"
+	"fbscheduler/internal/facebook/fake"
+)
+
+const (
+	testPageID    = "123"
+	testPageToken = "page-token"
+)
+
+// newTestServer starts a fake Graph API with one page and a client without an app secret
+func newTestServer(t *testing.T) (*fake.Server, *facebook.Client) {
+	t.Helper()
+	srv := fake.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddPage(testPageID, "Test Page", testPageToken)
+	return srv, srv.Client(facebook.WithAppSecret(""))
+}
+
+// postEdges lists the edges of the POST calls the server received, in order
+func postEdges(srv *fake.Server) []string {
+	var edges []string
+	for _, call := range srv.Calls() {
+		if call.Method == "POST" {
+			edges = append(edges, call.Edge)
+		}
+	}
+	return edges
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestClientUsesBaseURLAndVersion(t *testing.T) {
+	srv := fake.NewServer()
+	defer srv.Close()
+	srv.AddPage(testPageID, "Test Page", testPageToken)
+	srv.AddPage("456", "Other Page", "other-token")
+	client := srv.Client(facebook.WithVersion("v19.0"), facebook.WithAppSecret(""))
+
+	user, err := client.GetUserInfo("user-token")
+	if err != nil {
+		t.Fatalf("GetUserInfo() error = %v", err)
+	}
+	if user.ID == "" {
+		t.Error("GetUserInfo() returned no user ID")
+	}
+
+	pages, err := client.GetUserPages("user-token")
+	if err != nil {
+		t.Fatalf("GetUserPages() error = %v", err)
+	}
+	if len(pages) != 2 || pages[0].AccessToken != testPageToken {
+		t.Errorf("GetUserPages() = %+v, want the two pages of the fake server", pages)
+	}
+
+	tests := []struct {
+		index int
+		path  string
+	}{
+		{0, "/me"},
+		{1, "/me/accounts"},
+	}
+	calls := srv.Calls()
+	if len(calls) != len(tests) {
+		t.Fatalf("got %d calls, want %d", len(calls), len(tests))
+	}
+	for _, tt := range tests {
+		if calls[tt.index].Path != tt.path {
+			t.Errorf("call %d path = %s, want %s", tt.index, calls[tt.index].Path, tt.path)
+		}
+	}
+}
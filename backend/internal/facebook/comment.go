@@ -0,0 +1,50 @@
+package facebook
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// CommentRequest describes a comment on a post, photo or video
+type CommentRequest struct {
+	ObjectID    string
+	AccessToken string
+	Message     string
+	// Image is an optional photo attached to the comment. A URL-only item is
+	// sent as attachment_url; Path or Reader data is uploaded.
+	Image *MediaItem
+}
+
+// Comment posts a comment through the object's comments edge and returns
+// the comment ID
+func (c *Client) Comment(ctx context.Context, req CommentRequest) (string, error) {
+	if req.ObjectID == "" || req.AccessToken == "" {
+		return "", fmt.Errorf("%w: object ID and access token are required", ErrInvalidRequest)
+	}
+	if req.Message == "" && req.Image == nil {
+		return "", fmt.Errorf("%w: comment message or image is required", ErrInvalidRequest)
+	}
+
+	path := fmt.Sprintf("/%s/comments", req.ObjectID)
+	params := url.Values{}
+	if req.Message != "" {
+		params.Set("message", req.Message)
+	}
+
+	var body []byte
+	var err error
+	switch {
+	case req.Image == nil:
+		body, err = c.postForm(ctx, path, req.AccessToken, params)
+	case req.Image.Reader == nil && req.Image.Path == "":
+		params.Set("attachment_url", req.Image.URL)
+		body, err = c.postForm(ctx, path, req.AccessToken, params)
+	default:
+		body, err = c.uploadMedia(ctx, path, req.AccessToken, params, *req.Image, "comment.jpg")
+	}
+	if err != nil {
+		return "", err
+	}
+	return parseObjectID(body)
+}
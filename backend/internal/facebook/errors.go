@@ -0,0 +1,148 @@
+package facebook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+)
+
+// GraphError is a structured error returned by the Graph API
+// (https://developers.facebook.com/docs/graph-api/guides/error-handling)
+type GraphError struct {
+	StatusCode  int    `json:"-"`
+	Message     string `json:"message"`
+	Type        string `json:"type"`
+	Code        int    `json:"code"`
+	Subcode     int    `json:"error_subcode"`
+	FBTraceID   string `json:"fbtrace_id"`
+	Transient   bool   `json:"is_transient"`
+	UserTitle   string `json:"error_user_title"`
+	UserMessage string `json:"error_user_msg"`
+}
+
+func (e *GraphError) Error() string {
+	msg := fmt.Sprintf("facebook API error: %s (code: %d", e.Message, e.Code)
+	if e.Subcode != 0 {
+		msg += fmt.Sprintf(", subcode: %d", e.Subcode)
+	}
+	if e.FBTraceID != "" {
+		msg += ", fbtrace_id: " + e.FBTraceID
+	}
+	return msg + ")"
+}
+
+// IsRateLimit reports app, user, page or business use case throttling
+func (e *GraphError) IsRateLimit() bool {
+	switch e.Code {
+	case 4, 17, 32, 341, 613:
+		return true
+	}
+	// Business use case rate limits (80001 Pages, 80004 Ads, ...)
+	return e.Code >= 80000 && e.Code <= 80014
+}
+
+// IsTokenExpired reports an invalid, expired or revoked access token
+func (e *GraphError) IsTokenExpired() bool {
+	if e.Code == 190 || e.Code == 102 {
+		return true
+	}
+	switch e.Subcode {
+	case 458, 459, 460, 463, 464, 467:
+		return true
+	}
+	return false
+}
+
+// IsPermissionDenied reports a missing permission or page task
+func (e *GraphError) IsPermissionDenied() bool {
+	return e.Code == 10 || (e.Code >= 200 && e.Code <= 299)
+}
+
+// IsNotFound reports an object that does not exist or was deleted
+func (e *GraphError) IsNotFound() bool {
+	return (e.Code == 100 && e.Subcode == 33) || e.Code == 803
+}
+
+// IsTransient reports errors that are expected to succeed on retry
+func (e *GraphError) IsTransient() bool {
+	if e.Transient || e.Code == 1 || e.Code == 2 {
+		return true
+	}
+	return e.StatusCode >= http.StatusInternalServerError && !e.IsTokenExpired() && !e.IsPermissionDenied()
+}
+
+// AsGraphError unwraps err into a *GraphError if it is one
+func AsGraphError(err error) (*GraphError, bool) {
+	var graphErr *GraphError
+	if errors.As(err, &graphErr) {
+		return graphErr, true
+	}
+	return nil, false
+}
+
+// IsRateLimit reports whether err is a Graph API rate limit error
+func IsRateLimit(err error) bool {
+	graphErr, ok := AsGraphError(err)
+	return ok && graphErr.IsRateLimit()
+}
+
+// IsTokenExpired reports whether err is caused by an invalid access token
+func IsTokenExpired(err error) bool {
+	graphErr, ok := AsGraphError(err)
+	return ok && graphErr.IsTokenExpired()
+}
+
+// IsPermissionDenied reports whether err is caused by missing permissions
+func IsPermissionDenied(err error) bool {
+	graphErr, ok := AsGraphError(err)
+	return ok && graphErr.IsPermissionDenied()
+}
+
+// IsNotFound reports whether err is caused by a missing or deleted object
+func IsNotFound(err error) bool {
+	graphErr, ok := AsGraphError(err)
+	return ok && graphErr.IsNotFound()
+}
+
+// IsTransient reports whether err is a transient Graph API error
+func IsTransient(err error) bool {
+	graphErr, ok := AsGraphError(err)
+	return ok && graphErr.IsTransient()
+}
+
+// IsAmbiguous reports whether Facebook may have carried out a request that
+// returned err: timeouts, dropped connections, transient 5xx responses and
+// batch operations answered with null.
+// A publish that fails this way can already be live on the page.
+func IsAmbiguous(err error) bool {
+	if graphErr, ok := AsGraphError(err); ok {
+		return graphErr.IsTransient()
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, ErrOperationNotExecuted)
+}
+
+// parseGraphError returns a *GraphError when body carries an "error" object
+// or the status code is not 2xx, and nil otherwise
+func parseGraphError(statusCode int, body []byte) error {
+	var envelope struct {
+		Error *GraphError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err == nil && envelope.Error != nil {
+		envelope.Error.StatusCode = statusCode
+		return envelope.Error
+	}
+
+	if statusCode < 200 || statusCode >= 300 {
+		return &GraphError{
+			StatusCode: statusCode,
+			Message:    fmt.Sprintf("unexpected status %d: %s", statusCode, string(body)),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package facebook_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"fbscheduler/internal/facebook"
+	"fbscheduler/internal/facebook/fake"
+)
+
+func TestGraphErrorClassification(t *testing.T) {
+	tests := []struct {
+		name                          string
+		err                           *facebook.GraphError
+		rateLimit, token, perm, trans bool
+	}{
+		{"page rate limit", &facebook.GraphError{StatusCode: 400, Code: 32}, true, false, false, false},
+		{"business use case limit", &facebook.GraphError{StatusCode: 400, Code: 80001}, true, false, false, false},
+		{"expired token", &facebook.GraphError{StatusCode: 400, Code: 190, Subcode: 463}, false, true, false, false},
+		{"password changed", &facebook.GraphError{StatusCode: 400, Code: 100, Subcode: 460}, false, true, false, false},
+		{"missing permission", &facebook.GraphError{StatusCode: 403, Code: 200}, false, false, true, false},
+		{"transient flag", &facebook.GraphError{StatusCode: 400, Code: 368, Transient: true}, false, false, false, true},
+		{"server error", &facebook.GraphError{StatusCode: 500, Code: 1}, false, false, false, true},
+		{"invalid parameter", &facebook.GraphError{StatusCode: 400, Code: 100}, false, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("publish: %w", tt.err)
+			got := []bool{facebook.IsRateLimit(err), facebook.IsTokenExpired(err), facebook.IsPermissionDenied(err), facebook.IsTransient(err)}
+			want := []bool{tt.rateLimit, tt.token, tt.perm, tt.trans}
+			for i, name := range []string{"IsRateLimit", "IsTokenExpired", "IsPermissionDenied", "IsTransient"} {
+				if got[i] != want[i] {
+					t.Errorf("%s() = %v, want %v", name, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPublishGraphError(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.FailNext("feed", fake.Error{Status: 400, Code: 368, Message: "blocked from posting"})
+
+	_, err := client.Publish(context.Background(), facebook.PublishRequest{
+		PageID: testPageID, AccessToken: testPageToken, Message: "hello",
+	})
+	graphErr, ok := facebook.AsGraphError(err)
+	if !ok {
+		t.Fatalf("Publish() error = %v, want *GraphError", err)
+	}
+	if graphErr.Code != 368 || !strings.Contains(graphErr.Error(), "blocked") {
+		t.Errorf("GraphError = %+v, want code 368", graphErr)
+	}
+	if facebook.IsAmbiguous(err) {
+		t.Error("a rejected post must not be treated as ambiguous")
+	}
+}
@@ -0,0 +1,56 @@
+package fake
+
+import (
+	"strconv"
+	"time"
+
+	"fbscheduler/internal/facebook"
+)
+
+// PagePosts returns the posts published on a page through feed and photos,
+// oldest first
+func (s *Server) PagePosts(pageID string) []facebook.FeedPost {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]facebook.FeedPost(nil), s.pagePosts[pageID]...)
+}
+
+// recordPagePost keeps a post published right away (not scheduled) so that
+// {page}/posts can list it
+func (s *Server) recordPagePost(call Call, pageID, postID string) {
+	if call.Param("scheduled_publish_time") != "" || call.Param("published") == "false" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pagePosts[pageID] = append(s.pagePosts[pageID], facebook.FeedPost{
+		ID:          postID,
+		Message:     call.Param("message"),
+		CreatedTime: time.Now(),
+	})
+}
+
+// listPagePosts answers {page}/posts newest first, honouring since and limit
+func (s *Server) listPagePosts(call Call, pageID string) []map[string]string {
+	since, _ := strconv.ParseInt(call.Param("since"), 10, 64)
+	limit, err := strconv.Atoi(call.Param("limit"))
+	if err != nil || limit <= 0 {
+		limit = 25
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	posts := s.pagePosts[pageID]
+	data := make([]map[string]string, 0)
+	for i := len(posts) - 1; i >= 0 && len(data) < limit; i-- {
+		if posts[i].CreatedTime.Unix() < since {
+			continue
+		}
+		data = append(data, map[string]string{
+			"id":           posts[i].ID,
+			"message":      posts[i].Message,
+			"created_time": posts[i].CreatedTime.UTC().Format("2006-01-02T15:04:05-0700"),
+		})
+	}
+	return data
+}
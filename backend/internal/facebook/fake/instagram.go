@@ -0,0 +1,237 @@
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"fbscheduler/internal/facebook"
+)
+
+// InstagramMedia is a container created on an Instagram account, published
+// or not
+type InstagramMedia struct {
+	IGUserID  string
+	MediaType string // "" for a single image, CAROUSEL, REELS or VIDEO
+	Caption   string
+	ImageURL  string
+	VideoURL  string
+	Children  []string
+	Published bool
+
+	// pendingPolls is the number of status checks still answered IN_PROGRESS
+	pendingPolls int
+}
+
+// LinkInstagram links an Instagram Business account to a page added with
+// AddPage, so me/accounts and the page node report it
+func (s *Server) LinkInstagram(pageID, igUserID, username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.pages {
+		if s.pages[i].ID == pageID {
+			s.pages[i].InstagramBusinessAccount = &facebook.InstagramAccount{ID: igUserID, Username: username}
+		}
+	}
+}
+
+// SetContainerProcessing makes new Instagram containers report IN_PROGRESS
+// for the given number of status checks before FINISHED
+func (s *Server) SetContainerProcessing(polls int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.containerPolls = polls
+}
+
+// InstagramContainer returns a container by its ID
+func (s *Server) InstagramContainer(id string) (InstagramMedia, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	media, ok := s.igContainers[id]
+	if !ok {
+		return InstagramMedia{}, false
+	}
+	return *media, true
+}
+
+// InstagramPublished returns the container published as mediaID
+func (s *Server) InstagramPublished(mediaID string) (InstagramMedia, bool) {
+	s.mu.Lock()
+	containerID, ok := s.igPublished[mediaID]
+	s.mu.Unlock()
+	if !ok {
+		return InstagramMedia{}, false
+	}
+	return s.InstagramContainer(containerID)
+}
+
+// igHashtag is a hashtag findable by ig_hashtag_search
+type igHashtag struct {
+	name        string
+	topMedia    int
+	recentMedia int
+}
+
+// AddHashtag makes a hashtag findable by ig_hashtag_search with the given
+// number of top and recent media, and returns its ID
+func (s *Server) AddHashtag(name string, topMedia, recentMedia int) string {
+	id := s.newID()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hashtags[id] = &igHashtag{name: strings.ToLower(name), topMedia: topMedia, recentMedia: recentMedia}
+	return id
+}
+
+// HashtagSearches returns the hashtag names an Instagram account looked up
+// with ig_hashtag_search, in order
+func (s *Server) HashtagSearches(igUserID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.hashtagSearches[igUserID]...)
+}
+
+// instagramResponse answers the page's instagram_business_account field,
+// container creation, status checks, media_publish and hashtag search. ok is false for
+// requests it does not handle.
+func (s *Server) instagramResponse(call Call, node string, segments []string) (int, interface{}, bool) {
+	switch {
+	case call.Method == http.MethodGet && len(segments) == 1 && strings.Contains(call.Param("fields"), "instagram_business_account"):
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, page := range s.pages {
+			if page.ID == node {
+				return http.StatusOK, map[string]interface{}{"id": node, "instagram_business_account": page.InstagramBusinessAccount}, true
+			}
+		}
+		return http.StatusOK, map[string]interface{}{"id": node}, true
+
+	case call.Method == http.MethodGet && len(segments) == 1 && strings.Contains(call.Param("fields"), "status_code"):
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		media, ok := s.igContainers[node]
+		if !ok {
+			return http.StatusBadRequest, errorBody(Error{Code: 100, Subcode: 33, Type: "GraphMethodException",
+				Message: "Unsupported get request. Object with ID '" + node + "' does not exist"}), true
+		}
+		status := facebook.IGStatusFinished
+		switch {
+		case media.Published:
+			status = facebook.IGStatusPublished
+		case media.pendingPolls > 0:
+			media.pendingPolls--
+			status = facebook.IGStatusInProgress
+		}
+		return http.StatusOK, map[string]string{"id": node, "status_code": status, "status": status}, true
+
+	case call.Method == http.MethodPost && call.Edge == "media":
+		return s.createContainer(call, node)
+
+	case call.Method == http.MethodPost && call.Edge == "media_publish":
+		return s.publishContainer(call, node)
+
+	case call.Method == http.MethodGet && call.Path == "/ig_hashtag_search":
+		return s.searchHashtag(call)
+
+	case call.Method == http.MethodGet && len(segments) == 2 && (segments[1] == "top_media" || segments[1] == "recent_media"):
+		return s.hashtagMedia(node, segments[1])
+	}
+	return 0, nil, false
+}
+
+func (s *Server) createContainer(call Call, igUserID string) (int, interface{}, bool) {
+	media := &InstagramMedia{
+		IGUserID:  igUserID,
+		MediaType: call.Param("media_type"),
+		Caption:   call.Param("caption"),
+		ImageURL:  call.Param("image_url"),
+		VideoURL:  call.Param("video_url"),
+	}
+	if children := call.Param("children"); children != "" {
+		media.Children = strings.Split(children, ",")
+	}
+
+	invalid := func(message string) (int, interface{}, bool) {
+		return http.StatusBadRequest, errorBody(Error{Code: 100, Type: "OAuthException", Message: message}), true
+	}
+	switch media.MediaType {
+	case "":
+		if media.ImageURL == "" {
+			return invalid("image_url is required")
+		}
+	case facebook.IGMediaTypeReels, facebook.IGMediaTypeVideo:
+		if media.VideoURL == "" {
+			return invalid("video_url is required")
+		}
+	case facebook.IGMediaTypeCarousel:
+		if len(media.Children) < 2 || len(media.Children) > facebook.MaxCarouselItems {
+			return invalid("a carousel needs 2 to 10 children")
+		}
+	default:
+		return invalid("unsupported media_type " + media.MediaType)
+	}
+
+	id := s.newID()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, child := range media.Children {
+		if _, ok := s.igContainers[child]; !ok {
+			return invalid("unknown carousel child " + child)
+		}
+	}
+	media.pendingPolls = s.containerPolls
+	s.igContainers[id] = media
+	return http.StatusOK, map[string]string{"id": id}, true
+}
+
+func (s *Server) publishContainer(call Call, igUserID string) (int, interface{}, bool) {
+	containerID := call.Param("creation_id")
+	mediaID := s.newID()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	media, ok := s.igContainers[containerID]
+	switch {
+	case !ok || media.IGUserID != igUserID:
+		return http.StatusBadRequest, errorBody(Error{Code: 100, Type: "OAuthException", Message: "invalid creation_id"}), true
+	case media.Published:
+		return http.StatusBadRequest, errorBody(Error{Code: 9004, Type: "OAuthException", Message: "media already published"}), true
+	case media.pendingPolls > 0:
+		return http.StatusBadRequest, errorBody(Error{Code: 9007, Subcode: 2207027, Type: "OAuthException", Message: "Media ID is not available"}), true
+	}
+	media.Published = true
+	s.igPublished[mediaID] = containerID
+	return http.StatusOK, map[string]string{"id": mediaID}, true
+}
+
+func (s *Server) searchHashtag(call Call) (int, interface{}, bool) {
+	igUserID, name := call.Param("user_id"), strings.ToLower(call.Param("q"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hashtagSearches[igUserID] = append(s.hashtagSearches[igUserID], name)
+	data := make([]map[string]string, 0, 1)
+	for id, hashtag := range s.hashtags {
+		if hashtag.name == name {
+			data = append(data, map[string]string{"id": id})
+		}
+	}
+	return http.StatusOK, map[string]interface{}{"data": data}, true
+}
+
+func (s *Server) hashtagMedia(hashtagID, edge string) (int, interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hashtag, ok := s.hashtags[hashtagID]
+	if !ok {
+		return 0, nil, false
+	}
+	count := hashtag.topMedia
+	if edge == "recent_media" {
+		count = hashtag.recentMedia
+	}
+	data := make([]map[string]string, 0, count)
+	for i := 0; i < count; i++ {
+		data = append(data, map[string]string{"id": fmt.Sprintf("%s_%s_%d", hashtagID, edge, i)})
+	}
+	return http.StatusOK, map[string]interface{}{"data": data}, true
+}
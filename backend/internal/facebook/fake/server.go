@@ -0,0 +1,1124 @@
+// Package fake runs an in-process stand-in for the Facebook Graph API.
+//
+// It answers the endpoints facebook.Client uses (feed, photos, videos
+// including resumable upload sessions, video_reels, photo_stories and
+// video_stories with their rupload endpoint, albums, oauth/access_token
+// including the long-lived token exchange, debug_token, me, me/accounts, posts scheduled with scheduled_publish_time, which
+// can be read back, listed, rescheduled and deleted, edits and deletes of published
+// posts, the posts published on a page, post insights, page webhook subscriptions (subscribed_apps), place and pages search, Instagram containers and media_publish, and batch requests, whose
+// operations are recorded like separate calls), records every call, can
+// report throttling through the usage headers, can
+// enforce appsecret_proof and can be scripted to fail, so PublishPost and the scheduler can be exercised
+// end-to-end without network access:
+//
+//	srv := fake.NewServer()
+//	defer srv.Close()
+//	srv.AddPage("123", "Test Page", "page-token")
+//	srv.FailNext("feed", fake.Error{Status: 400, Code: 368, Message: "blocked"})
+//	client := srv.Client()
+package fake
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fbscheduler/internal/facebook"
+)
+
+// mediaPrefix is where AddMedia serves downloadable files from
+const mediaPrefix = "/_media/"
+
+// reelUploadPrefix stands in for rupload.facebook.com/video-upload
+const reelUploadPrefix = "/video-upload/"
+
+var versionSegment = regexp.MustCompile(`^v\d+\.\d+$`)
+
+// DefaultChunkSize is the transfer chunk size handed out by upload sessions
+const DefaultChunkSize = 1 << 20
+
+// Call is one recorded Graph API request
+type Call struct {
+	Method string
+	// Path without the version prefix, e.g. "/123/feed"
+	Path   string
+	Edge   string
+	Query  url.Values
+	Form   url.Values
+	Files  map[string][]byte
+	Header http.Header
+	// Body is the raw request body of binary uploads (application/octet-stream)
+	Body []byte
+}
+
+// Param returns a parameter from the form body, falling back to the query
+func (c Call) Param(key string) string {
+	if v := c.Form.Get(key); v != "" {
+		return v
+	}
+	return c.Query.Get(key)
+}
+
+// AccessToken returns the token sent in the Authorization header
+// ("OAuth" or "Bearer"), falling back to the access_token parameter
+func (c Call) AccessToken() string {
+	auth := c.Header.Get("Authorization")
+	for _, scheme := range []string{"OAuth ", "Bearer "} {
+		if strings.HasPrefix(auth, scheme) {
+			return strings.TrimPrefix(auth, scheme)
+		}
+	}
+	return c.Param("access_token")
+}
+
+// Error is a scripted Graph API error response
+type Error struct {
+	Status      int
+	Code        int
+	Subcode     int
+	Type        string
+	Message     string
+	IsTransient bool
+	// Committed carries the request out before answering with the error,
+	// like a timeout after Facebook created the post
+	Committed bool
+}
+
+// Page is a page returned by me/accounts
+type Page struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	AccessToken string   `json:"access_token"`
+	Category    string   `json:"category"`
+	Tasks       []string `json:"tasks"`
+
+	InstagramBusinessAccount *facebook.InstagramAccount `json:"instagram_business_account,omitempty"`
+}
+
+// HandlerFunc overrides the default response for an edge. It returns the
+// status code and a JSON-encodable body.
+type HandlerFunc func(call Call) (int, interface{})
+
+// Server is a fake Graph API backed by httptest.Server
+type Server struct {
+	URL string
+
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	calls    []Call
+	failures map[string][]Error
+	handlers map[string]HandlerFunc
+	pages    []Page
+	media    map[string][]byte
+	nextID   int64
+
+	chunkSize int64
+	sessions  map[string]*uploadSession
+	videos    map[string][]byte
+	ruploads  map[string][]byte
+
+	scheduled map[string]*scheduledObject
+	// scheduledOrder lists scheduled IDs in creation order
+	scheduledOrder []string
+	revoked        map[string]bool
+
+	// appSecret, when set, makes every call with a token require a
+	// matching appsecret_proof ("Require App Secret")
+	appSecret string
+
+	// usageHeaders are sent with every response, including batch operations
+	usageHeaders http.Header
+
+	insights map[string]facebook.PostInsights
+
+	// edited and deleted track POST and DELETE on published objects
+	edited  map[string]string
+	deleted map[string]bool
+
+	// subscriptions are the subscribed_fields per page from subscribed_apps
+	subscriptions map[string][]string
+
+	// places are returned by search?type=place and pages/search
+	places []facebook.Place
+
+	// igContainers are Instagram media containers by ID, igPublished maps
+	// published media IDs to their container
+	igContainers   map[string]*InstagramMedia
+	igPublished    map[string]string
+	containerPolls int
+
+	// hashtags are Instagram hashtags by ID; hashtagSearches lists the names
+	// each Instagram account looked up with ig_hashtag_search
+	hashtags        map[string]*igHashtag
+	hashtagSearches map[string][]string
+
+	// pagePosts are the posts published on each page, read back from
+	// {page}/posts
+	pagePosts map[string][]facebook.FeedPost
+}
+
+// Token lifetimes reported by oauth/access_token and debug_token
+const (
+	longLivedTokenPrefix = "fake-long-lived-token-"
+	shortLivedTokenTTL   = 2 * time.Hour
+	longLivedTokenTTL    = 60 * 24 * time.Hour
+)
+
+// DefaultScopes are the permissions debug_token reports for every token
+var DefaultScopes = []string{"pages_show_list", "pages_read_engagement", "pages_manage_posts", "pages_manage_metadata", "read_insights", "business_management", "instagram_basic", "instagram_content_publish"}
+
+// scheduledObject is a post created with scheduled_publish_time
+type scheduledObject struct {
+	pageID    string
+	message   string
+	publishAt int64
+	published bool
+	deleted   bool
+}
+
+// uploadSession tracks one resumable video upload
+type uploadSession struct {
+	videoID string
+	size    int64
+	data    []byte
+}
+
+// NewServer starts a fake Graph API server
+func NewServer() *Server {
+	s := &Server{
+		failures: make(map[string][]Error),
+		handlers: make(map[string]HandlerFunc),
+		media:    make(map[string][]byte),
+		nextID:   1000,
+
+		chunkSize: DefaultChunkSize,
+		sessions:  make(map[string]*uploadSession),
+		videos:    make(map[string][]byte),
+		ruploads:  make(map[string][]byte),
+		scheduled: make(map[string]*scheduledObject),
+		revoked:   make(map[string]bool),
+
+		usageHeaders: http.Header{},
+		insights:     make(map[string]facebook.PostInsights),
+		edited:       make(map[string]string),
+		deleted:      make(map[string]bool),
+
+		subscriptions: make(map[string][]string),
+
+		igContainers: make(map[string]*InstagramMedia),
+		igPublished:  make(map[string]string),
+
+		hashtags:        make(map[string]*igHashtag),
+		hashtagSearches: make(map[string][]string),
+
+		pagePosts: make(map[string][]facebook.FeedPost),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns a facebook.Client wired to this server
+func (s *Server) Client(opts ...facebook.Option) *facebook.Client {
+	opts = append([]facebook.Option{
+		facebook.WithBaseURL(s.URL),
+		facebook.WithHTTPClient(s.srv.Client()),
+	}, opts...)
+	return facebook.NewClient(opts...)
+}
+
+// AddPage registers a page returned by me/accounts
+func (s *Server) AddPage(id, name, accessToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages = append(s.pages, Page{
+		ID:          id,
+		Name:        name,
+		AccessToken: accessToken,
+		Category:    "Brand",
+		Tasks:       []string{"ANALYZE", "ADVERTISE", "MODERATE", "CREATE_CONTENT", "MANAGE"},
+	})
+}
+
+// AddMedia serves data at a URL that the client can download as media
+func (s *Server) AddMedia(name string, data []byte) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.media[name] = data
+	return s.URL + mediaPrefix + name
+}
+
+// RequireAppSecret rejects calls whose appsecret_proof does not match the
+// token signed with secret, like an app with "Require App Secret" enabled
+func (s *Server) RequireAppSecret(secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.appSecret = secret
+}
+
+// SetUsage sends a usage header (facebook.HeaderAppUsage, HeaderPageUsage
+// or HeaderBusinessUseCaseUsage) with every response; an empty value
+// removes it
+func (s *Server) SetUsage(header, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if value == "" {
+		s.usageHeaders.Del(header)
+		return
+	}
+	s.usageHeaders.Set(header, value)
+}
+
+// SetInsights sets the metrics returned for a post; posts without insights
+// report zeros
+func (s *Server) SetInsights(postID string, insights facebook.PostInsights) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.insights[postID] = insights
+}
+
+// EditedMessage returns the message set on a post by an edit
+func (s *Server) EditedMessage(id string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg, ok := s.edited[id]
+	return msg, ok
+}
+
+// Deleted reports whether a published object was deleted
+func (s *Server) Deleted(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.deleted[id]
+}
+
+// Subscriptions returns the webhook fields the app subscribed the page to
+func (s *Server) Subscriptions(pageID string) ([]string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fields, ok := s.subscriptions[pageID]
+	return fields, ok
+}
+
+// AddPlace makes a place findable by search?type=place and pages/search
+func (s *Server) AddPlace(place facebook.Place) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.places = append(s.places, place)
+}
+
+// RevokeToken makes debug_token report token as invalid
+func (s *Server) RevokeToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revoked[token] = true
+}
+
+// FailNext makes the next request to edge (e.g. "feed", "photos",
+// "access_token") fail with e. Calls queue up in order.
+func (s *Server) FailNext(edge string, e Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e.Status == 0 {
+		e.Status = http.StatusBadRequest
+	}
+	if e.Type == "" {
+		e.Type = "OAuthException"
+	}
+	s.failures[edge] = append(s.failures[edge], e)
+}
+
+// SetChunkSize changes the chunk size requested by upload sessions
+func (s *Server) SetChunkSize(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chunkSize = n
+}
+
+// Video returns the bytes of a finished resumable upload
+func (s *Server) Video(videoID string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.videos[videoID]
+	return data, ok
+}
+
+// Reel returns the bytes of a finished reel or video story upload
+func (s *Server) Reel(videoID string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.ruploads[videoID]
+	return data, ok
+}
+
+// PublishScheduled makes a post created with scheduled_publish_time go live
+// immediately. It reports false for unknown IDs.
+func (s *Server) PublishScheduled(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.scheduled[id]
+	if ok {
+		obj.published = true
+	}
+	return ok
+}
+
+// ScheduledAt returns the current scheduled_publish_time of a post
+func (s *Server) ScheduledAt(id string) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.scheduled[id]
+	if !ok || obj.deleted {
+		return 0, false
+	}
+	return obj.publishAt, true
+}
+
+// Handle overrides the default response for every request to edge
+func (s *Server) Handle(edge string, fn HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[edge] = fn
+}
+
+// Calls returns every recorded call in order
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// CallsTo returns the recorded calls to one edge
+func (s *Server) CallsTo(edge string) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Call
+	for _, c := range s.calls {
+		if c.Edge == edge {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Reset clears recorded calls and pending failures
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = nil
+	s.failures = make(map[string][]Error)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, mediaPrefix) {
+		s.serveMedia(w, r)
+		return
+	}
+
+	call, err := readCall(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorBody(Error{Code: 100, Type: "GraphMethodException", Message: err.Error()}))
+		return
+	}
+
+	status, body := s.dispatch(call)
+	s.mu.Lock()
+	for key, values := range s.usageHeaders {
+		w.Header()[key] = values
+	}
+	s.mu.Unlock()
+	writeJSON(w, status, body)
+}
+
+// dispatch records a call and answers it: app secret check, scripted
+// failures, custom handlers, then the default response. Batch operations
+// go through it one by one.
+func (s *Server) dispatch(call Call) (int, interface{}) {
+	s.mu.Lock()
+	s.calls = append(s.calls, call)
+	appSecret := s.appSecret
+	var failure *Error
+	if queue := s.failures[call.Edge]; len(queue) > 0 {
+		next := queue[0]
+		failure = &next
+		s.failures[call.Edge] = queue[1:]
+	}
+	handler := s.handlers[call.Edge]
+	s.mu.Unlock()
+
+	if token := call.AccessToken(); appSecret != "" && token != "" &&
+		call.Param("appsecret_proof") != facebook.AppSecretProof(token, appSecret) {
+		return http.StatusBadRequest, errorBody(Error{Code: 100, Type: "GraphMethodException",
+			Message: "Invalid appsecret_proof provided in the API argument"})
+	}
+	if failure != nil {
+		if failure.Committed {
+			s.answer(call, handler)
+		}
+		return failure.Status, errorBody(*failure)
+	}
+	return s.answer(call, handler)
+}
+
+// answer runs the custom handler of the edge, or the default response
+func (s *Server) answer(call Call, handler HandlerFunc) (int, interface{}) {
+	if handler != nil {
+		return handler(call)
+	}
+	return s.defaultResponse(call)
+}
+
+func (s *Server) serveMedia(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, mediaPrefix)
+	s.mu.Lock()
+	data, ok := s.media[name]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	// Content-Type comes from the extension or is sniffed; HEAD is answered too
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(data))
+}
+
+// defaultResponse mimics the shape of real Graph API responses
+func (s *Server) defaultResponse(call Call) (int, interface{}) {
+	segments := strings.Split(strings.Trim(call.Path, "/"), "/")
+	node := segments[0]
+
+	if len(segments) == 1 {
+		if status, body, ok := s.scheduledObjectResponse(call, node); ok {
+			return status, body
+		}
+		if status, body, ok := s.publishedObjectResponse(call, node); ok {
+			return status, body
+		}
+	}
+
+	if status, body, ok := s.instagramResponse(call, node, segments); ok {
+		return status, body
+	}
+
+	switch {
+	case call.Method == http.MethodPost && call.Edge == "batch":
+		return s.serveBatch(call)
+
+	case call.Method == http.MethodDelete:
+		return http.StatusOK, map[string]bool{"success": true}
+
+	case call.Path == "/oauth/access_token" && call.Param("grant_type") == "fb_exchange_token":
+		return http.StatusOK, map[string]interface{}{
+			"access_token": longLivedTokenPrefix + s.newID(),
+			"token_type":   "bearer",
+			"expires_in":   int64(longLivedTokenTTL / time.Second),
+		}
+
+	case call.Path == "/oauth/access_token":
+		return http.StatusOK, map[string]interface{}{
+			"access_token": "fake-user-token-" + s.newID(),
+			"token_type":   "bearer",
+			"expires_in":   int64(shortLivedTokenTTL / time.Second),
+		}
+
+	case call.Path == "/debug_token":
+		return http.StatusOK, map[string]interface{}{"data": s.debugToken(call.Param("input_token"))}
+
+	case call.Method == http.MethodGet && len(segments) == 1 && strings.Contains(call.Param("fields"), "insights"):
+		return http.StatusOK, s.postInsights(node)
+
+	case call.Method == http.MethodGet && len(segments) == 1 && call.Param("fields") == "status":
+		if status, ok := s.ruploadStatus(node); ok {
+			return http.StatusOK, status
+		}
+		return http.StatusOK, map[string]string{"id": node}
+
+	case call.Method == http.MethodGet && len(segments) == 1 && strings.Contains(call.Param("fields"), "created_time"):
+		return http.StatusOK, objectDetails(call, node)
+
+	case call.Method == http.MethodGet && (call.Path == "/search" || call.Path == "/pages/search"):
+		return http.StatusOK, map[string]interface{}{"data": s.searchPlaces(call.Param("q"))}
+
+	case call.Path == "/me":
+		return http.StatusOK, map[string]interface{}{
+			"id":   "fake-user",
+			"name": "Fake User",
+			"picture": map[string]interface{}{
+				"data": map[string]string{"url": s.URL + mediaPrefix + "avatar.jpg"},
+			},
+		}
+
+	case call.Path == "/me/accounts":
+		s.mu.Lock()
+		pages := append([]Page{}, s.pages...)
+		s.mu.Unlock()
+		return http.StatusOK, map[string]interface{}{"data": pages}
+
+	case call.Method == http.MethodPost && call.Edge == "subscribed_apps":
+		s.mu.Lock()
+		s.subscriptions[node] = strings.Split(call.Param("subscribed_fields"), ",")
+		s.mu.Unlock()
+		return http.StatusOK, map[string]bool{"success": true}
+
+	case call.Method == http.MethodPost && call.Edge == "feed":
+		id := node + "_" + s.newID()
+		s.trackScheduled(call, id)
+		s.recordPagePost(call, node, id)
+		return http.StatusOK, map[string]string{"id": id}
+
+	case call.Method == http.MethodGet && call.Edge == "posts":
+		return http.StatusOK, map[string]interface{}{"data": s.listPagePosts(call, node)}
+
+	case call.Method == http.MethodGet && call.Edge == "scheduled_posts":
+		return http.StatusOK, map[string]interface{}{"data": s.listScheduledPosts(node)}
+
+	case call.Method == http.MethodPost && call.Edge == "videos" && call.Param("upload_phase") != "":
+		return s.resumableUpload(call)
+
+	case call.Method == http.MethodPost && (call.Edge == "video_reels" || call.Edge == "video_stories"):
+		return s.reelUpload(call, node)
+
+	case call.Method == http.MethodPost && call.Edge == "photo_stories":
+		if call.Param("photo_id") == "" {
+			return http.StatusBadRequest, errorBody(Error{Code: 100, Type: "OAuthException", Message: "photo_id is required"})
+		}
+		return http.StatusOK, map[string]interface{}{"success": true, "post_id": s.newID()}
+
+	case call.Method == http.MethodPost && call.Edge == "video-upload":
+		return s.reelTransfer(call)
+
+	case call.Method == http.MethodPost && call.Edge == "photos":
+		id := s.newID()
+		if call.Param("published") == "false" {
+			s.trackScheduled(call, id)
+			return http.StatusOK, map[string]string{"id": id}
+		}
+		s.recordPagePost(call, node, node+"_"+id)
+		return http.StatusOK, map[string]string{"id": id, "post_id": node + "_" + id}
+
+	case call.Method == http.MethodPost:
+		return http.StatusOK, map[string]string{"id": s.newID()}
+
+	default:
+		return http.StatusOK, map[string]string{"id": node}
+	}
+}
+
+// resumableUpload implements the start / transfer / finish video phases
+func (s *Server) resumableUpload(call Call) (int, interface{}) {
+	switch call.Param("upload_phase") {
+	case "start":
+		size, err := strconv.ParseInt(call.Param("file_size"), 10, 64)
+		if err != nil || size <= 0 {
+			return http.StatusBadRequest, errorBody(Error{Code: 100, Type: "OAuthException", Message: "invalid file_size"})
+		}
+		videoID, sessionID := s.newID(), s.newID()
+		s.mu.Lock()
+		s.sessions[sessionID] = &uploadSession{videoID: videoID, size: size}
+		end := min(s.chunkSize, size)
+		s.mu.Unlock()
+		return http.StatusOK, map[string]string{
+			"video_id":          videoID,
+			"upload_session_id": sessionID,
+			"start_offset":      "0",
+			"end_offset":        strconv.FormatInt(end, 10),
+		}
+
+	case "transfer":
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		session, ok := s.sessions[call.Param("upload_session_id")]
+		if !ok {
+			return http.StatusBadRequest, errorBody(Error{Code: 100, Type: "OAuthException", Message: "unknown upload session"})
+		}
+		start, _ := strconv.ParseInt(call.Param("start_offset"), 10, 64)
+		if start != int64(len(session.data)) {
+			return http.StatusBadRequest, errorBody(Error{Code: 6001, Subcode: 1363037, Type: "OAuthException", Message: "unexpected start_offset"})
+		}
+		session.data = append(session.data, call.Files["video_file_chunk"]...)
+		next := int64(len(session.data))
+		return http.StatusOK, map[string]string{
+			"start_offset": strconv.FormatInt(next, 10),
+			"end_offset":   strconv.FormatInt(min(next+s.chunkSize, session.size), 10),
+		}
+
+	case "finish":
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		sessionID := call.Param("upload_session_id")
+		session, ok := s.sessions[sessionID]
+		if !ok || int64(len(session.data)) != session.size {
+			return http.StatusBadRequest, errorBody(Error{Code: 6001, Type: "OAuthException", Message: "upload incomplete"})
+		}
+		s.videos[session.videoID] = session.data
+		delete(s.sessions, sessionID)
+		s.trackScheduledLocked(call, session.videoID)
+		return http.StatusOK, map[string]bool{"success": true}
+	}
+
+	return http.StatusBadRequest, errorBody(Error{Code: 100, Type: "OAuthException", Message: "invalid upload_phase"})
+}
+
+// reelUpload implements the start / finish phases of page video_reels
+// and video_stories
+func (s *Server) reelUpload(call Call, pageID string) (int, interface{}) {
+	switch call.Param("upload_phase") {
+	case "start":
+		videoID := s.newID()
+		s.mu.Lock()
+		s.sessions[videoID] = &uploadSession{videoID: videoID}
+		s.mu.Unlock()
+		return http.StatusOK, map[string]string{
+			"video_id":   videoID,
+			"upload_url": s.URL + reelUploadPrefix + "v18.0/" + videoID,
+		}
+
+	case "finish":
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		videoID := call.Param("video_id")
+		session, ok := s.sessions[videoID]
+		if !ok || session.size == 0 || int64(len(session.data)) != session.size {
+			return http.StatusBadRequest, errorBody(Error{Code: 6000, Type: "OAuthException", Message: "reel upload incomplete"})
+		}
+		s.ruploads[videoID] = session.data
+		delete(s.sessions, videoID)
+		s.trackScheduledLocked(call, pageID+"_"+videoID)
+		return http.StatusOK, map[string]interface{}{"success": true, "post_id": pageID + "_" + videoID}
+	}
+
+	return http.StatusBadRequest, errorBody(Error{Code: 100, Type: "OAuthException", Message: "invalid upload_phase"})
+}
+
+// reelTransfer receives the raw reel bytes sent to the upload_url
+func (s *Server) reelTransfer(call Call) (int, interface{}) {
+	if call.AccessToken() == "" {
+		return http.StatusUnauthorized, errorBody(Error{Code: 190, Type: "OAuthException", Message: "missing OAuth authorization header"})
+	}
+	segments := strings.Split(strings.Trim(call.Path, "/"), "/")
+	videoID := segments[len(segments)-1]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[videoID]
+	if !ok {
+		return http.StatusBadRequest, errorBody(Error{Code: 100, Type: "OAuthException", Message: "unknown video_id"})
+	}
+	size, err := strconv.ParseInt(call.Header.Get("file_size"), 10, 64)
+	if err != nil || size <= 0 || (session.size != 0 && size != session.size) {
+		return http.StatusBadRequest, errorBody(Error{Code: 100, Type: "OAuthException", Message: "invalid file_size"})
+	}
+	offset, _ := strconv.ParseInt(call.Header.Get("offset"), 10, 64)
+	if offset != int64(len(session.data)) || offset+int64(len(call.Body)) > size {
+		return http.StatusBadRequest, errorBody(Error{Code: 100, Type: "OAuthException", Message: "offset does not match the bytes received"})
+	}
+	session.size = size
+	session.data = append(session.data, call.Body...)
+	return http.StatusOK, map[string]bool{"success": true}
+}
+
+// ruploadStatus answers {video}?fields=status with the bytes received so
+// far by a reel or story upload
+func (s *Server) ruploadStatus(videoID string) (map[string]interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[videoID]
+	if !ok {
+		return nil, false
+	}
+	return map[string]interface{}{
+		"id": videoID,
+		"status": map[string]interface{}{
+			"video_status": "upload",
+			"uploading_phase": map[string]interface{}{
+				"status":            "in_progress",
+				"bytes_transferred": len(session.data),
+			},
+		},
+	}, true
+}
+
+// batchResultRef is a JSONPath reference to an earlier operation's id
+var batchResultRef = regexp.MustCompile(`\{result=([A-Za-z0-9_]+):\$\.id\}`)
+
+// serveBatch runs the operations of a batch request in order. References
+// to earlier named operations are resolved; an operation whose dependency
+// failed is answered with null, as Facebook does.
+func (s *Server) serveBatch(call Call) (int, interface{}) {
+	var ops []struct {
+		Method        string `json:"method"`
+		RelativeURL   string `json:"relative_url"`
+		Name          string `json:"name"`
+		Body          string `json:"body"`
+		AttachedFiles string `json:"attached_files"`
+	}
+	if err := json.Unmarshal([]byte(call.Param("batch")), &ops); err != nil || len(ops) == 0 {
+		return http.StatusBadRequest, errorBody(Error{Code: 100, Type: "GraphBatchException", Message: "The parameter batch is required and must be a JSON array"})
+	}
+	if len(ops) > facebook.MaxBatchSize {
+		return http.StatusBadRequest, errorBody(Error{Code: 1, Type: "GraphBatchException",
+			Message: fmt.Sprintf("Too many requests in batch message. Maximum batch size is %d", facebook.MaxBatchSize)})
+	}
+
+	ids := make(map[string]string)
+	responses := make([]interface{}, len(ops))
+	for i, op := range ops {
+		resolved := true
+		body := batchResultRef.ReplaceAllStringFunc(op.Body, func(ref string) string {
+			id, ok := ids[batchResultRef.FindStringSubmatch(ref)[1]]
+			resolved = resolved && ok
+			return url.QueryEscape(id)
+		})
+		if !resolved {
+			continue
+		}
+
+		target, err := url.Parse("/" + strings.TrimPrefix(op.RelativeURL, "/"))
+		if err != nil {
+			return http.StatusBadRequest, errorBody(Error{Code: 100, Type: "GraphBatchException", Message: "invalid relative_url"})
+		}
+		form, _ := url.ParseQuery(body)
+		path, edge := splitPath(target.Path)
+		sub := Call{
+			Method: strings.ToUpper(op.Method),
+			Path:   path,
+			Edge:   edge,
+			Query:  target.Query(),
+			Form:   form,
+			Files:  make(map[string][]byte),
+			Header: http.Header{},
+		}
+		if op.AttachedFiles != "" {
+			sub.Files["source"] = call.Files[op.AttachedFiles]
+		}
+
+		status, respBody := s.dispatch(sub)
+		data, _ := json.Marshal(respBody)
+		if op.Name != "" && status >= 200 && status < 300 {
+			var created struct {
+				ID string `json:"id"`
+			}
+			json.Unmarshal(data, &created)
+			ids[op.Name] = created.ID
+		}
+		response := map[string]interface{}{"code": status, "body": string(data)}
+		if call.Param("include_headers") != "false" {
+			response["headers"] = s.batchHeaders()
+		}
+		responses[i] = response
+	}
+	return http.StatusOK, responses
+}
+
+// batchHeaders lists the usage headers in the batch response format
+func (s *Server) batchHeaders() []map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	headers := []map[string]string{{"name": "Content-Type", "value": "application/json"}}
+	for key, values := range s.usageHeaders {
+		headers = append(headers, map[string]string{"name": key, "value": values[0]})
+	}
+	return headers
+}
+
+// trackScheduled remembers id when call carries scheduled_publish_time
+func (s *Server) trackScheduled(call Call, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trackScheduledLocked(call, id)
+}
+
+func (s *Server) trackScheduledLocked(call Call, id string) {
+	publishAt, err := strconv.ParseInt(call.Param("scheduled_publish_time"), 10, 64)
+	if err != nil {
+		return
+	}
+	message := call.Param("message")
+	if message == "" {
+		message = call.Param("description")
+	}
+	pageID := strings.Split(strings.Trim(call.Path, "/"), "/")[0]
+	s.scheduled[id] = &scheduledObject{pageID: pageID, message: message, publishAt: publishAt}
+	s.scheduledOrder = append(s.scheduledOrder, id)
+}
+
+// listScheduledPosts answers {page}/scheduled_posts with the page's
+// scheduled posts that are neither published nor deleted, newest first
+func (s *Server) listScheduledPosts(pageID string) []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().Unix()
+	data := make([]map[string]interface{}, 0)
+	for i := len(s.scheduledOrder) - 1; i >= 0; i-- {
+		id := s.scheduledOrder[i]
+		obj := s.scheduled[id]
+		if obj.pageID != pageID || obj.published || obj.deleted || now >= obj.publishAt {
+			continue
+		}
+		data = append(data, map[string]interface{}{
+			"id":                     id,
+			"message":                obj.message,
+			"scheduled_publish_time": obj.publishAt,
+		})
+	}
+	return data
+}
+
+// scheduledObjectResponse answers GET, POST (reschedule) and DELETE on a
+// tracked scheduled post. ok is false for untracked IDs.
+func (s *Server) scheduledObjectResponse(call Call, id string) (int, interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, tracked := s.scheduled[id]
+	if !tracked {
+		return 0, nil, false
+	}
+	if obj.deleted {
+		return http.StatusBadRequest, errorBody(Error{Code: 100, Subcode: 33, Type: "GraphMethodException",
+			Message: "Unsupported get request. Object with ID '" + id + "' does not exist"}), true
+	}
+
+	switch call.Method {
+	case http.MethodDelete:
+		obj.deleted = true
+		return http.StatusOK, map[string]bool{"success": true}, true
+
+	case http.MethodPost:
+		if v := call.Param("scheduled_publish_time"); v != "" {
+			publishAt, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return http.StatusBadRequest, errorBody(Error{Code: 100, Type: "OAuthException", Message: "invalid scheduled_publish_time"}), true
+			}
+			obj.publishAt = publishAt
+		}
+		return http.StatusOK, map[string]bool{"success": true}, true
+	}
+
+	published := obj.published || time.Now().Unix() >= obj.publishAt
+	return http.StatusOK, map[string]interface{}{
+		"id":                     id,
+		"is_published":           published,
+		"published":              published,
+		"scheduled_publish_time": obj.publishAt,
+	}, true
+}
+
+// publishedObjectResponse answers edits (POST with message) and deletes of
+// a published object, and reports deleted objects as missing. ok is false
+// for requests it does not handle.
+func (s *Server) publishedObjectResponse(call Call, id string) (int, interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.deleted[id] {
+		return http.StatusBadRequest, errorBody(Error{Code: 100, Subcode: 33, Type: "GraphMethodException",
+			Message: "Unsupported request. Object with ID '" + id + "' does not exist"}), true
+	}
+
+	switch {
+	case call.Method == http.MethodDelete:
+		s.deleted[id] = true
+		return http.StatusOK, map[string]bool{"success": true}, true
+	case call.Method == http.MethodPost && call.Param("message") != "":
+		s.edited[id] = call.Param("message")
+		return http.StatusOK, map[string]bool{"success": true}, true
+	case call.Method == http.MethodPost && call.Param("description") != "":
+		s.edited[id] = call.Param("description")
+		return http.StatusOK, map[string]bool{"success": true}, true
+	}
+	return 0, nil, false
+}
+
+// objectDetails answers facebook.GetPublishedPost with a permalink under
+// facebook.com and the current time as created_time
+func objectDetails(call Call, id string) map[string]string {
+	details := map[string]string{
+		"id":           id,
+		"created_time": time.Now().UTC().Format("2006-01-02T15:04:05-0700"),
+	}
+	field := "permalink_url"
+	if strings.Contains(call.Param("fields"), "link") && !strings.Contains(call.Param("fields"), field) {
+		field = "link"
+	}
+	details[field] = "https://www.facebook.com/" + strings.Replace(id, "_", "/posts/", 1)
+	return details
+}
+
+// postInsights answers the fields requested by facebook.GetPostInsights
+func (s *Server) postInsights(postID string) map[string]interface{} {
+	s.mu.Lock()
+	in := s.insights[postID]
+	s.mu.Unlock()
+
+	metric := func(name string, value int64) map[string]interface{} {
+		return map[string]interface{}{
+			"name":   name,
+			"period": "lifetime",
+			"values": []map[string]int64{{"value": value}},
+		}
+	}
+	return map[string]interface{}{
+		"id":        postID,
+		"shares":    map[string]int64{"count": in.Shares},
+		"reactions": map[string]interface{}{"data": []interface{}{}, "summary": map[string]int64{"total_count": in.Reactions}},
+		"comments":  map[string]interface{}{"data": []interface{}{}, "summary": map[string]int64{"total_count": in.Comments}},
+		"insights": map[string]interface{}{"data": []interface{}{
+			metric(facebook.MetricImpressions, in.Impressions),
+			metric(facebook.MetricReach, in.Reach),
+			metric(facebook.MetricClicks, in.Clicks),
+		}},
+	}
+}
+
+// searchPlaces returns the places whose name contains q (case-insensitive);
+// an empty q matches every place
+func (s *Server) searchPlaces(q string) []facebook.Place {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	places := make([]facebook.Place, 0)
+	for _, place := range s.places {
+		if strings.Contains(strings.ToLower(place.Name), strings.ToLower(q)) {
+			places = append(places, place)
+		}
+	}
+	return places
+}
+
+// debugToken describes a token the way debug_token does: page tokens never
+// expire, long-lived user tokens last 60 days, anything else 2 hours
+func (s *Server) debugToken(token string) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := map[string]interface{}{
+		"app_id":                 "fake-app",
+		"type":                   "USER",
+		"user_id":                "fake-user",
+		"is_valid":               !s.revoked[token],
+		"expires_at":             time.Now().Add(shortLivedTokenTTL).Unix(),
+		"data_access_expires_at": time.Now().Add(90 * 24 * time.Hour).Unix(),
+		"scopes":                 DefaultScopes,
+	}
+	for _, page := range s.pages {
+		if page.AccessToken == token {
+			data["type"] = "PAGE"
+			data["profile_id"] = page.ID
+			data["expires_at"] = 0
+			return data
+		}
+	}
+	if strings.HasPrefix(token, longLivedTokenPrefix) {
+		data["expires_at"] = time.Now().Add(longLivedTokenTTL).Unix()
+	}
+	return data
+}
+
+func (s *Server) newID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	return fmt.Sprintf("%d", s.nextID)
+}
+
+// readCall parses a request into a Call, including multipart uploads
+func readCall(r *http.Request) (Call, error) {
+	path, edge := splitPath(r.URL.Path)
+	call := Call{
+		Method: r.Method,
+		Path:   path,
+		Edge:   edge,
+		Query:  r.URL.Query(),
+		Form:   url.Values{},
+		Files:  make(map[string][]byte),
+		Header: r.Header.Clone(),
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, "multipart/form-data"):
+		if err := r.ParseMultipartForm(64 << 20); err != nil {
+			return call, err
+		}
+		for key, values := range r.MultipartForm.Value {
+			call.Form[key] = values
+		}
+		for key, headers := range r.MultipartForm.File {
+			f, err := headers[0].Open()
+			if err != nil {
+				return call, err
+			}
+			data, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				return call, err
+			}
+			call.Files[key] = data
+		}
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		if err := r.ParseForm(); err != nil {
+			return call, err
+		}
+		call.Form = r.PostForm
+	case strings.HasPrefix(contentType, "application/octet-stream"):
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return call, err
+		}
+		call.Body = body
+	}
+
+	return call, nil
+}
+
+// splitPath strips the version prefix and names the edge: the last path
+// segment, "video-upload" for reel transfers and "batch" for the API root
+func splitPath(rawPath string) (path, edge string) {
+	path = rawPath
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) > 0 && versionSegment.MatchString(segments[0]) {
+		segments = segments[1:]
+		path = "/" + strings.Join(segments, "/")
+	}
+
+	switch {
+	case strings.HasPrefix(path, reelUploadPrefix):
+		edge = "video-upload"
+	case strings.Trim(path, "/") == "":
+		edge = "batch"
+	default:
+		edge = segments[len(segments)-1]
+	}
+	return path, edge
+}
+
+func errorBody(e Error) map[string]interface{} {
+	return map[string]interface{}{
+		"error": map[string]interface{}{
+			"message":       e.Message,
+			"type":          e.Type,
+			"code":          e.Code,
+			"error_subcode": e.Subcode,
+			"is_transient":  e.IsTransient,
+			"fbtrace_id":    "fake-trace",
+		},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
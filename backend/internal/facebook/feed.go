@@ -0,0 +1,57 @@
+package facebook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// graphTimeLayout is the format of Graph API timestamps such as created_time
+const graphTimeLayout = "2006-01-02T15:04:05-0700"
+
+// FeedPost is a post read back from a page
+type FeedPost struct {
+	ID          string
+	Message     string
+	CreatedTime time.Time
+	// ScheduledPublishTime is set on posts listed by GetScheduledPosts
+	ScheduledPublishTime time.Time
+}
+
+// GetRecentPosts lists up to limit posts the page published since the given
+// time, newest first. It is used to find out whether a publish that failed
+// ambiguously (see IsAmbiguous) created the post anyway.
+func (c *Client) GetRecentPosts(ctx context.Context, pageID, accessToken string, since time.Time, limit int) ([]FeedPost, error) {
+	params := url.Values{}
+	params.Set("fields", "id,message,created_time")
+	params.Set("since", strconv.FormatInt(since.Unix(), 10))
+	params.Set("limit", strconv.Itoa(limit))
+	body, err := c.getGraph(ctx, fmt.Sprintf("/%s/posts", pageID), accessToken, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []struct {
+			ID          string `json:"id"`
+			Message     string `json:"message"`
+			CreatedTime string `json:"created_time"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse page posts: %s", string(body))
+	}
+
+	posts := make([]FeedPost, 0, len(result.Data))
+	for _, p := range result.Data {
+		created, err := time.Parse(graphTimeLayout, p.CreatedTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse created_time %q of post %s", p.CreatedTime, p.ID)
+		}
+		posts = append(posts, FeedPost{ID: p.ID, Message: p.Message, CreatedTime: created})
+	}
+	return posts, nil
+}
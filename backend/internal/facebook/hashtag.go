@@ -0,0 +1,92 @@
+package facebook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// MaxHashtagSearchesPerWeek is how many unique hashtags an Instagram
+	// account may look up with ig_hashtag_search in HashtagSearchWindow
+	MaxHashtagSearchesPerWeek = 30
+
+	// HashtagSearchWindow is the rolling window of the hashtag search limit
+	HashtagSearchWindow = 7 * 24 * time.Hour
+
+	// hashtagMediaLimit is the page size read from top_media and recent_media
+	hashtagMediaLimit = 50
+)
+
+// HashtagStats is the media activity of an Instagram hashtag. Instagram does
+// not expose a total media count, so the counts are the media returned by
+// the first page of top_media and recent_media (last 24 hours).
+type HashtagStats struct {
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	TopMediaCount    int    `json:"top_media_count"`
+	RecentMediaCount int    `json:"recent_media_count"`
+}
+
+// SearchHashtag returns the ID of the Instagram hashtag name, or "" when it
+// does not exist. Every new hashtag counts towards the account's
+// MaxHashtagSearchesPerWeek, so callers should keep the ID.
+func (c *Client) SearchHashtag(ctx context.Context, igUserID, accessToken, name string) (string, error) {
+	params := url.Values{}
+	params.Set("user_id", igUserID)
+	params.Set("q", name)
+	body, err := c.getGraph(ctx, "/ig_hashtag_search", accessToken, params)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to parse hashtag search: %s", string(body))
+	}
+	if len(result.Data) == 0 {
+		return "", nil
+	}
+	return result.Data[0].ID, nil
+}
+
+// GetHashtagStats counts the top and recent media of a hashtag found with
+// SearchHashtag. It does not count towards the hashtag search limit.
+func (c *Client) GetHashtagStats(ctx context.Context, igUserID, accessToken, hashtagID, name string) (*HashtagStats, error) {
+	stats := &HashtagStats{ID: hashtagID, Name: name}
+
+	var err error
+	if stats.TopMediaCount, err = c.countHashtagMedia(ctx, igUserID, accessToken, hashtagID, "top_media"); err != nil {
+		return nil, err
+	}
+	if stats.RecentMediaCount, err = c.countHashtagMedia(ctx, igUserID, accessToken, hashtagID, "recent_media"); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (c *Client) countHashtagMedia(ctx context.Context, igUserID, accessToken, hashtagID, edge string) (int, error) {
+	params := url.Values{}
+	params.Set("user_id", igUserID)
+	params.Set("fields", "id")
+	params.Set("limit", strconv.Itoa(hashtagMediaLimit))
+	body, err := c.getGraph(ctx, fmt.Sprintf("/%s/%s", hashtagID, edge), accessToken, params)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read hashtag %s: %w", edge, err)
+	}
+
+	var result struct {
+		Data []json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, fmt.Errorf("failed to parse hashtag %s: %s", edge, string(body))
+	}
+	return len(result.Data), nil
+}
@@ -0,0 +1,254 @@
+package facebook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Instagram container media types
+// (https://developers.facebook.com/docs/instagram-platform/content-publishing)
+const (
+	IGMediaTypeCarousel = "CAROUSEL"
+	IGMediaTypeReels    = "REELS"
+	IGMediaTypeVideo    = "VIDEO" // video carousel item
+)
+
+// Instagram container status codes
+const (
+	IGStatusFinished   = "FINISHED"
+	IGStatusInProgress = "IN_PROGRESS"
+	IGStatusError      = "ERROR"
+	IGStatusExpired    = "EXPIRED"
+	IGStatusPublished  = "PUBLISHED"
+)
+
+const (
+	// MaxCarouselItems is the Instagram limit of images and videos per carousel
+	MaxCarouselItems = 10
+
+	// DefaultContainerPollInterval is how often a container's status_code is
+	// checked while Instagram processes a video
+	DefaultContainerPollInterval = 5 * time.Second
+
+	// ContainerTimeout bounds the wait for a container to finish processing
+	ContainerTimeout = 5 * time.Minute
+)
+
+// WithContainerPollInterval changes how often Instagram container status is
+// polled (default DefaultContainerPollInterval)
+func WithContainerPollInterval(d time.Duration) Option {
+	return func(c *Client) {
+		c.containerPollInterval = d
+	}
+}
+
+// InstagramAccount is the Instagram Business account linked to a page
+type InstagramAccount struct {
+	ID                string `json:"id"`
+	Username          string `json:"username"`
+	ProfilePictureURL string `json:"profile_picture_url"`
+}
+
+// InstagramPublishRequest describes an Instagram post published through the
+// page token. Instagram fetches media itself, so every item needs a public URL.
+type InstagramPublishRequest struct {
+	IGUserID    string
+	AccessToken string
+	Caption     string
+
+	// MediaType uses the page media types: MediaTypePhoto (default) publishes
+	// a single image or a carousel, MediaTypeVideo and MediaTypeReel a reel
+	MediaType string
+	Media     []MediaItem
+}
+
+// InstagramPublishResult identifies the published Instagram media
+type InstagramPublishResult struct {
+	MediaID     string
+	ContainerID string
+}
+
+func (r InstagramPublishRequest) validate() error {
+	if r.IGUserID == "" || r.AccessToken == "" {
+		return fmt.Errorf("%w: instagram account and access token are required", ErrInvalidRequest)
+	}
+	if len(r.Media) == 0 {
+		return fmt.Errorf("%w: instagram posts need at least 1 photo or video", ErrInvalidRequest)
+	}
+	if len(r.Media) > MaxCarouselItems {
+		return fmt.Errorf("%w: an instagram carousel holds at most %d items", ErrInvalidRequest, MaxCarouselItems)
+	}
+	switch r.MediaType {
+	case "", MediaTypePhoto:
+	case MediaTypeVideo, MediaTypeReel:
+		if len(r.Media) != 1 {
+			return fmt.Errorf("%w: an instagram reel needs exactly 1 video", ErrInvalidRequest)
+		}
+	default:
+		return fmt.Errorf("%w: media type %q cannot be published to instagram", ErrInvalidRequest, r.MediaType)
+	}
+	for i, item := range r.Media {
+		if !strings.HasPrefix(item.URL, "http://") && !strings.HasPrefix(item.URL, "https://") {
+			return fmt.Errorf("%w: instagram media item %d needs a public URL", ErrInvalidRequest, i+1)
+		}
+	}
+	return nil
+}
+
+// GetInstagramAccount returns the Instagram Business account linked to the
+// page, or nil when there is none
+func (c *Client) GetInstagramAccount(ctx context.Context, pageID, accessToken string) (*InstagramAccount, error) {
+	params := url.Values{}
+	params.Set("fields", "instagram_business_account{id,username,profile_picture_url}")
+	body, err := c.getGraph(ctx, "/"+pageID, accessToken, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		InstagramBusinessAccount *InstagramAccount `json:"instagram_business_account"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse instagram account: %s", string(body))
+	}
+	return result.InstagramBusinessAccount, nil
+}
+
+// PublishInstagram publishes a single image, a carousel or a reel: it
+// creates the media container(s), waits until Instagram has processed them
+// and publishes with media_publish
+func (c *Client) PublishInstagram(ctx context.Context, req InstagramPublishRequest) (*InstagramPublishResult, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	var containerID string
+	var err error
+	switch {
+	case req.MediaType == MediaTypeVideo || req.MediaType == MediaTypeReel:
+		fmt.Printf("🎬 Creating instagram reel container for %s...\n", req.IGUserID)
+		params := url.Values{}
+		params.Set("media_type", IGMediaTypeReels)
+		params.Set("video_url", req.Media[0].URL)
+		containerID, err = c.createContainer(ctx, req, params)
+	case len(req.Media) == 1:
+		fmt.Printf("📷 Creating instagram image container for %s...\n", req.IGUserID)
+		params := url.Values{}
+		params.Set("image_url", req.Media[0].URL)
+		containerID, err = c.createContainer(ctx, req, params)
+	default:
+		containerID, err = c.createCarouselContainer(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.waitForContainer(ctx, containerID, req.AccessToken); err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("creation_id", containerID)
+	body, err := c.postForm(ctx, fmt.Sprintf("/%s/media_publish", req.IGUserID), req.AccessToken, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish instagram media: %w", err)
+	}
+	mediaID, err := parseObjectID(body)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("✅ Published instagram media %s\n", mediaID)
+	return &InstagramPublishResult{MediaID: mediaID, ContainerID: containerID}, nil
+}
+
+// createCarouselContainer creates one item container per media and the
+// carousel container referencing them
+func (c *Client) createCarouselContainer(ctx context.Context, req InstagramPublishRequest) (string, error) {
+	fmt.Printf("🖼️ Creating instagram carousel with %d items for %s...\n", len(req.Media), req.IGUserID)
+
+	children := make([]string, 0, len(req.Media))
+	for i, item := range req.Media {
+		isVideo := c.isVideoMedia(ctx, req.MediaType, item)
+		params := url.Values{}
+		params.Set("is_carousel_item", "true")
+		if isVideo {
+			params.Set("media_type", IGMediaTypeVideo)
+			params.Set("video_url", item.URL)
+		} else {
+			params.Set("image_url", item.URL)
+		}
+
+		itemReq := req
+		itemReq.Caption = ""
+		childID, err := c.createContainer(ctx, itemReq, params)
+		if err != nil {
+			return "", fmt.Errorf("carousel item %d: %w", i+1, err)
+		}
+		// Video items must finish processing before the carousel is created
+		if isVideo {
+			if err := c.waitForContainer(ctx, childID, req.AccessToken); err != nil {
+				return "", fmt.Errorf("carousel item %d: %w", i+1, err)
+			}
+		}
+		children = append(children, childID)
+	}
+
+	params := url.Values{}
+	params.Set("media_type", IGMediaTypeCarousel)
+	params.Set("children", strings.Join(children, ","))
+	return c.createContainer(ctx, req, params)
+}
+
+// createContainer creates a media container on the Instagram account
+func (c *Client) createContainer(ctx context.Context, req InstagramPublishRequest, params url.Values) (string, error) {
+	if req.Caption != "" {
+		params.Set("caption", req.Caption)
+	}
+	body, err := c.postForm(ctx, fmt.Sprintf("/%s/media", req.IGUserID), req.AccessToken, params)
+	if err != nil {
+		return "", fmt.Errorf("failed to create instagram container: %w", err)
+	}
+	return parseObjectID(body)
+}
+
+// waitForContainer polls the container's status_code until it is FINISHED.
+// ERROR and EXPIRED containers can never be published.
+func (c *Client) waitForContainer(ctx context.Context, containerID, accessToken string) error {
+	ctx, cancel := context.WithTimeout(ctx, ContainerTimeout)
+	defer cancel()
+
+	params := url.Values{}
+	params.Set("fields", "status_code,status")
+	for {
+		body, err := c.getGraph(ctx, "/"+containerID, accessToken, params)
+		if err != nil {
+			return fmt.Errorf("failed to check instagram container %s: %w", containerID, err)
+		}
+
+		var result struct {
+			StatusCode string `json:"status_code"`
+			Status     string `json:"status"`
+		}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return fmt.Errorf("failed to parse instagram container status: %s", string(body))
+		}
+
+		switch result.StatusCode {
+		case IGStatusFinished, IGStatusPublished:
+			return nil
+		case IGStatusError, IGStatusExpired:
+			return fmt.Errorf("%w: instagram container %s is %s: %s", ErrInvalidRequest, containerID, result.StatusCode, result.Status)
+		}
+
+		select {
+		case <-time.After(c.containerPollInterval):
+		case <-ctx.Done():
+			return fmt.Errorf("instagram container %s not ready: %w", containerID, ctx.Err())
+		}
+	}
+}
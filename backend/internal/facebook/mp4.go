@@ -0,0 +1,170 @@
+package facebook
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// VideoInfo is the metadata ProbeVideo reads from an MP4/MOV container
+type VideoInfo struct {
+	// Width and Height are the display size, with rotation applied
+	Width    int
+	Height   int
+	Duration time.Duration
+}
+
+// ProbeVideo reads the duration and display size of an MP4/MOV file from
+// its moov box without decoding any frames
+func ProbeVideo(r io.ReaderAt, size int64) (*VideoInfo, error) {
+	moov, err := findBox(r, 0, size, "moov")
+	if err != nil {
+		return nil, err
+	}
+
+	info := &VideoInfo{}
+	mvhd, err := findBox(r, moov.dataStart, moov.end, "mvhd")
+	if err != nil {
+		return nil, err
+	}
+	if info.Duration, err = readMovieDuration(r, mvhd); err != nil {
+		return nil, err
+	}
+
+	// The first track with a "vide" handler carries the picture size
+	for offset := moov.dataStart; offset < moov.end; {
+		trak, err := findBox(r, offset, moov.end, "trak")
+		if err != nil {
+			break
+		}
+		offset = trak.end
+
+		isVideo, err := isVideoTrack(r, trak)
+		if err != nil || !isVideo {
+			continue
+		}
+		tkhd, err := findBox(r, trak.dataStart, trak.end, "tkhd")
+		if err != nil {
+			return nil, err
+		}
+		if info.Width, info.Height, err = readTrackSize(r, tkhd); err != nil {
+			return nil, err
+		}
+		return info, nil
+	}
+	return nil, fmt.Errorf("no video track found")
+}
+
+// mp4Box is the location of one ISO BMFF box inside the file
+type mp4Box struct {
+	boxType   string
+	dataStart int64
+	end       int64
+}
+
+// findBox returns the first box of boxType between start and end
+func findBox(r io.ReaderAt, start, end int64, boxType string) (*mp4Box, error) {
+	header := make([]byte, 16)
+	for offset := start; offset+8 <= end; {
+		if _, err := r.ReadAt(header[:8], offset); err != nil {
+			return nil, fmt.Errorf("failed to read box header: %w", err)
+		}
+		boxSize := int64(binary.BigEndian.Uint32(header[:4]))
+		headerSize := int64(8)
+		switch boxSize {
+		case 0:
+			boxSize = end - offset
+		case 1:
+			if _, err := r.ReadAt(header[8:16], offset+8); err != nil {
+				return nil, fmt.Errorf("failed to read box header: %w", err)
+			}
+			boxSize = int64(binary.BigEndian.Uint64(header[8:16]))
+			headerSize = 16
+		}
+		if boxSize < headerSize || offset+boxSize > end {
+			return nil, fmt.Errorf("malformed %q box at offset %d", string(header[4:8]), offset)
+		}
+
+		if string(header[4:8]) == boxType {
+			return &mp4Box{boxType: boxType, dataStart: offset + headerSize, end: offset + boxSize}, nil
+		}
+		offset += boxSize
+	}
+	return nil, fmt.Errorf("%s box not found", boxType)
+}
+
+// readBoxData reads n bytes of a box body
+func readBoxData(r io.ReaderAt, box *mp4Box, n int64) ([]byte, error) {
+	if box.end-box.dataStart < n {
+		return nil, fmt.Errorf("%s box too short", box.boxType)
+	}
+	buf := make([]byte, n)
+	if _, err := r.ReadAt(buf, box.dataStart); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func readMovieDuration(r io.ReaderAt, mvhd *mp4Box) (time.Duration, error) {
+	buf, err := readBoxData(r, mvhd, 32)
+	if err != nil {
+		return 0, err
+	}
+
+	var timescale, duration uint64
+	if buf[0] == 1 {
+		timescale = uint64(binary.BigEndian.Uint32(buf[20:24]))
+		duration = binary.BigEndian.Uint64(buf[24:32])
+	} else {
+		timescale = uint64(binary.BigEndian.Uint32(buf[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(buf[16:20]))
+	}
+	if timescale == 0 {
+		return 0, fmt.Errorf("invalid movie timescale")
+	}
+	return time.Duration(duration * uint64(time.Second) / timescale), nil
+}
+
+func isVideoTrack(r io.ReaderAt, trak *mp4Box) (bool, error) {
+	mdia, err := findBox(r, trak.dataStart, trak.end, "mdia")
+	if err != nil {
+		return false, err
+	}
+	hdlr, err := findBox(r, mdia.dataStart, mdia.end, "hdlr")
+	if err != nil {
+		return false, err
+	}
+	buf, err := readBoxData(r, hdlr, 12)
+	if err != nil {
+		return false, err
+	}
+	return string(buf[8:12]) == "vide", nil
+}
+
+// readTrackSize returns the tkhd width and height, swapped when the
+// transformation matrix rotates the picture by 90 or 270 degrees
+func readTrackSize(r io.ReaderAt, tkhd *mp4Box) (int, int, error) {
+	matrixOffset := int64(40)
+	buf, err := readBoxData(r, tkhd, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	if buf[0] == 1 {
+		matrixOffset = 52
+	}
+
+	buf, err = readBoxData(r, tkhd, matrixOffset+44)
+	if err != nil {
+		return 0, 0, err
+	}
+	matrix := buf[matrixOffset:]
+	a := int32(binary.BigEndian.Uint32(matrix[0:4]))
+	width := int(binary.BigEndian.Uint32(matrix[36:40]) >> 16)
+	height := int(binary.BigEndian.Uint32(matrix[40:44]) >> 16)
+
+	if a == 0 {
+		width, height = height, width
+	}
+	return width, height, nil
+}
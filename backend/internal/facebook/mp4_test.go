@@ -0,0 +1,118 @@
+package facebook_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"fbscheduler/internal/facebook"
+)
+
+// testVideo describes the MP4 built by buildMP4
+type testVideo struct {
+	width, height int
+	duration      time.Duration
+	rotated       bool // 90° display matrix
+	handler       string
+	noMoov        bool
+	padding       int // mdat payload size
+}
+
+// box encodes an MP4 box: 32-bit size, type, then the payload parts
+func box(boxType string, parts ...[]byte) []byte {
+	payload := bytes.Join(parts, nil)
+	out := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint32(out, uint32(8+len(payload)))
+	copy(out[4:], boxType)
+	return append(out, payload...)
+}
+
+// buildMP4 writes the boxes ProbeVideo reads: ftyp, moov{mvhd, trak{tkhd, mdia{hdlr}}}, mdat
+func buildMP4(v testVideo) []byte {
+	const timescale = 1000
+
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], timescale)
+	binary.BigEndian.PutUint32(mvhd[16:], uint32(v.duration/time.Millisecond))
+
+	tkhd := make([]byte, 84)
+	if v.rotated {
+		binary.BigEndian.PutUint32(tkhd[44:], 0x00010000)
+		binary.BigEndian.PutUint32(tkhd[52:], 0xFFFF0000)
+	} else {
+		binary.BigEndian.PutUint32(tkhd[40:], 0x00010000)
+		binary.BigEndian.PutUint32(tkhd[56:], 0x00010000)
+	}
+	binary.BigEndian.PutUint32(tkhd[76:], uint32(v.width)<<16)
+	binary.BigEndian.PutUint32(tkhd[80:], uint32(v.height)<<16)
+
+	handler := v.handler
+	if handler == "" {
+		handler = "vide"
+	}
+	hdlr := make([]byte, 24)
+	copy(hdlr[8:], handler)
+
+	ftyp := box("ftyp", []byte("isom"), make([]byte, 4), []byte("isommp42"))
+	mdat := box("mdat", make([]byte, v.padding))
+	if v.noMoov {
+		return bytes.Join([][]byte{ftyp, mdat}, nil)
+	}
+	moov := box("moov", box("mvhd", mvhd), box("trak", box("tkhd", tkhd), box("mdia", box("hdlr", hdlr))))
+	return bytes.Join([][]byte{ftyp, moov, mdat}, nil)
+}
+
+func TestProbeVideo(t *testing.T) {
+	tests := []struct {
+		name    string
+		video   testVideo
+		want    facebook.VideoInfo
+		wantErr bool
+	}{
+		{
+			name:  "portrait",
+			video: testVideo{width: 1080, height: 1920, duration: 15 * time.Second},
+			want:  facebook.VideoInfo{Width: 1080, Height: 1920, Duration: 15 * time.Second},
+		},
+		{
+			name:  "landscape",
+			video: testVideo{width: 1920, height: 1080, duration: 90500 * time.Millisecond},
+			want:  facebook.VideoInfo{Width: 1920, Height: 1080, Duration: 90500 * time.Millisecond},
+		},
+		{
+			name:  "rotated landscape is displayed portrait",
+			video: testVideo{width: 1920, height: 1080, duration: 10 * time.Second, rotated: true},
+			want:  facebook.VideoInfo{Width: 1080, Height: 1920, Duration: 10 * time.Second},
+		},
+		{
+			name:    "audio only",
+			video:   testVideo{width: 0, height: 0, duration: 10 * time.Second, handler: "soun"},
+			wantErr: true,
+		},
+		{
+			name:    "no moov box",
+			video:   testVideo{noMoov: true, padding: 64},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := buildMP4(tt.video)
+			info, err := facebook.ProbeVideo(bytes.NewReader(data), int64(len(data)))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ProbeVideo() = %+v, want error", info)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProbeVideo() error = %v", err)
+			}
+			if *info != tt.want {
+				t.Errorf("ProbeVideo() = %+v, want %+v", *info, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package facebook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// facebookBaseURL prefixes the relative permalinks Facebook returns for
+// videos and reels
+const facebookBaseURL = "https://www.facebook.com"
+
+// PublishedPost is what Facebook reports about a live post
+type PublishedPost struct {
+	ID           string
+	PermalinkURL string
+	CreatedTime  time.Time
+	// Raw is the Graph response the details were read from
+	Raw json.RawMessage
+}
+
+// GetPublishedPost reads the permalink and creation time of a published
+// object. Posts, photos and videos expose permalink_url; albums only have
+// link, so an invalid field error is retried with link.
+func (c *Client) GetPublishedPost(ctx context.Context, objectID, accessToken string) (*PublishedPost, error) {
+	body, err := c.getObjectFields(ctx, objectID, accessToken, "id,permalink_url,created_time")
+	if graphErr, ok := AsGraphError(err); ok && graphErr.Code == 100 && !graphErr.IsNotFound() {
+		body, err = c.getObjectFields(ctx, objectID, accessToken, "id,link,created_time")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		ID           string `json:"id"`
+		PermalinkURL string `json:"permalink_url"`
+		Link         string `json:"link"`
+		CreatedTime  string `json:"created_time"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse post details: %s", string(body))
+	}
+
+	post := &PublishedPost{ID: result.ID, PermalinkURL: result.PermalinkURL, Raw: body}
+	if post.PermalinkURL == "" {
+		post.PermalinkURL = result.Link
+	}
+	if strings.HasPrefix(post.PermalinkURL, "/") {
+		post.PermalinkURL = facebookBaseURL + post.PermalinkURL
+	}
+	if result.CreatedTime != "" {
+		created, err := time.Parse(graphTimeLayout, result.CreatedTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse created_time %q of %s", result.CreatedTime, objectID)
+		}
+		post.CreatedTime = created
+	}
+	return post, nil
+}
+
+func (c *Client) getObjectFields(ctx context.Context, objectID, accessToken, fields string) ([]byte, error) {
+	params := url.Values{}
+	params.Set("fields", fields)
+	return c.getGraph(ctx, "/"+objectID, accessToken, params)
+}
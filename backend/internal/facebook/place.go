@@ -0,0 +1,99 @@
+package facebook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// placeFields are read for every place search result
+const placeFields = "id,name,location{street,city,country,latitude,longitude},checkins"
+
+// DefaultPlaceSearchLimit caps place search results when no limit is given
+const DefaultPlaceSearchLimit = 25
+
+// Place is a location page that posts can be checked in to
+type Place struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Location struct {
+		Street    string  `json:"street,omitempty"`
+		City      string  `json:"city,omitempty"`
+		Country   string  `json:"country,omitempty"`
+		Latitude  float64 `json:"latitude,omitempty"`
+		Longitude float64 `json:"longitude,omitempty"`
+	} `json:"location"`
+	Checkins int64 `json:"checkins,omitempty"`
+}
+
+// PlaceSearch narrows a place search. Distance (meters) only applies
+// together with a center.
+type PlaceSearch struct {
+	Query     string
+	Latitude  float64
+	Longitude float64
+	Distance  int
+	Limit     int
+}
+
+func (s PlaceSearch) hasCenter() bool {
+	return s.Latitude != 0 || s.Longitude != 0
+}
+
+// SearchPlaces finds places to tag with PublishRequest.Place. It uses the
+// place search (search?type=place) and falls back to the pages search,
+// which only matches by name, when the app has no access to place search.
+// Rate limits and transient errors are returned as is.
+func (c *Client) SearchPlaces(ctx context.Context, search PlaceSearch, accessToken string) ([]Place, error) {
+	if search.Query == "" && !search.hasCenter() {
+		return nil, fmt.Errorf("%w: a query or a center is required to search places", ErrInvalidRequest)
+	}
+	if search.Limit <= 0 {
+		search.Limit = DefaultPlaceSearchLimit
+	}
+
+	params := url.Values{}
+	params.Set("type", "place")
+	params.Set("fields", placeFields)
+	params.Set("limit", strconv.Itoa(search.Limit))
+	if search.Query != "" {
+		params.Set("q", search.Query)
+	}
+	if search.hasCenter() {
+		params.Set("center", strconv.FormatFloat(search.Latitude, 'f', -1, 64)+","+strconv.FormatFloat(search.Longitude, 'f', -1, 64))
+		if search.Distance > 0 {
+			params.Set("distance", strconv.Itoa(search.Distance))
+		}
+	}
+
+	places, err := c.searchPlaces(ctx, "/search", accessToken, params)
+	if err == nil || search.Query == "" || IsRateLimit(err) || IsTransient(err) {
+		return places, err
+	}
+	if _, isGraphErr := AsGraphError(err); !isGraphErr {
+		return nil, err
+	}
+
+	params = url.Values{}
+	params.Set("q", search.Query)
+	params.Set("fields", placeFields)
+	params.Set("limit", strconv.Itoa(search.Limit))
+	return c.searchPlaces(ctx, "/pages/search", accessToken, params)
+}
+
+func (c *Client) searchPlaces(ctx context.Context, path, accessToken string, params url.Values) ([]Place, error) {
+	body, err := c.getGraph(ctx, path, accessToken, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []Place `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse place search: %s", string(body))
+	}
+	return result.Data, nil
+}
@@ -0,0 +1,40 @@
+package facebook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// UpdatePost replaces the text of a published or scheduled post: the
+// message of feed and photo posts, the description of videos and reels.
+// Facebook only allows editing the text; media and links are fixed once
+// the post exists, and stories cannot be edited at all.
+func (c *Client) UpdatePost(ctx context.Context, objectID, accessToken, mediaType, message string) error {
+	if message == "" {
+		return fmt.Errorf("%w: message is required to edit a post", ErrInvalidRequest)
+	}
+
+	field := "message"
+	switch mediaType {
+	case MediaTypeStory:
+		return fmt.Errorf("%w: stories cannot be edited", ErrInvalidRequest)
+	case MediaTypeVideo, MediaTypeReel:
+		field = "description"
+	}
+	params := url.Values{}
+	params.Set(field, message)
+	_, err := c.postForm(ctx, "/"+objectID, accessToken, params)
+	return err
+}
+
+// DeletePost deletes a post, photo or video
+func (c *Client) DeletePost(ctx context.Context, objectID, accessToken string) error {
+	httpReq, err := c.newGraphRequest(ctx, http.MethodDelete, "/"+objectID, accessToken, nil, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.doGraph(httpReq)
+	return err
+}
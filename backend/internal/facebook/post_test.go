@@ -0,0 +1,48 @@
+package facebook_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"fbscheduler/internal/facebook"
+)
+
+func TestUpdatePost(t *testing.T) {
+	tests := []struct {
+		name      string
+		mediaType string
+		wantField string
+		wantErr   error
+	}{
+		{"feed post", "", "message", nil},
+		{"photo", facebook.MediaTypePhoto, "message", nil},
+		{"video", facebook.MediaTypeVideo, "description", nil},
+		{"reel", facebook.MediaTypeReel, "description", nil},
+		{"story", facebook.MediaTypeStory, "", facebook.ErrInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, client := newTestServer(t)
+			objectID := testPageID + "_1"
+
+			err := client.UpdatePost(context.Background(), objectID, testPageToken, tt.mediaType, "edited")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("UpdatePost() error = %v, want %v", err, tt.wantErr)
+				}
+				if calls := srv.CallsTo(objectID); len(calls) != 0 {
+					t.Errorf("UpdatePost() sent %d calls, want none", len(calls))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdatePost() error = %v", err)
+			}
+			calls := srv.CallsTo(objectID)
+			if len(calls) != 1 || calls[0].Param(tt.wantField) != "edited" {
+				t.Errorf("UpdatePost() calls = %+v, want %s=edited", calls, tt.wantField)
+			}
+		})
+	}
+}
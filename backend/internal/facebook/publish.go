@@ -0,0 +1,550 @@
+package facebook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Media types accepted by PublishRequest.MediaType
+const (
+	MediaTypePhoto = "photo"
+	MediaTypeVideo = "video"
+	MediaTypeReel  = "reel"
+	MediaTypeStory = "story"
+)
+
+// MediaItem is one photo or video attached to a post. The data source is,
+// in order of precedence, Reader, a local file at Path, or a download of URL.
+type MediaItem struct {
+	URL      string
+	Path     string
+	Reader   io.Reader
+	Filename string
+	Caption  string
+}
+
+// MediaFromURLs builds media items from a list of URLs
+func MediaFromURLs(urls []string) []MediaItem {
+	items := make([]MediaItem, 0, len(urls))
+	for _, u := range urls {
+		items = append(items, MediaItem{URL: u})
+	}
+	return items
+}
+
+// PublishRequest describes a page post. Everything except PageID and
+// AccessToken is optional.
+type PublishRequest struct {
+	PageID      string
+	AccessToken string
+	Message     string
+
+	MediaType string // MediaTypePhoto (default), MediaTypeVideo, MediaTypeReel or MediaTypeStory
+	Media     []MediaItem
+
+	Link  string
+	Place string // Place page ID for check-ins
+	Tags  string // Comma-separated user IDs
+
+	// Link preview overrides. Facebook only honors them for links to
+	// domains the page owns; otherwise the scraped preview is used.
+	LinkName        string
+	LinkDescription string
+	LinkPicture     string
+
+	// AlbumName publishes photos into a new album, one photo per item with
+	// its own caption, instead of a single multi-photo feed post
+	AlbumName string
+
+	// Published = false creates the post without showing it on the page
+	// (a dark post, reachable by link and usable in ads)
+	Published *bool
+
+	// Targeting restricts who can see the post; FeedTargeting only
+	// promotes it in the news feed of the matching people
+	Targeting     *Audience
+	FeedTargeting *Audience
+
+	// ScheduledPublishTime hands scheduling off to Facebook: the post is
+	// created unpublished and goes live at this time. Must be between
+	// MinScheduleLead and MaxScheduleLead from now.
+	ScheduledPublishTime time.Time
+
+	// OnProgress reports video and reel upload progress
+	OnProgress ProgressFunc
+}
+
+// PublishResult identifies what was created on Facebook
+type PublishResult struct {
+	// PostID is the page post ID (or album ID in album mode)
+	PostID string
+	// MediaIDs are the uploaded photo/video object IDs, in request order
+	MediaIDs []string
+	// Response is the raw Graph response of the request that created the
+	// post (nil when Facebook only acknowledged it, e.g. video uploads)
+	Response json.RawMessage
+}
+
+// ErrInvalidRequest is wrapped by every PublishRequest validation error.
+// Such requests are rejected before calling Facebook and never succeed on retry.
+var ErrInvalidRequest = errors.New("invalid publish request")
+
+// Facebook-side scheduling window for ScheduledPublishTime
+const (
+	MinScheduleLead = 10 * time.Minute
+	MaxScheduleLead = 30 * 24 * time.Hour
+)
+
+func (r PublishRequest) validate() error {
+	if r.PageID == "" || r.AccessToken == "" {
+		return fmt.Errorf("%w: page ID and access token are required", ErrInvalidRequest)
+	}
+	if r.Message == "" && r.Link == "" && len(r.Media) == 0 {
+		return fmt.Errorf("%w: message, link or media is required", ErrInvalidRequest)
+	}
+	if r.Link != "" && len(r.Media) > 0 {
+		return fmt.Errorf("%w: a link post cannot carry uploaded media; use LinkPicture for a custom preview image", ErrInvalidRequest)
+	}
+	if r.Link == "" && (r.LinkName != "" || r.LinkDescription != "" || r.LinkPicture != "") {
+		return fmt.Errorf("%w: link name, description and picture require a link", ErrInvalidRequest)
+	}
+	if r.Tags != "" && r.Place == "" {
+		return fmt.Errorf("%w: tagging people requires a place", ErrInvalidRequest)
+	}
+	if r.Place != "" && (r.MediaType == MediaTypeReel || r.MediaType == MediaTypeStory) {
+		return fmt.Errorf("%w: reels and stories cannot be checked in to a place", ErrInvalidRequest)
+	}
+	if r.MediaType == MediaTypeVideo && len(r.Media) > 1 {
+		return fmt.Errorf("%w: facebook only supports 1 video per post", ErrInvalidRequest)
+	}
+	if r.MediaType == MediaTypeReel && len(r.Media) != 1 {
+		return fmt.Errorf("%w: a reel needs exactly 1 video", ErrInvalidRequest)
+	}
+	if r.MediaType == MediaTypeStory && len(r.Media) != 1 {
+		return fmt.Errorf("%w: a story needs exactly 1 photo or video", ErrInvalidRequest)
+	}
+	if err := r.validateAudience(); err != nil {
+		return err
+	}
+	if !r.ScheduledPublishTime.IsZero() {
+		if r.MediaType == MediaTypeStory || r.AlbumName != "" {
+			return fmt.Errorf("%w: stories and albums cannot be scheduled on Facebook", ErrInvalidRequest)
+		}
+		if lead := time.Until(r.ScheduledPublishTime); lead < MinScheduleLead || lead > MaxScheduleLead {
+			return fmt.Errorf("%w: scheduled publish time must be between %v and %v from now",
+				ErrInvalidRequest, MinScheduleLead, MaxScheduleLead)
+		}
+	}
+	for i, item := range r.Media {
+		if item.Reader == nil && item.Path == "" && item.URL == "" {
+			return fmt.Errorf("%w: media item %d has no URL, path or data", ErrInvalidRequest, i+1)
+		}
+	}
+	return nil
+}
+
+// validateAudience checks targeting, feed targeting and unpublished posts,
+// which only apply to feed, photo and video posts
+func (r PublishRequest) validateAudience() error {
+	unpublished := r.Published != nil && !*r.Published
+	if r.Targeting == nil && r.FeedTargeting == nil && !unpublished {
+		return nil
+	}
+	if r.MediaType == MediaTypeReel || r.MediaType == MediaTypeStory || r.AlbumName != "" {
+		return fmt.Errorf("%w: reels, stories and albums cannot be targeted or unpublished", ErrInvalidRequest)
+	}
+	if unpublished && !r.ScheduledPublishTime.IsZero() {
+		return fmt.Errorf("%w: an unpublished post cannot be scheduled on Facebook", ErrInvalidRequest)
+	}
+	if r.Targeting != nil {
+		if err := r.Targeting.Validate(); err != nil {
+			return fmt.Errorf("targeting: %w", err)
+		}
+	}
+	if r.FeedTargeting != nil {
+		if err := r.FeedTargeting.Validate(); err != nil {
+			return fmt.Errorf("feed targeting: %w", err)
+		}
+	}
+	return nil
+}
+
+// Publish creates a page post: text, link, single photo, multi-photo,
+// photo album, video, reel or story, depending on the request
+func (c *Client) Publish(ctx context.Context, req PublishRequest) (*PublishResult, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case req.MediaType == MediaTypeReel:
+		return c.publishReel(ctx, req)
+	case req.MediaType == MediaTypeStory:
+		return c.publishStory(ctx, req)
+	case len(req.Media) == 0:
+		return c.publishFeed(ctx, req, nil)
+	case req.MediaType == MediaTypeVideo:
+		return c.publishVideo(ctx, req)
+	case req.AlbumName != "":
+		return c.publishAlbum(ctx, req)
+	case len(req.Media) == 1:
+		return c.publishSinglePhoto(ctx, req)
+	default:
+		return c.publishMultiPhoto(ctx, req)
+	}
+}
+
+// postParams builds the fields shared by feed, photo and video posts.
+// messageField is "message" for feed/photos and "description" for videos.
+func (r PublishRequest) postParams(messageField string) url.Values {
+	params := url.Values{}
+	if r.Message != "" {
+		params.Set(messageField, r.Message)
+	}
+	if r.Place != "" {
+		params.Set("place", r.Place)
+	}
+	if r.Tags != "" {
+		params.Set("tags", r.Tags)
+	}
+	if r.Published != nil && !*r.Published {
+		params.Set("published", "false")
+	}
+	if r.Targeting != nil {
+		params.Set("targeting", r.Targeting.targetingParam())
+	}
+	if r.FeedTargeting != nil {
+		params.Set("feed_targeting", r.FeedTargeting.feedTargetingParam())
+	}
+	if !r.ScheduledPublishTime.IsZero() {
+		params.Set("published", "false")
+		params.Set("scheduled_publish_time", strconv.FormatInt(r.ScheduledPublishTime.Unix(), 10))
+	}
+	return params
+}
+
+// feedParams builds a feed post: message, link preview and attached photos
+func (r PublishRequest) feedParams(mediaIDs []string) url.Values {
+	params := r.postParams("message")
+	if r.Link != "" {
+		params.Set("link", r.Link)
+		if r.LinkName != "" {
+			params.Set("name", r.LinkName)
+		}
+		if r.LinkDescription != "" {
+			params.Set("description", r.LinkDescription)
+		}
+		if r.LinkPicture != "" {
+			params.Set("picture", r.LinkPicture)
+		}
+	}
+	for _, mediaID := range mediaIDs {
+		mediaJSON, _ := json.Marshal(map[string]string{"media_fbid": mediaID})
+		params.Add("attached_media[]", string(mediaJSON))
+	}
+	return params
+}
+
+// singlePhotoParams builds a one-photo post; the item caption is used
+// when the post has no message
+func (r PublishRequest) singlePhotoParams() url.Values {
+	params := r.postParams("message")
+	if r.Message == "" && r.Media[0].Caption != "" {
+		params.Set("message", r.Media[0].Caption)
+	}
+	return params
+}
+
+func (c *Client) publishFeed(ctx context.Context, req PublishRequest, mediaIDs []string) (*PublishResult, error) {
+	params := req.feedParams(mediaIDs)
+
+	body, err := c.postForm(ctx, fmt.Sprintf("/%s/feed", req.PageID), req.AccessToken, params)
+	if err != nil {
+		return nil, err
+	}
+	postID, err := parsePostID(body)
+	if err != nil {
+		return nil, err
+	}
+	return &PublishResult{PostID: postID, MediaIDs: mediaIDs, Response: body}, nil
+}
+
+func (c *Client) publishSinglePhoto(ctx context.Context, req PublishRequest) (*PublishResult, error) {
+	item := req.Media[0]
+	params := req.singlePhotoParams()
+
+	body, err := c.uploadMedia(ctx, fmt.Sprintf("/%s/photos", req.PageID), req.AccessToken, params, item, "image.jpg")
+	if err != nil {
+		return nil, err
+	}
+	return parsePublishResult(body)
+}
+
+// publishMultiPhoto uploads every photo unpublished, then attaches them to
+// one feed post. Facebook ignores per-photo captions in this mode.
+func (c *Client) publishMultiPhoto(ctx context.Context, req PublishRequest) (*PublishResult, error) {
+	fmt.Printf("⚡ Uploading %d images concurrently...\n", len(req.Media))
+	startTime := time.Now()
+
+	mediaIDs, err := c.uploadConcurrently(req.Media, func(item MediaItem) (string, error) {
+		params := url.Values{}
+		params.Set("published", "false")
+		body, err := c.uploadMedia(ctx, fmt.Sprintf("/%s/photos", req.PageID), req.AccessToken, params, item, "image.jpg")
+		if err != nil {
+			return "", err
+		}
+		return parseObjectID(body)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uploadDuration := time.Since(startTime)
+	fmt.Printf("✅ Uploaded %d images in %.2f seconds\n", len(mediaIDs), uploadDuration.Seconds())
+
+	return c.publishFeed(ctx, req, mediaIDs)
+}
+
+// publishAlbum creates an album and uploads each photo with its own caption
+func (c *Client) publishAlbum(ctx context.Context, req PublishRequest) (*PublishResult, error) {
+	fmt.Printf("📸 Creating album with %d photos (each with caption)...\n", len(req.Media))
+
+	albumParams := url.Values{}
+	albumParams.Set("name", req.AlbumName)
+	if req.Message != "" {
+		albumParams.Set("message", req.Message)
+	}
+	body, err := c.postForm(ctx, fmt.Sprintf("/%s/albums", req.PageID), req.AccessToken, albumParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create album: %w", err)
+	}
+	albumID, err := parseObjectID(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create album: %w", err)
+	}
+
+	photoIDs, err := c.uploadConcurrently(req.Media, func(item MediaItem) (string, error) {
+		params := url.Values{}
+		if item.Caption != "" {
+			params.Set("message", item.Caption)
+		}
+		body, err := c.uploadMedia(ctx, fmt.Sprintf("/%s/photos", albumID), req.AccessToken, params, item, "image.jpg")
+		if err != nil {
+			return "", err
+		}
+		return parseObjectID(body)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("✅ Album posted successfully: %s\n", albumID)
+	return &PublishResult{PostID: albumID, MediaIDs: photoIDs, Response: body}, nil
+}
+
+// uploadConcurrently runs upload for every item in parallel and returns the
+// resulting IDs in request order
+func (c *Client) uploadConcurrently(items []MediaItem, upload func(MediaItem) (string, error)) ([]string, error) {
+	type uploadResult struct {
+		id    string
+		index int
+		err   error
+	}
+
+	resultChan := make(chan uploadResult, len(items))
+	for i, item := range items {
+		go func(idx int, item MediaItem) {
+			id, err := upload(item)
+			resultChan <- uploadResult{id: id, index: idx, err: err}
+		}(i, item)
+	}
+
+	results := make([]uploadResult, len(items))
+	for i := 0; i < len(items); i++ {
+		result := <-resultChan
+		results[result.index] = result
+	}
+
+	ids := make([]string, 0, len(items))
+	for i, result := range results {
+		if result.err != nil {
+			return nil, fmt.Errorf("failed to upload media %d: %w", i+1, result.err)
+		}
+		ids = append(ids, result.id)
+	}
+	return ids, nil
+}
+
+// openMedia returns the item's data, downloading it when only a URL is set
+func (c *Client) openMedia(ctx context.Context, item MediaItem) (io.ReadCloser, error) {
+	if item.Reader != nil {
+		return io.NopCloser(item.Reader), nil
+	}
+	if item.Path != "" {
+		return os.Open(item.Path)
+	}
+
+	// Downloads use the media client (no total timeout) bounded by their own
+	// deadline, which lasts until the body is closed
+	ctx, cancel := context.WithTimeout(ctx, mediaDownloadTimeout)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, item.URL, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp, err := c.mediaClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to download media: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("failed to download media %s: status %d", item.URL, resp.StatusCode)
+	}
+	return &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}, nil
+}
+
+// cancelOnClose releases a download's context once its body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.cancel()
+	return err
+}
+
+// uploadMedia sends one file as the "source" field of a multipart request
+func (c *Client) uploadMedia(ctx context.Context, path, accessToken string, params url.Values, item MediaItem, defaultFilename string) ([]byte, error) {
+	src, err := c.openMedia(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	filename := item.Filename
+	if filename == "" {
+		filename = defaultFilename
+	}
+	return c.postMultipart(ctx, path, accessToken, params, "source", filename, src)
+}
+
+// postMultipart sends params plus one file field as multipart/form-data
+func (c *Client) postMultipart(ctx context.Context, path, accessToken string, params url.Values, fileField, filename string, src io.Reader) ([]byte, error) {
+	return c.sendMultipart(ctx, c.httpClient, path, accessToken, params, fileField, filename, src)
+}
+
+// sendMultipart builds the multipart body and sends it with httpClient
+func (c *Client) sendMultipart(ctx context.Context, httpClient *http.Client, path, accessToken string, params url.Values, fileField, filename string, src io.Reader) ([]byte, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile(fileField, filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, src); err != nil {
+		return nil, fmt.Errorf("failed to read media: %w", err)
+	}
+	for key, values := range params {
+		for _, v := range values {
+			writer.WriteField(key, v)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	httpReq, err := c.newGraphRequest(ctx, http.MethodPost, path, accessToken, nil, body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", writer.FormDataContentType())
+	return c.doGraphWith(httpClient, httpReq)
+}
+
+// postForm sends a url-encoded POST to a Graph API path
+func (c *Client) postForm(ctx context.Context, path, accessToken string, params url.Values) ([]byte, error) {
+	httpReq, err := c.newGraphRequest(ctx, http.MethodPost, path, accessToken, nil, bytes.NewBufferString(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.doGraph(httpReq)
+}
+
+// doGraph executes a request, records its usage headers and turns Graph API
+// errors into *GraphError
+func (c *Client) doGraph(req *http.Request) ([]byte, error) {
+	return c.doGraphWith(c.httpClient, req)
+}
+
+// doGraphWith is doGraph over another http.Client (see mediaClient)
+func (c *Client) doGraphWith(httpClient *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	c.recordUsage(requestToken(req), resp.Header)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := parseGraphError(resp.StatusCode, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+type postResponse struct {
+	ID     string `json:"id"`
+	PostID string `json:"post_id"`
+}
+
+// parsePublishResult reads {"id", "post_id"} from a photo/video upload
+func parsePublishResult(body []byte) (*PublishResult, error) {
+	var result postResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %s", string(body))
+	}
+	postID := result.PostID
+	if postID == "" {
+		postID = result.ID
+	}
+	return &PublishResult{PostID: postID, MediaIDs: []string{result.ID}, Response: body}, nil
+}
+
+// parsePostID reads the created post ID, preferring post_id over id
+func parsePostID(body []byte) (string, error) {
+	var result postResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to parse response: %s", string(body))
+	}
+	if result.PostID != "" {
+		return result.PostID, nil
+	}
+	return result.ID, nil
+}
+
+// parseObjectID reads the "id" of a created object
+func parseObjectID(body []byte) (string, error) {
+	var result postResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to parse response: %s", string(body))
+	}
+	return result.ID, nil
+}
@@ -0,0 +1,131 @@
+package facebook_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"fbscheduler/internal/facebook"
+	"fbscheduler/internal/facebook/fake"
+)
+
+func TestPublishValidation(t *testing.T) {
+	photo := facebook.MediaItem{URL: "https://example.com/a.jpg"}
+	video := facebook.MediaItem{URL: "https://example.com/a.mp4"}
+
+	tests := []struct {
+		name string
+		req  facebook.PublishRequest
+	}{
+		{"missing token", facebook.PublishRequest{PageID: testPageID, Message: "hi"}},
+		{"empty post", facebook.PublishRequest{PageID: testPageID, AccessToken: testPageToken}},
+		{"link with media", facebook.PublishRequest{PageID: testPageID, AccessToken: testPageToken,
+			Link: "https://example.com", Media: []facebook.MediaItem{photo}}},
+		{"link preview without link", facebook.PublishRequest{PageID: testPageID, AccessToken: testPageToken,
+			Message: "hi", LinkName: "name"}},
+		{"tags without place", facebook.PublishRequest{PageID: testPageID, AccessToken: testPageToken,
+			Message: "hi", Tags: "1,2"}},
+		{"reel with place", facebook.PublishRequest{PageID: testPageID, AccessToken: testPageToken,
+			MediaType: facebook.MediaTypeReel, Media: []facebook.MediaItem{video}, Place: "42"}},
+		{"two videos", facebook.PublishRequest{PageID: testPageID, AccessToken: testPageToken,
+			MediaType: facebook.MediaTypeVideo, Media: []facebook.MediaItem{video, video}}},
+		{"reel without video", facebook.PublishRequest{PageID: testPageID, AccessToken: testPageToken,
+			MediaType: facebook.MediaTypeReel, Message: "hi"}},
+		{"story with two items", facebook.PublishRequest{PageID: testPageID, AccessToken: testPageToken,
+			MediaType: facebook.MediaTypeStory, Media: []facebook.MediaItem{photo, photo}}},
+		{"unpublished story", facebook.PublishRequest{PageID: testPageID, AccessToken: testPageToken,
+			MediaType: facebook.MediaTypeStory, Media: []facebook.MediaItem{photo}, Published: boolPtr(false)}},
+		{"scheduled too soon", facebook.PublishRequest{PageID: testPageID, AccessToken: testPageToken,
+			Message: "hi", ScheduledPublishTime: time.Now().Add(time.Minute)}},
+		{"scheduled album", facebook.PublishRequest{PageID: testPageID, AccessToken: testPageToken,
+			Media: []facebook.MediaItem{photo}, AlbumName: "Album", ScheduledPublishTime: time.Now().Add(time.Hour)}},
+		{"media item without source", facebook.PublishRequest{PageID: testPageID, AccessToken: testPageToken,
+			Media: []facebook.MediaItem{{Caption: "no source"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, client := newTestServer(t)
+			_, err := client.Publish(context.Background(), tt.req)
+			if !errors.Is(err, facebook.ErrInvalidRequest) {
+				t.Fatalf("Publish() error = %v, want ErrInvalidRequest", err)
+			}
+			if calls := srv.Calls(); len(calls) != 0 {
+				t.Errorf("invalid request reached the Graph API: %d calls", len(calls))
+			}
+		})
+	}
+}
+
+func TestPublishDispatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   func(srv *fake.Server) facebook.PublishRequest
+		edges []string
+	}{
+		{
+			name: "text",
+			req: func(srv *fake.Server) facebook.PublishRequest {
+				return facebook.PublishRequest{Message: "hello"}
+			},
+			edges: []string{"feed"},
+		},
+		{
+			name: "link",
+			req: func(srv *fake.Server) facebook.PublishRequest {
+				return facebook.PublishRequest{Message: "read this", Link: "https://example.com/article"}
+			},
+			edges: []string{"feed"},
+		},
+		{
+			name: "single photo",
+			req: func(srv *fake.Server) facebook.PublishRequest {
+				return facebook.PublishRequest{Message: "photo", Media: facebook.MediaFromURLs([]string{
+					srv.AddMedia("a.jpg", []byte("jpeg-a")),
+				})}
+			},
+			edges: []string{"photos"},
+		},
+		{
+			name: "multi photo",
+			req: func(srv *fake.Server) facebook.PublishRequest {
+				return facebook.PublishRequest{Message: "photos", Media: facebook.MediaFromURLs([]string{
+					srv.AddMedia("a.jpg", []byte("jpeg-a")),
+					srv.AddMedia("b.jpg", []byte("jpeg-b")),
+				})}
+			},
+			edges: []string{"photos", "photos", "feed"},
+		},
+		{
+			name: "album",
+			req: func(srv *fake.Server) facebook.PublishRequest {
+				return facebook.PublishRequest{Message: "album", AlbumName: "Trip", Media: facebook.MediaFromURLs([]string{
+					srv.AddMedia("a.jpg", []byte("jpeg-a")),
+					srv.AddMedia("b.jpg", []byte("jpeg-b")),
+				})}
+			},
+			edges: []string{"albums", "photos", "photos"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, client := newTestServer(t)
+			req := tt.req(srv)
+			req.PageID, req.AccessToken = testPageID, testPageToken
+
+			result, err := client.Publish(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Publish() error = %v", err)
+			}
+			if result.PostID == "" {
+				t.Error("Publish() returned no post ID")
+			}
+			if got := postEdges(srv); !reflect.DeepEqual(got, tt.edges) {
+				t.Errorf("POST edges = %v, want %v", got, tt.edges)
+			}
+		})
+	}
+}
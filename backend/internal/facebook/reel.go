@@ -0,0 +1,199 @@
+package facebook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Reels requirements
+// (https://developers.facebook.com/docs/video-api/guides/reels-publishing)
+const (
+	ReelMinDuration = 3 * time.Second
+	ReelMaxDuration = 90 * time.Second
+	ReelMinWidth    = 540
+	ReelMinHeight   = 960
+
+	// reelAspectTolerance allows for encoders rounding 9:16 sizes
+	reelAspectTolerance = 0.01
+)
+
+// ValidateReel checks that a video can be published as a reel: 9:16,
+// at least 540x960 and between 3 and 90 seconds long
+func ValidateReel(info *VideoInfo) error {
+	if info.Duration < ReelMinDuration || info.Duration > ReelMaxDuration {
+		return fmt.Errorf("%w: reel duration must be between %v and %v, got %.1fs",
+			ErrInvalidRequest, ReelMinDuration, ReelMaxDuration, info.Duration.Seconds())
+	}
+	if info.Width < ReelMinWidth || info.Height < ReelMinHeight {
+		return fmt.Errorf("%w: reel resolution must be at least %dx%d, got %dx%d",
+			ErrInvalidRequest, ReelMinWidth, ReelMinHeight, info.Width, info.Height)
+	}
+	ratio := float64(info.Width) / float64(info.Height)
+	if ratio < 9.0/16.0-reelAspectTolerance || ratio > 9.0/16.0+reelAspectTolerance {
+		return fmt.Errorf("%w: reel aspect ratio must be 9:16, got %dx%d",
+			ErrInvalidRequest, info.Width, info.Height)
+	}
+	return nil
+}
+
+// publishReel uploads a video with the page video_reels flow: start a
+// session, send the bytes to the returned upload URL in chunks, then finish with the
+// description. The video is validated locally before anything is uploaded.
+func (c *Client) publishReel(ctx context.Context, req PublishRequest) (*PublishResult, error) {
+	src, size, cleanup, err := c.spoolMedia(ctx, req.Media[0])
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	info, err := ProbeVideo(src, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot read reel video: %v", ErrInvalidRequest, err)
+	}
+	if err := ValidateReel(info); err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("🎬 Uploading reel to page %s (%.2f MB, %dx%d, %.1fs)...\n",
+		req.PageID, float64(size)/(1024*1024), info.Width, info.Height, info.Duration.Seconds())
+
+	path := fmt.Sprintf("/%s/video_reels", req.PageID)
+	startParams := url.Values{}
+	startParams.Set("upload_phase", "start")
+	body, err := c.postForm(ctx, path, req.AccessToken, startParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start reel upload: %w", err)
+	}
+	var session struct {
+		VideoID   string `json:"video_id"`
+		UploadURL string `json:"upload_url"`
+	}
+	if err := json.Unmarshal(body, &session); err != nil || session.VideoID == "" || session.UploadURL == "" {
+		return nil, fmt.Errorf("failed to parse reel upload session: %s", string(body))
+	}
+
+	if err := c.ruploadVideo(ctx, session.VideoID, session.UploadURL, req.AccessToken, src, size, req.OnProgress); err != nil {
+		return nil, fmt.Errorf("failed to upload reel: %w", err)
+	}
+
+	params := req.postParams("description")
+	params.Del("published")
+	params.Set("upload_phase", "finish")
+	params.Set("video_id", session.VideoID)
+	switch {
+	case !req.ScheduledPublishTime.IsZero():
+		params.Set("video_state", "SCHEDULED")
+	case req.Published != nil && !*req.Published:
+		params.Set("video_state", "DRAFT")
+	default:
+		params.Set("video_state", "PUBLISHED")
+	}
+
+	body, err = c.postForm(ctx, path, req.AccessToken, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to finish reel upload: %w", err)
+	}
+	var finish struct {
+		Success bool   `json:"success"`
+		PostID  string `json:"post_id"`
+	}
+	if err := json.Unmarshal(body, &finish); err != nil || !finish.Success {
+		return nil, fmt.Errorf("reel was not published: %s", string(body))
+	}
+
+	postID := finish.PostID
+	if postID == "" {
+		postID = session.VideoID
+	}
+	return &PublishResult{PostID: postID, MediaIDs: []string{session.VideoID}, Response: body}, nil
+}
+
+// ruploadChunkSize is how many bytes one rupload request carries
+const ruploadChunkSize = 4 << 20
+
+// ruploadVideo sends the file to a rupload upload_url (reels and video
+// stories) chunk by chunk, each request starting at its "offset" header.
+// A failed chunk is retried from the offset Facebook reports it has
+// received, so an interrupted upload resumes instead of starting over.
+func (c *Client) ruploadVideo(ctx context.Context, videoID, uploadURL, accessToken string, src io.ReaderAt, size int64, progress ProgressFunc) error {
+	var offset int64
+	failures := 0
+	for offset < size {
+		end := min(offset+ruploadChunkSize, size)
+		err := c.ruploadChunk(ctx, uploadURL, accessToken, src, offset, end, size)
+		if err == nil {
+			offset, failures = end, 0
+			if progress != nil {
+				progress(offset, size)
+			}
+			continue
+		}
+
+		if graphErr, ok := AsGraphError(err); ok && !graphErr.IsTransient() {
+			return err
+		}
+		failures++
+		if failures >= uploadChunkRetries {
+			return fmt.Errorf("chunk at offset %d: %w", offset, err)
+		}
+		select {
+		case <-time.After(uploadChunkRetryDelay * time.Duration(failures)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		// Facebook may have kept part (or all) of the failed chunk
+		if received, err := c.ruploadOffset(ctx, videoID, accessToken); err == nil && received <= size {
+			offset = received
+		}
+		fmt.Printf("🔄 Resuming video upload at offset %d (attempt %d/%d)\n", offset, failures+1, uploadChunkRetries)
+	}
+	return nil
+}
+
+// ruploadChunk sends bytes [start, end) with its own deadline over the
+// media client
+func (c *Client) ruploadChunk(ctx context.Context, uploadURL, accessToken string, src io.ReaderAt, start, end, size int64) error {
+	ctx, cancel := context.WithTimeout(ctx, uploadChunkTimeout)
+	defer cancel()
+
+	httpReq, err := c.newGraphRequest(ctx, http.MethodPost, uploadURL, accessToken, nil, io.NewSectionReader(src, start, end-start))
+	if err != nil {
+		return err
+	}
+	httpReq.ContentLength = end - start
+	httpReq.Header.Set("offset", strconv.FormatInt(start, 10))
+	httpReq.Header.Set("file_size", strconv.FormatInt(size, 10))
+	httpReq.Header.Set("Content-Type", "application/octet-stream")
+
+	_, err = c.doGraphWith(c.mediaClient, httpReq)
+	return err
+}
+
+// ruploadOffset reads how many bytes of a rupload Facebook has received
+// (status.uploading_phase.bytes_transferred)
+func (c *Client) ruploadOffset(ctx context.Context, videoID, accessToken string) (int64, error) {
+	params := url.Values{}
+	params.Set("fields", "status")
+	body, err := c.getGraph(ctx, "/"+videoID, accessToken, params)
+	if err != nil {
+		return 0, err
+	}
+	var result struct {
+		Status struct {
+			UploadingPhase struct {
+				BytesTransferred graphOffset `json:"bytes_transferred"`
+			} `json:"uploading_phase"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, fmt.Errorf("failed to parse upload status: %s", string(body))
+	}
+	return int64(result.Status.UploadingPhase.BytesTransferred), nil
+}
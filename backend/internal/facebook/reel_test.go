@@ -0,0 +1,88 @@
+package facebook_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"fbscheduler/internal/facebook"
+	"fbscheduler/internal/facebook/fake"
+)
+
+func TestValidateReel(t *testing.T) {
+	tests := []struct {
+		name    string
+		info    facebook.VideoInfo
+		wantErr bool
+	}{
+		{"9:16 full HD", facebook.VideoInfo{Width: 1080, Height: 1920, Duration: 30 * time.Second}, false},
+		{"minimum size", facebook.VideoInfo{Width: 540, Height: 960, Duration: 3 * time.Second}, false},
+		{"too short", facebook.VideoInfo{Width: 1080, Height: 1920, Duration: 2 * time.Second}, true},
+		{"too long", facebook.VideoInfo{Width: 1080, Height: 1920, Duration: 91 * time.Second}, true},
+		{"too small", facebook.VideoInfo{Width: 360, Height: 640, Duration: 10 * time.Second}, true},
+		{"landscape", facebook.VideoInfo{Width: 1920, Height: 1080, Duration: 10 * time.Second}, true},
+		{"square", facebook.VideoInfo{Width: 1080, Height: 1080, Duration: 10 * time.Second}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := facebook.ValidateReel(&tt.info)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateReel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, facebook.ErrInvalidRequest) {
+				t.Errorf("ValidateReel() error = %v, want ErrInvalidRequest", err)
+			}
+		})
+	}
+}
+
+func TestPublishReelResumesUpload(t *testing.T) {
+	srv, client := newTestServer(t)
+	// Two rupload chunks; the first reaches Facebook but the response is lost
+	video := buildMP4(testVideo{width: 1080, height: 1920, duration: 15 * time.Second, padding: 5 << 20})
+	srv.FailNext("video-upload", fake.Error{Status: 500, Code: 2, Message: "timeout", IsTransient: true, Committed: true})
+
+	result, err := client.Publish(context.Background(), facebook.PublishRequest{
+		PageID: testPageID, AccessToken: testPageToken, MediaType: facebook.MediaTypeReel,
+		Media: []facebook.MediaItem{{Reader: bytes.NewReader(video)}},
+	})
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	var offsets []string
+	for _, call := range srv.CallsTo("video-upload") {
+		offsets = append(offsets, call.Header.Get("offset"))
+	}
+	want := []string{"0", "4194304"}
+	if len(offsets) != len(want) || offsets[0] != want[0] || offsets[1] != want[1] {
+		t.Errorf("rupload offsets = %v, want %v (resume after the committed chunk)", offsets, want)
+	}
+	uploaded, ok := srv.Reel(result.MediaIDs[0])
+	if !ok || !bytes.Equal(uploaded, video) {
+		t.Errorf("uploaded reel has %d bytes, want the %d bytes of the file", len(uploaded), len(video))
+	}
+}
+
+func TestPublishReel(t *testing.T) {
+	srv, client := newTestServer(t)
+	video := buildMP4(testVideo{width: 1080, height: 1920, duration: 15 * time.Second, padding: 1024})
+
+	result, err := client.Publish(context.Background(), facebook.PublishRequest{
+		PageID: testPageID, AccessToken: testPageToken, Message: "reel", MediaType: facebook.MediaTypeReel,
+		Media: []facebook.MediaItem{{URL: srv.AddMedia("reel.mp4", video)}},
+	})
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if result.PostID == "" {
+		t.Error("Publish() returned no post ID")
+	}
+	want := []string{"video_reels", "video-upload", "video_reels"}
+	if got := postEdges(srv); !reflect.DeepEqual(got, want) {
+		t.Errorf("POST edges = %v, want %v", got, want)
+	}
+}
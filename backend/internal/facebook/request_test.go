@@ -0,0 +1,100 @@
+package facebook_test
+
+import (
+	"context"
+	"testing"
+
+	"fbscheduler/internal/facebook"
+	"fbscheduler/internal/facebook/fake"
+)
+
+func TestAppSecretProof(t *testing.T) {
+	tests := []struct {
+		token, secret, want string
+	}{
+		// echo -n page-token | openssl dgst -sha256 -hmac secret
+		{testPageToken, "secret", "58c48136d9507c3c8f5112ca4b42928f913c3338700a30bf9a6626368ff7748b"},
+		{testPageToken, "other", ""},
+		{"other-token", "secret", ""},
+	}
+	for _, tt := range tests {
+		got := facebook.AppSecretProof(tt.token, tt.secret)
+		if tt.want != "" && got != tt.want {
+			t.Errorf("AppSecretProof(%q, %q) = %s, want %s", tt.token, tt.secret, got, tt.want)
+		}
+		if tt.want == "" && got == tests[0].want {
+			t.Errorf("AppSecretProof(%q, %q) collides with the proof of %q/%q", tt.token, tt.secret, tests[0].token, tests[0].secret)
+		}
+	}
+}
+
+func TestAppSecretProofRequired(t *testing.T) {
+	tests := []struct {
+		name      string
+		appSecret string
+		wantErr   bool
+	}{
+		{"matching secret", "secret", false},
+		{"wrong secret", "wrong", true},
+		{"no secret", "", true},
+	}
+
+	publishers := []struct {
+		name    string
+		publish func(client *facebook.Client) error
+	}{
+		{"publish", func(client *facebook.Client) error {
+			_, err := client.Publish(context.Background(), facebook.PublishRequest{
+				PageID: testPageID, AccessToken: testPageToken, Message: "hello",
+			})
+			return err
+		}},
+		{"batch", func(client *facebook.Client) error {
+			results := client.PublishBatch(context.Background(), []facebook.PublishRequest{
+				{PageID: testPageID, AccessToken: testPageToken, Message: "hello"},
+			})
+			return results[0].Err
+		}},
+	}
+
+	for _, p := range publishers {
+		for _, tt := range tests {
+			t.Run(p.name+"/"+tt.name, func(t *testing.T) {
+				srv := fake.NewServer()
+				defer srv.Close()
+				srv.RequireAppSecret("secret")
+				client := srv.Client(facebook.WithAppSecret(tt.appSecret))
+
+				err := p.publish(client)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if tt.wantErr {
+					return
+				}
+				for _, call := range srv.CallsTo("feed") {
+					if call.Param("appsecret_proof") != facebook.AppSecretProof(testPageToken, "secret") {
+						t.Errorf("feed call has appsecret_proof %q", call.Param("appsecret_proof"))
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestAccessTokenNotInURL(t *testing.T) {
+	srv, client := newTestServer(t)
+	if _, err := client.GetPublishedPost(context.Background(), "123_1", testPageToken); err != nil {
+		t.Fatalf("GetPublishedPost() error = %v", err)
+	}
+	calls := srv.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("got %d calls, want 1", len(calls))
+	}
+	if calls[0].Query.Get("access_token") != "" {
+		t.Error("access token was sent in the query string")
+	}
+	if got := calls[0].AccessToken(); got != testPageToken {
+		t.Errorf("Authorization token = %q, want %q", got, testPageToken)
+	}
+}
@@ -0,0 +1,212 @@
+package facebook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// Video story requirements
+// (https://developers.facebook.com/docs/page-stories-api)
+const (
+	StoryMinDuration = 3 * time.Second
+	StoryMaxDuration = 60 * time.Second
+	StoryMinWidth    = 540
+	StoryMinHeight   = 960
+)
+
+// videoExtensions marks story media that is published as a video story
+var videoExtensions = map[string]bool{".mp4": true, ".mov": true, ".m4v": true}
+
+// mediaHeadTimeout bounds the HEAD request that reads a media URL's Content-Type
+const mediaHeadTimeout = 10 * time.Second
+
+// ValidateStoryVideo checks the duration and resolution of a video story
+func ValidateStoryVideo(info *VideoInfo) error {
+	if info.Duration < StoryMinDuration || info.Duration > StoryMaxDuration {
+		return fmt.Errorf("%w: story duration must be between %v and %v, got %.1fs",
+			ErrInvalidRequest, StoryMinDuration, StoryMaxDuration, info.Duration.Seconds())
+	}
+	if info.Width < StoryMinWidth || info.Height < StoryMinHeight {
+		return fmt.Errorf("%w: story resolution must be at least %dx%d, got %dx%d",
+			ErrInvalidRequest, StoryMinWidth, StoryMinHeight, info.Width, info.Height)
+	}
+	return nil
+}
+
+// IsVideoMedia reports whether item looks like a video, judging by the
+// extension of its Filename, Path or URL
+func IsVideoMedia(item MediaItem) bool {
+	for _, name := range []string{item.Filename, item.Path, item.URL} {
+		if name == "" {
+			continue
+		}
+		if u, err := url.Parse(name); err == nil && u.Scheme != "" {
+			name = u.Path
+		}
+		return videoExtensions[strings.ToLower(path.Ext(name))]
+	}
+	return false
+}
+
+// isVideoMedia decides whether item is a video. The post media type wins when
+// it names one; otherwise the Content-Type of the file (sniffed) or URL (HEAD)
+// is used, so CDN and signed URLs without an extension are not mistaken for
+// photos. The extension is the last resort.
+func (c *Client) isVideoMedia(ctx context.Context, mediaType string, item MediaItem) bool {
+	switch mediaType {
+	case MediaTypeVideo, MediaTypeReel:
+		return true
+	case MediaTypePhoto:
+		return false
+	}
+
+	mimeType, _, _ := mime.ParseMediaType(c.mediaContentType(ctx, item))
+	switch {
+	case strings.HasPrefix(mimeType, "video/"):
+		return true
+	case strings.HasPrefix(mimeType, "image/"):
+		return false
+	}
+	return IsVideoMedia(item)
+}
+
+// mediaContentType sniffs a local file or asks the media host with a HEAD
+// request. It returns "" when neither is possible.
+func (c *Client) mediaContentType(ctx context.Context, item MediaItem) string {
+	switch {
+	case item.Reader != nil:
+		return ""
+	case item.Path != "":
+		f, err := os.Open(item.Path)
+		if err != nil {
+			return ""
+		}
+		defer f.Close()
+		head := make([]byte, 512)
+		n, _ := io.ReadFull(f, head)
+		return http.DetectContentType(head[:n])
+	case item.URL != "":
+		ctx, cancel := context.WithTimeout(ctx, mediaHeadTimeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, item.URL, nil)
+		if err != nil {
+			return ""
+		}
+		resp, err := c.mediaClient.Do(req)
+		if err != nil {
+			return ""
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return ""
+		}
+		return resp.Header.Get("Content-Type")
+	}
+	return ""
+}
+
+// publishStory publishes a page story. Photos are uploaded unpublished and
+// attached with photo_stories; videos go through the video_stories
+// start / upload / finish flow. Stories have no caption, so Message is not sent.
+func (c *Client) publishStory(ctx context.Context, req PublishRequest) (*PublishResult, error) {
+	if c.isVideoMedia(ctx, req.MediaType, req.Media[0]) {
+		return c.publishVideoStory(ctx, req)
+	}
+	return c.publishPhotoStory(ctx, req)
+}
+
+func (c *Client) publishPhotoStory(ctx context.Context, req PublishRequest) (*PublishResult, error) {
+	fmt.Printf("📲 Publishing photo story to page %s...\n", req.PageID)
+
+	params := url.Values{}
+	params.Set("published", "false")
+	body, err := c.uploadMedia(ctx, fmt.Sprintf("/%s/photos", req.PageID), req.AccessToken, params, req.Media[0], "story.jpg")
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload story photo: %w", err)
+	}
+	photoID, err := parseObjectID(body)
+	if err != nil {
+		return nil, err
+	}
+
+	storyParams := url.Values{}
+	storyParams.Set("photo_id", photoID)
+	body, err = c.postForm(ctx, fmt.Sprintf("/%s/photo_stories", req.PageID), req.AccessToken, storyParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish photo story: %w", err)
+	}
+	return parseStoryResult(body, photoID)
+}
+
+func (c *Client) publishVideoStory(ctx context.Context, req PublishRequest) (*PublishResult, error) {
+	src, size, cleanup, err := c.spoolMedia(ctx, req.Media[0])
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	info, err := ProbeVideo(src, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot read story video: %v", ErrInvalidRequest, err)
+	}
+	if err := ValidateStoryVideo(info); err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("📲 Publishing video story to page %s (%.2f MB, %.1fs)...\n",
+		req.PageID, float64(size)/(1024*1024), info.Duration.Seconds())
+
+	edge := fmt.Sprintf("/%s/video_stories", req.PageID)
+	startParams := url.Values{}
+	startParams.Set("upload_phase", "start")
+	body, err := c.postForm(ctx, edge, req.AccessToken, startParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start story upload: %w", err)
+	}
+	var session struct {
+		VideoID   string `json:"video_id"`
+		UploadURL string `json:"upload_url"`
+	}
+	if err := json.Unmarshal(body, &session); err != nil || session.VideoID == "" || session.UploadURL == "" {
+		return nil, fmt.Errorf("failed to parse story upload session: %s", string(body))
+	}
+
+	if err := c.ruploadVideo(ctx, session.VideoID, session.UploadURL, req.AccessToken, src, size, req.OnProgress); err != nil {
+		return nil, fmt.Errorf("failed to upload story video: %w", err)
+	}
+
+	finishParams := url.Values{}
+	finishParams.Set("upload_phase", "finish")
+	finishParams.Set("video_id", session.VideoID)
+	body, err = c.postForm(ctx, edge, req.AccessToken, finishParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to finish story upload: %w", err)
+	}
+	return parseStoryResult(body, session.VideoID)
+}
+
+// parseStoryResult reads {"success", "post_id"} from photo_stories and
+// video_stories. The story ID falls back to the media ID.
+func parseStoryResult(body []byte, mediaID string) (*PublishResult, error) {
+	var result struct {
+		Success bool   `json:"success"`
+		PostID  string `json:"post_id"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil || !result.Success {
+		return nil, fmt.Errorf("story was not published: %s", string(body))
+	}
+	postID := result.PostID
+	if postID == "" {
+		postID = mediaID
+	}
+	return &PublishResult{PostID: postID, MediaIDs: []string{mediaID}, Response: body}, nil
+}
@@ -0,0 +1,64 @@
+package facebook_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"fbscheduler/internal/facebook"
+	"fbscheduler/internal/facebook/fake"
+)
+
+func TestPublishStory(t *testing.T) {
+	tests := []struct {
+		name  string
+		media func(srv *fake.Server) string
+		edges []string
+	}{
+		{
+			name: "photo story",
+			media: func(srv *fake.Server) string {
+				return srv.AddMedia("story.jpg", []byte("jpeg-story"))
+			},
+			edges: []string{"photos", "photo_stories"},
+		},
+		{
+			name: "video story",
+			media: func(srv *fake.Server) string {
+				return srv.AddMedia("story.mp4", buildMP4(testVideo{
+					width: 1080, height: 1920, duration: 20 * time.Second, padding: 1024,
+				}))
+			},
+			edges: []string{"video_stories", "video-upload", "video_stories"},
+		},
+		{
+			name: "video story from a URL without extension",
+			media: func(srv *fake.Server) string {
+				return srv.AddMedia("signed-video-token", buildMP4(testVideo{
+					width: 1080, height: 1920, duration: 20 * time.Second, padding: 1024,
+				}))
+			},
+			edges: []string{"video_stories", "video-upload", "video_stories"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, client := newTestServer(t)
+			result, err := client.Publish(context.Background(), facebook.PublishRequest{
+				PageID: testPageID, AccessToken: testPageToken, MediaType: facebook.MediaTypeStory,
+				Media: []facebook.MediaItem{{URL: tt.media(srv)}},
+			})
+			if err != nil {
+				t.Fatalf("Publish() error = %v", err)
+			}
+			if result.PostID == "" {
+				t.Error("Publish() returned no post ID")
+			}
+			if got := postEdges(srv); !reflect.DeepEqual(got, tt.edges) {
+				t.Errorf("POST edges = %v, want %v", got, tt.edges)
+			}
+		})
+	}
+}
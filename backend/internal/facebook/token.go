@@ -0,0 +1,116 @@
+package facebook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+)
+
+// AccessToken is a token returned by oauth/access_token. ExpiresAt is zero
+// when Facebook did not report an expiry.
+type AccessToken struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// TokenDebugInfo is what debug_token reports about a user or page token
+type TokenDebugInfo struct {
+	AppID   string
+	Type    string // USER or PAGE
+	UserID  string
+	IsValid bool
+	// ExpiresAt is zero for tokens that never expire (page tokens derived
+	// from a long-lived user token)
+	ExpiresAt time.Time
+	// DataAccessExpiresAt is when the user's data access must be renewed
+	// by logging in again, even if the token itself never expires
+	DataAccessExpiresAt time.Time
+	Scopes              []string
+}
+
+// HasScope reports whether the token was granted scope
+func (t *TokenDebugInfo) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ExchangeLongLivedToken swaps a short-lived user token for a long-lived
+// one (about 60 days). Page tokens fetched with the long-lived token from
+// me/accounts do not expire.
+func (c *Client) ExchangeLongLivedToken(ctx context.Context, shortLivedToken string) (*AccessToken, error) {
+	params := url.Values{}
+	params.Set("grant_type", "fb_exchange_token")
+	params.Set("client_id", os.Getenv("FACEBOOK_APP_ID"))
+	params.Set("client_secret", c.appSecret)
+	params.Set("fb_exchange_token", shortLivedToken)
+
+	body, err := c.getGraph(ctx, "/oauth/access_token", "", params)
+	if err != nil {
+		return nil, fmt.Errorf("facebook long-lived token exchange error: %w", err)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil || result.AccessToken == "" {
+		return nil, fmt.Errorf("no access token in long-lived token response: %s", string(body))
+	}
+
+	token := &AccessToken{Token: result.AccessToken}
+	if result.ExpiresIn > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// DebugToken inspects a token with the app access token
+// (FACEBOOK_APP_ID|app secret)
+func (c *Client) DebugToken(ctx context.Context, inputToken string) (*TokenDebugInfo, error) {
+	params := url.Values{}
+	params.Set("input_token", inputToken)
+	appToken := os.Getenv("FACEBOOK_APP_ID") + "|" + c.appSecret
+
+	body, err := c.getGraph(ctx, "/debug_token", appToken, params)
+	if err != nil {
+		return nil, fmt.Errorf("facebook debug_token error: %w", err)
+	}
+
+	var result struct {
+		Data struct {
+			AppID               string   `json:"app_id"`
+			Type                string   `json:"type"`
+			UserID              string   `json:"user_id"`
+			IsValid             bool     `json:"is_valid"`
+			ExpiresAt           int64    `json:"expires_at"`
+			DataAccessExpiresAt int64    `json:"data_access_expires_at"`
+			Scopes              []string `json:"scopes"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse debug_token response: %s", string(body))
+	}
+
+	data := result.Data
+	info := &TokenDebugInfo{
+		AppID:   data.AppID,
+		Type:    data.Type,
+		UserID:  data.UserID,
+		IsValid: data.IsValid,
+		Scopes:  data.Scopes,
+	}
+	if data.ExpiresAt > 0 {
+		info.ExpiresAt = time.Unix(data.ExpiresAt, 0)
+	}
+	if data.DataAccessExpiresAt > 0 {
+		info.DataAccessExpiresAt = time.Unix(data.DataAccessExpiresAt, 0)
+	}
+	return info, nil
+}
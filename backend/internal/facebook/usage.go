@@ -0,0 +1,157 @@
+package facebook
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Usage headers Facebook attaches to Graph API responses
+// (https://developers.facebook.com/docs/graph-api/overview/rate-limiting)
+const (
+	HeaderAppUsage             = "X-App-Usage"
+	HeaderPageUsage            = "X-Page-Usage"
+	HeaderBusinessUseCaseUsage = "X-Business-Use-Case-Usage"
+)
+
+// UsageStats is how much of a rate limit has been used, in percent
+type UsageStats struct {
+	CallCount    int `json:"call_count"`
+	TotalCPUTime int `json:"total_cputime"`
+	TotalTime    int `json:"total_time"`
+	// EstimatedTimeToRegainAccess is in minutes; non-zero once throttled
+	EstimatedTimeToRegainAccess int `json:"estimated_time_to_regain_access,omitempty"`
+}
+
+// Max returns the highest of the three percentages
+func (s UsageStats) Max() int {
+	return max(s.CallCount, s.TotalCPUTime, s.TotalTime)
+}
+
+// BusinessUseCaseUsage is one entry of X-Business-Use-Case-Usage
+type BusinessUseCaseUsage struct {
+	Type string `json:"type"`
+	UsageStats
+}
+
+// Usage is the latest throttling snapshot Facebook reported for a token.
+// App is shared by every token of the app; Page and BusinessUseCase (keyed
+// by page ID) belong to the page the token was used for.
+type Usage struct {
+	App             *UsageStats                       `json:"app,omitempty"`
+	Page            *UsageStats                       `json:"page,omitempty"`
+	BusinessUseCase map[string][]BusinessUseCaseUsage `json:"business_use_case,omitempty"`
+	UpdatedAt       time.Time                         `json:"updated_at"`
+}
+
+// AppPercent is the app-level usage in percent
+func (u Usage) AppPercent() int {
+	if u.App == nil {
+		return 0
+	}
+	return u.App.Max()
+}
+
+// PagePercent is the highest page-level usage in percent, from X-Page-Usage
+// and the business use case entries
+func (u Usage) PagePercent() int {
+	percent := 0
+	if u.Page != nil {
+		percent = u.Page.Max()
+	}
+	for _, entries := range u.BusinessUseCase {
+		for _, entry := range entries {
+			percent = max(percent, entry.Max())
+		}
+	}
+	return percent
+}
+
+// MaxPercent is the highest usage of any limit, in percent
+func (u Usage) MaxPercent() int {
+	return max(u.AppPercent(), u.PagePercent())
+}
+
+// RegainAccessIn is the longest estimated_time_to_regain_access reported,
+// zero when nothing is throttled
+func (u Usage) RegainAccessIn() time.Duration {
+	minutes := 0
+	for _, stats := range []*UsageStats{u.App, u.Page} {
+		if stats != nil {
+			minutes = max(minutes, stats.EstimatedTimeToRegainAccess)
+		}
+	}
+	for _, entries := range u.BusinessUseCase {
+		for _, entry := range entries {
+			minutes = max(minutes, entry.EstimatedTimeToRegainAccess)
+		}
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// parseUsage reads the usage headers; ok is false when none are present
+func parseUsage(header http.Header) (usage Usage, ok bool) {
+	if v := header.Get(HeaderAppUsage); v != "" {
+		var stats UsageStats
+		if json.Unmarshal([]byte(v), &stats) == nil {
+			usage.App, ok = &stats, true
+		}
+	}
+	if v := header.Get(HeaderPageUsage); v != "" {
+		var stats UsageStats
+		if json.Unmarshal([]byte(v), &stats) == nil {
+			usage.Page, ok = &stats, true
+		}
+	}
+	if v := header.Get(HeaderBusinessUseCaseUsage); v != "" {
+		var buc map[string][]BusinessUseCaseUsage
+		if json.Unmarshal([]byte(v), &buc) == nil {
+			usage.BusinessUseCase, ok = buc, true
+		}
+	}
+	usage.UpdatedAt = time.Now()
+	return usage, ok
+}
+
+// recordUsage merges the usage headers of a response into the snapshot
+// kept for accessToken. Headers missing from this response keep their
+// previous value.
+func (c *Client) recordUsage(accessToken string, header http.Header) {
+	if accessToken == "" {
+		return
+	}
+	usage, ok := parseUsage(header)
+	if !ok {
+		return
+	}
+
+	c.usageMu.Lock()
+	defer c.usageMu.Unlock()
+	if prev, found := c.usage[accessToken]; found {
+		if usage.App == nil {
+			usage.App = prev.App
+		}
+		if usage.Page == nil {
+			usage.Page = prev.Page
+		}
+		if usage.BusinessUseCase == nil {
+			usage.BusinessUseCase = prev.BusinessUseCase
+		}
+	}
+	c.usage[accessToken] = usage
+}
+
+// Usage returns the latest throttling snapshot for a page or user token.
+// ok is false until a response for that token carried usage headers.
+func (c *Client) Usage(accessToken string) (usage Usage, ok bool) {
+	c.usageMu.Lock()
+	defer c.usageMu.Unlock()
+	usage, ok = c.usage[accessToken]
+	return usage, ok
+}
+
+// requestToken is the token a request was sent with
+func requestToken(req *http.Request) string {
+	return strings.TrimPrefix(req.Header.Get("Authorization"), "OAuth ")
+}
@@ -0,0 +1,130 @@
+package facebook_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fbscheduler/internal/facebook"
+)
+
+func TestUsageHeaders(t *testing.T) {
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantOK     bool
+		appPercent int
+		pagePct    int
+		maxPercent int
+		regain     time.Duration
+	}{
+		{
+			name:   "no headers",
+			wantOK: false,
+		},
+		{
+			name:       "app usage",
+			headers:    map[string]string{facebook.HeaderAppUsage: `{"call_count":12,"total_cputime":40,"total_time":7}`},
+			wantOK:     true,
+			appPercent: 40,
+			maxPercent: 40,
+		},
+		{
+			name:       "page usage",
+			headers:    map[string]string{facebook.HeaderPageUsage: `{"call_count":85,"total_cputime":3,"total_time":3}`},
+			wantOK:     true,
+			pagePct:    85,
+			maxPercent: 85,
+		},
+		{
+			name: "business use case throttled",
+			headers: map[string]string{
+				facebook.HeaderAppUsage:             `{"call_count":5,"total_cputime":5,"total_time":5}`,
+				facebook.HeaderBusinessUseCaseUsage: `{"123":[{"type":"pages","call_count":100,"total_cputime":20,"total_time":30,"estimated_time_to_regain_access":7}]}`,
+			},
+			wantOK:     true,
+			appPercent: 5,
+			pagePct:    100,
+			maxPercent: 100,
+			regain:     7 * time.Minute,
+		},
+		{
+			name:    "malformed header is ignored",
+			headers: map[string]string{facebook.HeaderAppUsage: `not json`},
+			wantOK:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, client := newTestServer(t)
+			for header, value := range tt.headers {
+				srv.SetUsage(header, value)
+			}
+			if _, err := client.GetPublishedPost(context.Background(), "123_1", testPageToken); err != nil {
+				t.Fatalf("GetPublishedPost() error = %v", err)
+			}
+
+			usage, ok := client.Usage(testPageToken)
+			if ok != tt.wantOK {
+				t.Fatalf("Usage() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if got := usage.AppPercent(); got != tt.appPercent {
+				t.Errorf("AppPercent() = %d, want %d", got, tt.appPercent)
+			}
+			if got := usage.PagePercent(); got != tt.pagePct {
+				t.Errorf("PagePercent() = %d, want %d", got, tt.pagePct)
+			}
+			if got := usage.MaxPercent(); got != tt.maxPercent {
+				t.Errorf("MaxPercent() = %d, want %d", got, tt.maxPercent)
+			}
+			if got := usage.RegainAccessIn(); got != tt.regain {
+				t.Errorf("RegainAccessIn() = %v, want %v", got, tt.regain)
+			}
+		})
+	}
+}
+
+func TestUsageKeepsMissingHeaders(t *testing.T) {
+	srv, client := newTestServer(t)
+	ctx := context.Background()
+
+	srv.SetUsage(facebook.HeaderAppUsage, `{"call_count":30,"total_cputime":1,"total_time":1}`)
+	srv.SetUsage(facebook.HeaderPageUsage, `{"call_count":60,"total_cputime":1,"total_time":1}`)
+	client.GetPublishedPost(ctx, "123_1", testPageToken)
+
+	// The next response only reports the app usage: the page usage is kept
+	srv.SetUsage(facebook.HeaderPageUsage, "")
+	srv.SetUsage(facebook.HeaderAppUsage, `{"call_count":35,"total_cputime":1,"total_time":1}`)
+	client.GetPublishedPost(ctx, "123_1", testPageToken)
+
+	usage, ok := client.Usage(testPageToken)
+	if !ok {
+		t.Fatal("Usage() has no snapshot")
+	}
+	if usage.AppPercent() != 35 || usage.PagePercent() != 60 {
+		t.Errorf("Usage() app = %d%%, page = %d%%, want 35%% and 60%%", usage.AppPercent(), usage.PagePercent())
+	}
+	if _, ok := client.Usage("other-token"); ok {
+		t.Error("Usage() reported a snapshot for a token that was never used")
+	}
+}
+
+func TestUsageFromBatch(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.SetUsage(facebook.HeaderPageUsage, `{"call_count":91,"total_cputime":1,"total_time":1}`)
+
+	results := client.PublishBatch(context.Background(), []facebook.PublishRequest{
+		{PageID: testPageID, AccessToken: testPageToken, Message: "hello"},
+	})
+	if results[0].Err != nil {
+		t.Fatalf("PublishBatch() error = %v", results[0].Err)
+	}
+	usage, ok := client.Usage(testPageToken)
+	if !ok || usage.PagePercent() != 91 {
+		t.Errorf("Usage() = %+v, %v, want page usage 91%% from the batch operation headers", usage, ok)
+	}
+}
@@ -0,0 +1,212 @@
+package facebook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Resumable upload tuning
+// (https://developers.facebook.com/docs/video-api/guides/publishing)
+const (
+	uploadChunkRetries    = 3
+	uploadChunkRetryDelay = 2 * time.Second
+
+	// uploadChunkTimeout bounds one transfer request (one chunk)
+	uploadChunkTimeout = 2 * time.Minute
+	// mediaDownloadTimeout bounds downloading one media URL
+	mediaDownloadTimeout = 30 * time.Minute
+)
+
+// ProgressFunc reports how many bytes of a video have been uploaded
+type ProgressFunc func(uploaded, total int64)
+
+// uploadSession is returned by the start and transfer phases
+type uploadSession struct {
+	VideoID         string      `json:"video_id"`
+	UploadSessionID string      `json:"upload_session_id"`
+	StartOffset     graphOffset `json:"start_offset"`
+	EndOffset       graphOffset `json:"end_offset"`
+}
+
+// graphOffset accepts offsets encoded either as JSON strings or numbers
+type graphOffset int64
+
+func (o *graphOffset) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		*o = graphOffset(n)
+		return nil
+	}
+	var n int64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*o = graphOffset(n)
+	return nil
+}
+
+// sizedReaderAt is implemented by *bytes.Reader, *strings.Reader and
+// *io.SectionReader, which can be chunked without copying to disk
+type sizedReaderAt interface {
+	io.ReaderAt
+	Size() int64
+}
+
+// publishVideo uploads a page video with the resumable upload protocol:
+// start a session, transfer the file chunk by chunk, then finish with the
+// post fields. Only one chunk is held in memory at a time.
+func (c *Client) publishVideo(ctx context.Context, req PublishRequest) (*PublishResult, error) {
+	src, size, cleanup, err := c.spoolMedia(ctx, req.Media[0])
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	fmt.Printf("📹 Uploading video to page %s (%.2f MB)...\n", req.PageID, float64(size)/(1024*1024))
+
+	videoID, err := c.uploadVideoResumable(ctx, fmt.Sprintf("/%s/videos", req.PageID), req.AccessToken, src, size, req.postParams("description"), req.OnProgress)
+	if err != nil {
+		return nil, err
+	}
+	return &PublishResult{PostID: videoID, MediaIDs: []string{videoID}}, nil
+}
+
+// uploadVideoResumable runs start / transfer / finish against path and
+// returns the video ID
+func (c *Client) uploadVideoResumable(ctx context.Context, path, accessToken string, src io.ReaderAt, size int64, finishParams url.Values, progress ProgressFunc) (string, error) {
+	startParams := url.Values{}
+	startParams.Set("upload_phase", "start")
+	startParams.Set("file_size", strconv.FormatInt(size, 10))
+
+	body, err := c.postForm(ctx, path, accessToken, startParams)
+	if err != nil {
+		return "", fmt.Errorf("failed to start video upload: %w", err)
+	}
+	var session uploadSession
+	if err := json.Unmarshal(body, &session); err != nil {
+		return "", fmt.Errorf("failed to parse upload session: %s", string(body))
+	}
+
+	start, end := int64(session.StartOffset), int64(session.EndOffset)
+	for start < end {
+		next, err := c.transferChunk(ctx, path, accessToken, session.UploadSessionID, src, start, end)
+		if err != nil {
+			return "", fmt.Errorf("failed to upload video chunk at offset %d: %w", start, err)
+		}
+		start, end = int64(next.StartOffset), int64(next.EndOffset)
+		if progress != nil {
+			progress(start, size)
+		}
+	}
+
+	params := url.Values{}
+	for key, values := range finishParams {
+		params[key] = values
+	}
+	params.Set("upload_phase", "finish")
+	params.Set("upload_session_id", session.UploadSessionID)
+
+	if _, err := c.postForm(ctx, path, accessToken, params); err != nil {
+		return "", fmt.Errorf("failed to finish video upload: %w", err)
+	}
+	return session.VideoID, nil
+}
+
+// transferChunk uploads bytes [start, end) and retries transient failures
+func (c *Client) transferChunk(ctx context.Context, path, accessToken, sessionID string, src io.ReaderAt, start, end int64) (*uploadSession, error) {
+	params := url.Values{}
+	params.Set("upload_phase", "transfer")
+	params.Set("upload_session_id", sessionID)
+	params.Set("start_offset", strconv.FormatInt(start, 10))
+
+	var lastErr error
+	for attempt := 0; attempt < uploadChunkRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(uploadChunkRetryDelay * time.Duration(attempt)):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			fmt.Printf("🔄 Retrying video chunk at offset %d (attempt %d/%d)\n", start, attempt+1, uploadChunkRetries)
+		}
+
+		chunk := io.NewSectionReader(src, start, end-start)
+		body, err := c.postChunk(ctx, path, accessToken, params, "video_file_chunk", "chunk", chunk)
+		if err == nil {
+			var next uploadSession
+			if err := json.Unmarshal(body, &next); err != nil {
+				return nil, fmt.Errorf("failed to parse transfer response: %s", string(body))
+			}
+			return &next, nil
+		}
+
+		lastErr = err
+		if graphErr, ok := AsGraphError(err); ok && !graphErr.IsTransient() {
+			return nil, err
+		}
+	}
+	return nil, lastErr
+}
+
+// postChunk sends one upload chunk over the media client with its own
+// deadline, so a slow chunk fails and is retried instead of the whole upload
+func (c *Client) postChunk(ctx context.Context, path, accessToken string, params url.Values, fileField, filename string, src io.Reader) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, uploadChunkTimeout)
+	defer cancel()
+	return c.sendMultipart(ctx, c.mediaClient, path, accessToken, params, fileField, filename, src)
+}
+
+// spoolMedia returns random-access data for a video. Local files and
+// in-memory readers are used directly; anything else (URL downloads, plain
+// readers) is streamed to a temporary file first.
+func (c *Client) spoolMedia(ctx context.Context, item MediaItem) (io.ReaderAt, int64, func(), error) {
+	noop := func() {}
+
+	if r, ok := item.Reader.(sizedReaderAt); ok {
+		return r, r.Size(), noop, nil
+	}
+
+	if item.Reader == nil && item.Path != "" {
+		f, err := os.Open(item.Path)
+		if err != nil {
+			return nil, 0, noop, err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, 0, noop, err
+		}
+		return f, info.Size(), func() { f.Close() }, nil
+	}
+
+	src, err := c.openMedia(ctx, item)
+	if err != nil {
+		return nil, 0, noop, err
+	}
+	defer src.Close()
+
+	tmp, err := os.CreateTemp("", "fbvideo-*")
+	if err != nil {
+		return nil, 0, noop, err
+	}
+	cleanup := func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+	size, err := io.Copy(tmp, src)
+	if err != nil {
+		cleanup()
+		return nil, 0, noop, fmt.Errorf("failed to download video: %w", err)
+	}
+	return tmp, size, cleanup, nil
+}
@@ -0,0 +1,43 @@
+package facebook_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"fbscheduler/internal/facebook"
+)
+
+func TestPublishVideoInChunks(t *testing.T) {
+	srv, client := newTestServer(t)
+	srv.SetChunkSize(1024)
+	video := buildMP4(testVideo{width: 1920, height: 1080, duration: 20 * time.Second, padding: 4000})
+
+	result, err := client.Publish(context.Background(), facebook.PublishRequest{
+		PageID: testPageID, AccessToken: testPageToken, MediaType: facebook.MediaTypeVideo,
+		Message: "video", Media: []facebook.MediaItem{{Reader: bytes.NewReader(video)}},
+	})
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	var phases []string
+	transfers := 0
+	for _, call := range srv.CallsTo("videos") {
+		phases = append(phases, call.Param("upload_phase"))
+		if call.Param("upload_phase") == "transfer" {
+			transfers++
+		}
+	}
+	if phases[0] != "start" || phases[len(phases)-1] != "finish" {
+		t.Errorf("upload phases = %v, want start, transfers, finish", phases)
+	}
+	if want := (len(video) + 1023) / 1024; transfers != want {
+		t.Errorf("sent %d transfer chunks, want %d", transfers, want)
+	}
+	uploaded, ok := srv.Video(result.PostID)
+	if !ok || !bytes.Equal(uploaded, video) {
+		t.Errorf("uploaded video has %d bytes, want %d", len(uploaded), len(video))
+	}
+}
@@ -0,0 +1,161 @@
+package facebook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// HeaderHubSignature256 carries the sha256 HMAC of a webhook delivery
+const HeaderHubSignature256 = "X-Hub-Signature-256"
+
+// WebhookFields are the page fields the app subscribes to
+var WebhookFields = []string{"feed"}
+
+// Feed change items and verbs sent in page feed webhooks
+const (
+	FeedItemStatus   = "status"
+	FeedItemPost     = "post"
+	FeedItemPhoto    = "photo"
+	FeedItemVideo    = "video"
+	FeedItemShare    = "share"
+	FeedItemComment  = "comment"
+	FeedItemReaction = "reaction"
+
+	FeedVerbAdd    = "add"
+	FeedVerbEdited = "edited"
+	FeedVerbRemove = "remove"
+	FeedVerbHide   = "hide"
+)
+
+// Webhook signature errors
+var (
+	ErrMissingSignature = errors.New("missing webhook signature")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrNoAppSecret      = errors.New("app secret is not configured")
+)
+
+// WebhookEvent is the body of a webhook delivery. Object is "page" for
+// page fields and "permissions" when a user grants or revokes a permission.
+type WebhookEvent struct {
+	Object string         `json:"object"`
+	Entry  []WebhookEntry `json:"entry"`
+}
+
+// WebhookEntry groups the changes of one page (or user for permissions)
+type WebhookEntry struct {
+	ID      string          `json:"id"`
+	Time    int64           `json:"time"`
+	Changes []WebhookChange `json:"changes"`
+}
+
+// WebhookChange is one changed field; Value is decoded with FeedChange or
+// PermissionChange depending on the object
+type WebhookChange struct {
+	Field string          `json:"field"`
+	Value json.RawMessage `json:"value"`
+}
+
+// FeedChange is the value of a page "feed" change
+type FeedChange struct {
+	Item         string `json:"item"`
+	Verb         string `json:"verb"`
+	PostID       string `json:"post_id"`
+	CommentID    string `json:"comment_id"`
+	ParentID     string `json:"parent_id"`
+	ReactionType string `json:"reaction_type"`
+	Message      string `json:"message"`
+	CreatedTime  int64  `json:"created_time"`
+	From         struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"from"`
+}
+
+// IsPostRemoval reports whether the change removes or hides a whole post
+func (f FeedChange) IsPostRemoval() bool {
+	switch f.Item {
+	case FeedItemStatus, FeedItemPost, FeedItemPhoto, FeedItemVideo, FeedItemShare:
+		return f.Verb == FeedVerbRemove || f.Verb == FeedVerbHide
+	}
+	return false
+}
+
+// PermissionChange is the value of a "permissions" change; Field on the
+// change is the permission name (e.g. pages_manage_posts)
+type PermissionChange struct {
+	Verb      string   `json:"verb"` // granted, revoked
+	TargetIDs []string `json:"target_ids"`
+}
+
+// Feed decodes the change value as a page feed change
+func (c WebhookChange) Feed() (FeedChange, error) {
+	var feed FeedChange
+	err := json.Unmarshal(c.Value, &feed)
+	return feed, err
+}
+
+// Permission decodes the change value as a permission change
+func (c WebhookChange) Permission() (PermissionChange, error) {
+	var perm PermissionChange
+	err := json.Unmarshal(c.Value, &perm)
+	return perm, err
+}
+
+// ParseWebhookEvent decodes a webhook delivery body
+func ParseWebhookEvent(body []byte) (*WebhookEvent, error) {
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook event: %w", err)
+	}
+	return &event, nil
+}
+
+// WebhookSignature returns the X-Hub-Signature-256 value Facebook sends
+// for body: "sha256=" followed by the hex HMAC keyed with the app secret
+func WebhookSignature(body []byte, appSecret string) string {
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks the X-Hub-Signature-256 header of a
+// delivery against the client's app secret
+func (c *Client) VerifyWebhookSignature(body []byte, signature string) error {
+	if c.appSecret == "" {
+		return ErrNoAppSecret
+	}
+	if !strings.HasPrefix(signature, "sha256=") {
+		return ErrMissingSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(WebhookSignature(body, c.appSecret))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// SubscribePageWebhooks installs the app on the page and subscribes it to
+// fields, so page events are delivered to the app's webhook. Needs a page
+// token with pages_manage_metadata.
+func (c *Client) SubscribePageWebhooks(ctx context.Context, pageID, accessToken string, fields []string) error {
+	params := url.Values{}
+	params.Set("subscribed_fields", strings.Join(fields, ","))
+	body, err := c.postForm(ctx, "/"+pageID+"/subscribed_apps", accessToken, params)
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil || !result.Success {
+		return fmt.Errorf("failed to subscribe page %s to webhooks: %s", pageID, string(body))
+	}
+	return nil
+}
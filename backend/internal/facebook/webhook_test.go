@@ -0,0 +1,59 @@
+package facebook_test
+
+import (
+	"errors"
+	"testing"
+
+	"fbscheduler/internal/facebook"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"object":"page","entry":[{"id":"123","time":1700000000,"changes":[{"field":"feed","value":{"item":"post","verb":"remove","post_id":"123_1"}}]}]}`)
+
+	tests := []struct {
+		name      string
+		appSecret string
+		signature string
+		wantErr   error
+	}{
+		{"valid", "secret", facebook.WebhookSignature(body, "secret"), nil},
+		{"signed with another secret", "secret", facebook.WebhookSignature(body, "other"), facebook.ErrInvalidSignature},
+		{"tampered body", "secret", facebook.WebhookSignature(append([]byte(" "), body...), "secret"), facebook.ErrInvalidSignature},
+		{"missing header", "secret", "", facebook.ErrMissingSignature},
+		{"sha1 header", "secret", "sha1=0123456789abcdef", facebook.ErrMissingSignature},
+		{"no app secret", "", facebook.WebhookSignature(body, "secret"), facebook.ErrNoAppSecret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := facebook.NewClient(facebook.WithAppSecret(tt.appSecret))
+			err := client.VerifyWebhookSignature(body, tt.signature)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyWebhookSignature() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseWebhookEvent(t *testing.T) {
+	body := []byte(`{"object":"page","entry":[{"id":"123","time":1700000000,"changes":[{"field":"feed","value":{"item":"post","verb":"remove","post_id":"123_1"}}]}]}`)
+
+	event, err := facebook.ParseWebhookEvent(body)
+	if err != nil {
+		t.Fatalf("ParseWebhookEvent() error = %v", err)
+	}
+	if event.Object != "page" || len(event.Entry) != 1 || len(event.Entry[0].Changes) != 1 {
+		t.Fatalf("ParseWebhookEvent() = %+v", event)
+	}
+	change, err := event.Entry[0].Changes[0].Feed()
+	if err != nil {
+		t.Fatalf("Feed() error = %v", err)
+	}
+	if change.PostID != "123_1" || !change.IsPostRemoval() {
+		t.Errorf("Feed() = %+v, want removal of 123_1", change)
+	}
+
+	if _, err := facebook.ParseWebhookEvent([]byte("not json")); err == nil {
+		t.Error("ParseWebhookEvent() accepted an invalid body")
+	}
+}
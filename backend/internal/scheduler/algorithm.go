@@ -32,6 +32,7 @@ type ScheduleRequest struct {
 	PageIDs      []string
 	PreferredDate time.Time
 	UseTimeSlots bool // true = dùng khung giờ của page, false = dùng thời gian cụ thể
+	MediaType    string // "story" chọn nick theo hạn mức story
 }
 
 // ScheduleResult kết quả schedule cho 1 page
@@ -88,8 +89,12 @@ func (s *SmartScheduler) CalculateSchedule(req ScheduleRequest) (*SchedulePrevie
 		return preview, nil
 	}
 
+	// Bước 0: Page không có quyền đăng bài thì báo lỗi luôn, không xếp lịch
+	pageIDs, denied := s.filterPostablePages(req.PageIDs)
+	preview.Results = append(preview.Results, denied...)
+
 	// Bước 1: Thu thập thông tin tất cả pages và time slots
-	pageSlots, err := s.collectPageTimeSlots(req.PageIDs, req.PreferredDate)
+	pageSlots, err := s.collectPageTimeSlots(pageIDs, req.PreferredDate, req.MediaType)
 	if err != nil {
 		return nil, err
 	}
@@ -137,8 +142,31 @@ type pageSlotInfo struct {
 	EndTime    time.Time
 }
 
+// filterPostablePages tách các page không có quyền đăng bài (thiếu task CREATE_CONTENT
+// hoặc quyền pages_manage_posts), trả về kết quả lỗi cho từng page đó
+func (s *SmartScheduler) filterPostablePages(pageIDs []string) ([]string, []ScheduleResult) {
+	allowed := make([]string, 0, len(pageIDs))
+	var denied []ScheduleResult
+
+	for _, pageID := range pageIDs {
+		page, err := s.store.GetPageByID(pageID)
+		if err == nil && page != nil {
+			if err := CheckPostingPermission(page); err != nil {
+				denied = append(denied, ScheduleResult{
+					PageID:   pageID,
+					PageName: page.PageName,
+					Error:    err,
+				})
+				continue
+			}
+		}
+		allowed = append(allowed, pageID)
+	}
+	return allowed, denied
+}
+
 // collectPageTimeSlots thu thập thông tin time slots của các pages
-func (s *SmartScheduler) collectPageTimeSlots(pageIDs []string, date time.Time) ([]pageSlotInfo, error) {
+func (s *SmartScheduler) collectPageTimeSlots(pageIDs []string, date time.Time, mediaType string) ([]pageSlotInfo, error) {
 	var result []pageSlotInfo
 
 	for _, pageID := range pageIDs {
@@ -149,7 +177,7 @@ func (s *SmartScheduler) collectPageTimeSlots(pageIDs []string, date time.Time)
 		}
 
 		// Lấy account tốt nhất cho page
-		account, err := s.store.GetBestAccountForPage(pageID)
+		account, err := s.store.GetBestAccountForMediaType(pageID, mediaType)
 		accountID := ""
 		accountName := ""
 		if err == nil && account != nil {
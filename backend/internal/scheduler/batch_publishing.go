@@ -0,0 +1,250 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+)
+
+// isBatchable kiểm tra bài có đăng được qua Graph batch API không (text, link, ảnh).
+// Bài chỉ đăng Instagram, bài cần in caption lên ảnh và bài của page không có quyền đăng / token hỏng
+// (PublishPost báo lỗi hoặc chặn bài) không qua batch.
+func isBatchable(sp db.ScheduledPost) bool {
+	return sp.Post != nil && sp.Page != nil && sp.Post.TargetsFacebook() && !sp.Post.BurnCaptions &&
+		sp.Page.CanPost() && sp.Page.TokenIsValid && buildPublishRequest(sp, "").Batchable()
+}
+
+// splitBatchablePosts tách bài đăng gộp được qua batch API khỏi các bài còn lại
+// (video, reel, story) vẫn đăng tuần tự theo nick
+func splitBatchablePosts(posts []db.ScheduledPost) (batchable, rest []db.ScheduledPost) {
+	for _, sp := range posts {
+		if isBatchable(sp) {
+			batchable = append(batchable, sp)
+		} else {
+			rest = append(rest, sp)
+		}
+	}
+	return batchable, rest
+}
+
+// batchItem là 1 bài trong lô cùng nick và token dùng để đăng
+type batchItem struct {
+	sp          db.ScheduledPost
+	account     *db.FacebookAccount
+	accessToken string
+	attempt     *publishAttempt
+}
+
+// PublishBatch đăng nhiều bài tới giờ cùng lúc qua Graph batch API thay vì
+// mỗi bài một loạt request. Mỗi bài vẫn được ghi post_logs, cập nhật thống kê
+// nick, comment đầu tiên và retry như PublishPost. Bài của mọi nick được gộp vào
+// các lô tối đa facebook.MaxBatchSize thao tác (planBatches); mỗi lô vẫn giữ semaphore,
+// cooldown và tạm dừng theo usage của từng nick có bài trong lô.
+func (e *PostingEngine) PublishBatch(sps []db.ScheduledPost) {
+	var items []batchItem
+	reserved := make(map[string]int) // số bài đã xếp cho mỗi nick trong lô
+	for _, sp := range sps {
+		account, accessToken, err := e.getAccountForPost(sp)
+		if err != nil {
+			log.Printf("⚠️ Post %s skipped from batch: failed to get account: %v", sp.ID, err)
+			continue
+		}
+		accountID := ""
+		if account != nil {
+			accountID = account.ID
+			// Nick hết hạn mức trong lô này → để pending, lượt sau sẽ chọn nick khác
+			if account.PostsToday+reserved[accountID] >= account.MaxPostsPerDay {
+				continue
+			}
+			reserved[accountID]++
+		}
+
+		// Đánh dấu processing ngay để lượt quét sau không lấy lại bài này
+		item := batchItem{sp: sp, account: account, accessToken: accessToken}
+		if err := e.store.UpdateScheduledPostStatus(sp.ID, "processing"); err != nil {
+			log.Printf("❌ Error updating status: %v", err)
+			releaseReservation(reserved, accountID)
+			continue
+		}
+		if item.attempt, err = e.startPublishAttempt(sp); err != nil {
+			log.Printf("❌ Error saving publish attempt: %v", err)
+			e.store.UpdateScheduledPostStatus(sp.ID, "pending")
+			releaseReservation(reserved, accountID)
+			continue
+		}
+		// Lần đăng trước đã tạo bài → ghi nhận luôn, không đưa vào lô
+		if fbPostID, found := e.resumePublishAttempt(sp, accessToken, item.attempt); found {
+			logEntry := &db.PostLog{ScheduledPostID: sp.ID, PostID: sp.PostID, PageID: sp.PageID}
+			e.handlePostSuccess(sp, account, accessToken, logEntry, &facebook.PublishResult{PostID: fbPostID})
+			continue
+		}
+		items = append(items, item)
+	}
+
+	for _, batch := range planBatches(items) {
+		e.publishMergedBatch(batch)
+	}
+}
+
+// releaseReservation trả lại hạn mức đã xếp cho bài bị bỏ khỏi lô
+func releaseReservation(reserved map[string]int, accountID string) {
+	if accountID != "" && reserved[accountID] > 0 {
+		reserved[accountID]--
+	}
+}
+
+// accountID là nick đăng bài ("" = không có nick, dùng token page)
+func (item batchItem) accountID() string {
+	if item.account == nil {
+		return ""
+	}
+	return item.account.ID
+}
+
+// planBatches gộp bài của mọi nick thành các lô tối đa facebook.MaxBatchSize thao tác,
+// theo thứ tự bài. Mỗi nick có tối đa MaxConcurrentPerAccount bài trong 1 lô (số chỗ
+// semaphore của nick), bài vượt quá chuyển sang lô sau.
+func planBatches(items []batchItem) [][]batchItem {
+	var batches [][]batchItem
+	for len(items) > 0 {
+		var batch, rest []batchItem
+		ops := 0
+		perAccount := make(map[string]int)
+		for _, item := range items {
+			n := batchOpCount(item)
+			accountID := item.accountID()
+			if ops+n > facebook.MaxBatchSize || (accountID != "" && perAccount[accountID] >= MaxConcurrentPerAccount) {
+				rest = append(rest, item)
+				continue
+			}
+			batch = append(batch, item)
+			ops += n
+			if accountID != "" {
+				perAccount[accountID]++
+			}
+		}
+		batches = append(batches, batch)
+		items = rest
+	}
+	return batches
+}
+
+// batchOpCount là số thao tác của bài trong lô: 1 bài đăng, cộng 1 lần upload cho mỗi ảnh khi đăng nhiều ảnh
+func batchOpCount(item batchItem) int {
+	if n := len(buildPublishRequest(item.sp, "").Media); n > 1 {
+		return n + 1
+	}
+	return 1
+}
+
+// publishMergedBatch đăng 1 lô bài của nhiều nick: giữ semaphore của từng nick (1 chỗ cho mỗi bài)
+// và chờ cooldown như PublishPost. Nick hoặc page đang tạm dừng theo usage → bài trả về pending.
+func (e *PostingEngine) publishMergedBatch(batch []batchItem) {
+	ready := make([]batchItem, 0, len(batch))
+	perAccount := make(map[string]int)
+	for _, item := range batch {
+		accountID := item.accountID()
+		if accountID != "" && e.isPaused("account", accountID) {
+			log.Printf("⏸️ Account %s is paused, post %s stays pending", accountID, item.sp.ID)
+			e.releaseBatchItems([]batchItem{item})
+			continue
+		}
+		if e.isPaused("page", item.sp.PageID) || e.regainAccessIn(item.sp) > 0 {
+			log.Printf("⏸️ Page %s is paused, post %s stays pending", item.sp.PageID, item.sp.ID)
+			e.releaseBatchItems([]batchItem{item})
+			continue
+		}
+		ready = append(ready, item)
+		if accountID != "" {
+			perAccount[accountID]++
+		}
+	}
+	if len(ready) == 0 {
+		return
+	}
+
+	// Giữ chỗ theo thứ tự ID nick để 2 lô chạy song song không chờ lẫn nhau
+	accountIDs := make([]string, 0, len(perAccount))
+	for accountID := range perAccount {
+		accountIDs = append(accountIDs, accountID)
+	}
+	sort.Strings(accountIDs)
+	for _, accountID := range accountIDs {
+		sem := e.getAccountSemaphore(accountID)
+		for i := 0; i < perAccount[accountID]; i++ {
+			sem <- struct{}{}
+		}
+	}
+	defer func() {
+		for _, accountID := range accountIDs {
+			sem := e.getAccountSemaphore(accountID)
+			for i := 0; i < perAccount[accountID]; i++ {
+				<-sem
+			}
+		}
+	}()
+	for _, accountID := range accountIDs {
+		e.waitForCooldown(accountID)
+	}
+
+	e.publishBatchChunk(ready)
+}
+
+// publishBatchChunk đăng 1 lô bài qua Graph batch API và xử lý kết quả từng bài
+func (e *PostingEngine) publishBatchChunk(items []batchItem) {
+	reqs := make([]facebook.PublishRequest, 0, len(items))
+	for _, item := range items {
+		reqs = append(reqs, buildPublishRequest(item.sp, item.accessToken))
+	}
+
+	log.Printf("📦 Publishing %d posts through the Graph batch API", len(reqs))
+	results := e.fbClient.PublishBatch(context.Background(), reqs)
+
+	for i, result := range results {
+		item := items[i]
+		logEntry := &db.PostLog{
+			ScheduledPostID: item.sp.ID,
+			PostID:          item.sp.PostID,
+			PageID:          item.sp.PageID,
+		}
+		e.applyUsage(item.sp, item.account, item.accessToken)
+		if result.Err != nil {
+			if fbPostID, found := e.findPublishedPost(item.sp, item.accessToken, item.attempt, result.Err); found {
+				e.handlePostSuccess(item.sp, item.account, item.accessToken, logEntry, &facebook.PublishResult{PostID: fbPostID})
+				continue
+			}
+			// Cả lô lỗi → retry bài, không tính lỗi cho nick
+			account := item.account
+			if errors.Is(result.Err, facebook.ErrBatchFailed) {
+				account = nil
+			}
+			e.handlePostError(item.sp, account, logEntry, result.Err)
+			continue
+		}
+		e.claimPublishedPost(item.sp, item.attempt, result.Result)
+		e.handlePostSuccess(item.sp, item.account, item.accessToken, logEntry, result.Result)
+	}
+}
+
+// releaseBatchItems trả bài chưa đăng về pending (lượt quét sau đăng lại)
+func (e *PostingEngine) releaseBatchItems(items []batchItem) {
+	for _, item := range items {
+		if err := e.store.UpdateScheduledPostStatus(item.sp.ID, "pending"); err != nil {
+			log.Printf("❌ Error updating status: %v", err)
+		}
+	}
+}
+
+// isPaused kiểm tra page / nick đang bị tạm dừng theo usage (lỗi DB coi như không tạm dừng)
+func (e *PostingEngine) isPaused(kind, id string) bool {
+	paused, err := e.store.IsPaused(kind, id)
+	if err != nil {
+		log.Printf("⚠️ Error checking %s pause: %v", kind, err)
+		return false
+	}
+	return paused
+}
@@ -0,0 +1,128 @@
+package scheduler
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+)
+
+// testScheduledPost tạo scheduled post của page có quyền đăng, token hợp lệ
+func testScheduledPost(id string, post *db.Post) db.ScheduledPost {
+	return db.ScheduledPost{
+		ID:     id,
+		PostID: "post-" + id,
+		PageID: "page-1",
+		Post:   post,
+		Page: &db.Page{
+			ID: "page-1", PageID: "123", PageName: "Test Page", AccessToken: "page-token",
+			TokenIsValid: true,
+		},
+	}
+}
+
+func TestIsBatchable(t *testing.T) {
+	tests := []struct {
+		name string
+		sp   db.ScheduledPost
+		want bool
+	}{
+		{"text", testScheduledPost("1", &db.Post{Content: "hello"}), true},
+		{"link", testScheduledPost("2", &db.Post{Content: "read", LinkURL: "https://example.com"}), true},
+		{"photos", testScheduledPost("3", &db.Post{MediaURLs: []string{"a.jpg", "b.jpg"}}), true},
+		{"video", testScheduledPost("4", &db.Post{MediaType: facebook.MediaTypeVideo, MediaURLs: []string{"a.mp4"}}), false},
+		{"reel", testScheduledPost("5", &db.Post{MediaType: facebook.MediaTypeReel, MediaURLs: []string{"a.mp4"}}), false},
+		{"story", testScheduledPost("6", &db.Post{MediaType: facebook.MediaTypeStory, MediaURLs: []string{"a.jpg"}}), false},
+		{"captioned album", testScheduledPost("7", &db.Post{Content: "Trip", MediaURLs: []string{"a.jpg", "b.jpg"},
+			MediaCaptions: []string{"first", "second"}}), false},
+		{"burned captions", testScheduledPost("8", &db.Post{MediaURLs: []string{"a.jpg"}, MediaCaptions: []string{"x"},
+			BurnCaptions: true}), false},
+		{"instagram only", testScheduledPost("9", &db.Post{Content: "hi", MediaURLs: []string{"a.jpg"},
+			Platforms: []string{db.PlatformInstagram}}), false},
+		{"invalid page token", func() db.ScheduledPost {
+			sp := testScheduledPost("10", &db.Post{Content: "hi"})
+			sp.Page.TokenIsValid = false
+			return sp
+		}(), false},
+		{"no posting permission", func() db.ScheduledPost {
+			sp := testScheduledPost("11", &db.Post{Content: "hi"})
+			sp.Page.Tasks = []string{"ANALYZE"}
+			return sp
+		}(), false},
+		{"missing post", db.ScheduledPost{ID: "12", Page: &db.Page{TokenIsValid: true}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBatchable(tt.sp); got != tt.want {
+				t.Errorf("isBatchable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitBatchablePosts(t *testing.T) {
+	posts := []db.ScheduledPost{
+		testScheduledPost("1", &db.Post{Content: "text"}),
+		testScheduledPost("2", &db.Post{MediaType: facebook.MediaTypeVideo, MediaURLs: []string{"a.mp4"}}),
+		testScheduledPost("3", &db.Post{MediaURLs: []string{"a.jpg"}}),
+	}
+	batchable, rest := splitBatchablePosts(posts)
+	if len(batchable) != 2 || batchable[0].ID != "1" || batchable[1].ID != "3" {
+		t.Errorf("batchable = %v, want posts 1 and 3", scheduledPostIDs(batchable))
+	}
+	if len(rest) != 1 || rest[0].ID != "2" {
+		t.Errorf("rest = %v, want post 2", scheduledPostIDs(rest))
+	}
+}
+
+func scheduledPostIDs(sps []db.ScheduledPost) []string {
+	ids := make([]string, 0, len(sps))
+	for _, sp := range sps {
+		ids = append(ids, sp.ID)
+	}
+	return ids
+}
+
+func TestPlanBatches(t *testing.T) {
+	accountA := &db.FacebookAccount{ID: "account-a"}
+	accountB := &db.FacebookAccount{ID: "account-b"}
+	photos := make([]string, facebook.MaxBatchSize-2)
+	for i := range photos {
+		photos[i] = "photo.jpg"
+	}
+
+	var items []batchItem
+	// 4 bài của nick A, 2 bài của nick B, 1 bài dùng token page
+	for i := 1; i <= 4; i++ {
+		items = append(items, batchItem{sp: testScheduledPost(fmt.Sprintf("a%d", i), &db.Post{Content: "a"}), account: accountA})
+	}
+	items = append(items,
+		batchItem{sp: testScheduledPost("b1", &db.Post{Content: "b"}), account: accountB},
+		batchItem{sp: testScheduledPost("b2", &db.Post{MediaURLs: photos}), account: accountB},
+		batchItem{sp: testScheduledPost("p1", &db.Post{Content: "page"})},
+	)
+
+	var got [][]string
+	for _, batch := range planBatches(items) {
+		var ids []string
+		for _, item := range batch {
+			ids = append(ids, item.sp.ID)
+		}
+		got = append(got, ids)
+	}
+	// Lô đầu gộp nhiều nick, tối đa MaxConcurrentPerAccount bài mỗi nick và MaxBatchSize thao tác
+	want := [][]string{{"a1", "a2", "a3", "b1", "p1"}, {"a4", "b2"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("planBatches() = %v, want %v", got, want)
+	}
+}
+
+func TestClassifyBatchFailure(t *testing.T) {
+	// Token của lô bị từ chối: không phải lỗi token của nick đăng từng bài
+	err := fmt.Errorf("%w: %w", facebook.ErrBatchFailed, &facebook.GraphError{Code: 190, Message: "Error validating access token"})
+	if got := classifyPostError(err); got != actionRetry {
+		t.Errorf("classifyPostError() = %v, want actionRetry", got)
+	}
+}
@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+	"fbscheduler/internal/imageprocessor"
+)
+
+// ============================================
+// CAPTIONS
+// Caption riêng cho từng ảnh: đăng dạng album, tùy chọn in caption lên ảnh
+// ============================================
+
+// albumNameMaxLength độ dài tối đa của tên album lấy từ nội dung bài
+const albumNameMaxLength = 64
+
+// PostAlbumName trả về tên album nếu bài được đăng dạng album (nhiều ảnh có caption riêng):
+// album_name, hoặc dòng đầu nội dung, hoặc "Album". Rỗng = không đăng dạng album.
+func PostAlbumName(post *db.Post) string {
+	if len(post.MediaURLs) < 2 || !post.HasMediaCaptions() || !isPhotoPost(post) {
+		return ""
+	}
+	if post.AlbumName != "" {
+		return post.AlbumName
+	}
+
+	name := strings.TrimSpace(strings.SplitN(post.Content, "\n", 2)[0])
+	if runes := []rune(name); len(runes) > albumNameMaxLength {
+		name = strings.TrimSpace(string(runes[:albumNameMaxLength-3])) + "..."
+	}
+	if name == "" {
+		name = "Album"
+	}
+	return name
+}
+
+// RenderCaptionOverlays in caption lên từng ảnh có caption khi bài bật burn_captions.
+// rendered[i] là ảnh thứ i đã in caption (nil = dùng ảnh gốc). Tải ảnh 1 lần, dùng lại cho nhiều page.
+func RenderCaptionOverlays(post *db.Post) ([][]byte, error) {
+	if !post.BurnCaptions || !isPhotoPost(post) {
+		return nil, nil
+	}
+
+	rendered := make([][]byte, len(post.MediaURLs))
+	for i, mediaURL := range post.MediaURLs {
+		caption := post.MediaCaption(i)
+		if caption == "" {
+			continue
+		}
+		data, err := imageprocessor.AddCaptionToImage(mediaURL, caption)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render caption on image %d: %w", i+1, err)
+		}
+		rendered[i] = data
+	}
+	return rendered, nil
+}
+
+// CaptionedMedia gắn caption vào từng media; ảnh đã in caption được upload từ bytes
+// (mỗi lần gọi tạo reader mới nên dùng được cho nhiều request)
+func CaptionedMedia(post *db.Post, items []facebook.MediaItem, rendered [][]byte) []facebook.MediaItem {
+	for i := range items {
+		items[i].Caption = post.MediaCaption(i)
+		if i < len(rendered) && rendered[i] != nil {
+			items[i].Reader = bytes.NewReader(rendered[i])
+			items[i].Filename = "image.jpg"
+			if http.DetectContentType(rendered[i]) == "image/png" {
+				items[i].Filename = "image.png"
+			}
+		}
+	}
+	return items
+}
+
+// isPhotoPost kiểm tra bài ảnh (caption từng ảnh / in caption chỉ áp dụng cho ảnh)
+func isPhotoPost(post *db.Post) bool {
+	switch post.MediaType {
+	case "", "text", facebook.MediaTypePhoto:
+		return len(post.MediaURLs) > 0
+	}
+	return false
+}
+
+// NeedsCaptionUpload cho biết bài có caption từng ảnh hoặc cần in caption lên ảnh:
+// phải do scheduler tự đăng, không hẹn giờ trên Facebook được
+func NeedsCaptionUpload(post *db.Post) bool {
+	return post.BurnCaptions || post.HasMediaCaptions()
+}
@@ -0,0 +1,184 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+
+	"github.com/google/uuid"
+)
+
+// ============================================
+// IDEMPOTENCY
+// Mỗi lần đăng có khóa idempotency lưu trước khi gọi Graph API.
+// Lỗi mơ hồ (timeout, 5xx) có thể xảy ra sau khi Facebook đã tạo bài:
+// đối chiếu feed của page trước khi retry để không đăng trùng.
+// Bài Facebook được claim theo khóa (facebook_post_claims) nên mỗi bài chỉ thuộc 1 lần đăng.
+// Khóa giữ nguyên qua các lần retry: lần đăng lại đối chiếu feed từ lần đăng đầu tiên trước khi gọi Graph API.
+// ============================================
+
+const (
+	// Lệch giờ tối đa giữa DB và Facebook khi so created_time (giây)
+	PublishLookupSkewSeconds = 60
+
+	// Số bài gần nhất của page đọc về để đối chiếu
+	PublishLookupLimit = 25
+)
+
+// publishAttempt là các lần gọi Graph API để đăng 1 scheduled post (cùng khóa qua các lần retry)
+type publishAttempt struct {
+	Key       string
+	StartedAt time.Time
+	// Resumed: đã có lần đăng trước, bài có thể đã lên Facebook
+	Resumed bool
+}
+
+// startPublishAttempt lưu khóa idempotency vào scheduled_posts trước khi gọi Graph API.
+// Lần đăng trước còn khóa thì dùng lại khóa và thời điểm bắt đầu của nó.
+func (e *PostingEngine) startPublishAttempt(sp db.ScheduledPost) (*publishAttempt, error) {
+	saved, err := e.store.StartPublishAttempt(sp.ID, uuid.New().String())
+	if err != nil {
+		return nil, err
+	}
+	return &publishAttempt{Key: saved.Key, StartedAt: saved.StartedAt, Resumed: saved.Resumed || sp.RetryCount > 0}, nil
+}
+
+// resumePublishAttempt đối chiếu feed trước khi đăng lại: lần đăng trước báo lỗi mơ hồ hoặc
+// server dừng giữa chừng có thể đã tạo bài, kể cả khi bài hiện trên feed sau lần đối chiếu trước đó.
+// Trả về ID bài nếu tìm thấy.
+func (e *PostingEngine) resumePublishAttempt(sp db.ScheduledPost, accessToken string, attempt *publishAttempt) (string, bool) {
+	if attempt == nil || !attempt.Resumed {
+		return "", false
+	}
+	fbPostID, found := e.lookUpPublishedPost(sp, accessToken, attempt)
+	if found {
+		log.Printf("🔎 Post %s from an earlier attempt is already on Facebook (attempt %s): %s", sp.ID, attempt.Key, fbPostID)
+	}
+	return fbPostID, found
+}
+
+// claimPublishedPost ghi nhận bài vừa đăng thành công thuộc khóa của lần đăng,
+// để lần đăng khác cùng nội dung đang đối chiếu feed không nhận nhầm bài này
+func (e *PostingEngine) claimPublishedPost(sp db.ScheduledPost, attempt *publishAttempt, result *facebook.PublishResult) {
+	if attempt == nil || result == nil || result.PostID == "" {
+		return
+	}
+	if _, err := e.store.ClaimFacebookPost(result.PostID, sp.PageID, attempt.Key); err != nil {
+		log.Printf("⚠️ Could not claim post %s for %s (attempt %s): %v", result.PostID, sp.ID, attempt.Key, err)
+	}
+}
+
+// findPublishedPost tìm trên page bài Facebook đã tạo dù lần đăng báo lỗi mơ hồ:
+// bài cùng nội dung, tạo sau khi bắt đầu lần đăng, chưa được ghi nhận và claim được
+// cho khóa của lần đăng này (không thuộc lần đăng khác).
+// Trả về ID bài nếu tìm thấy.
+func (e *PostingEngine) findPublishedPost(sp db.ScheduledPost, accessToken string, attempt *publishAttempt, postErr error) (string, bool) {
+	if attempt == nil || !facebook.IsAmbiguous(postErr) {
+		return "", false
+	}
+	fbPostID, found := e.lookUpPublishedPost(sp, accessToken, attempt)
+	if found {
+		log.Printf("🔎 Post %s was created on Facebook despite %v (attempt %s): %s", sp.ID, postErr, attempt.Key, fbPostID)
+	}
+	return fbPostID, found
+}
+
+// lookUpPublishedPost đọc feed của page từ lúc bắt đầu lần đăng đầu tiên, tìm bài cùng nội dung
+// chưa ghi post_logs và claim được cho khóa của scheduled post
+func (e *PostingEngine) lookUpPublishedPost(sp db.ScheduledPost, accessToken string, attempt *publishAttempt) (string, bool) {
+	if !canLookUpPublishedPost(sp) {
+		return "", false
+	}
+	message := strings.TrimSpace(expectedMessage(sp))
+	if message == "" {
+		return "", false
+	}
+
+	since := attempt.StartedAt.Add(-PublishLookupSkewSeconds * time.Second)
+	posts, err := e.fbClient.GetRecentPosts(context.Background(), sp.Page.PageID, accessToken, since, PublishLookupLimit)
+	if err != nil {
+		log.Printf("⚠️ Could not check page %s for post %s (attempt %s): %v", sp.Page.PageID, sp.ID, attempt.Key, err)
+		return "", false
+	}
+
+	// Feed trả về mới nhất trước → duyệt ngược để lấy bài tạo sớm nhất
+	for i := len(posts) - 1; i >= 0; i-- {
+		if strings.TrimSpace(posts[i].Message) != message {
+			continue
+		}
+		logged, err := e.store.IsFacebookPostLogged(posts[i].ID)
+		if err != nil || logged {
+			continue
+		}
+		// Claim bài cho khóa của lần đăng này: bài cùng nội dung đã thuộc lần đăng khác thì bỏ qua
+		claimed, err := e.store.ClaimFacebookPost(posts[i].ID, sp.PageID, attempt.Key)
+		if err != nil {
+			log.Printf("⚠️ Could not claim post %s for %s (attempt %s): %v", posts[i].ID, sp.ID, attempt.Key, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		return posts[i].ID, true
+	}
+	return "", false
+}
+
+// canLookUpPublishedPost kiểm tra bài có đối chiếu được qua feed của page không:
+// story, reel, album, bài ẩn và bài chỉ đăng Instagram không hiện đúng 1 bài trên feed
+func canLookUpPublishedPost(sp db.ScheduledPost) bool {
+	if sp.Post == nil || sp.Page == nil || !sp.Post.TargetsFacebook() || sp.Post.Unpublished {
+		return false
+	}
+	switch sp.Post.MediaType {
+	case facebook.MediaTypeStory, facebook.MediaTypeReel:
+		return false
+	}
+	return PostAlbumName(sp.Post) == ""
+}
+
+// expectedMessage là nội dung bài sẽ hiện trên feed (ảnh đơn không có nội dung thì lấy caption ảnh)
+func expectedMessage(sp db.ScheduledPost) string {
+	if sp.Post.Content == "" && len(sp.Post.MediaURLs) == 1 {
+		return sp.Post.MediaCaption(0)
+	}
+	return sp.Post.Content
+}
+
+// RecoverProcessingPosts đối soát bài còn "processing" lúc server khởi động (dừng giữa lúc đăng):
+// bài đã lên Facebook theo khóa của lần đăng trước → completed, còn lại trả về pending để đăng lại.
+func (e *PostingEngine) RecoverProcessingPosts() {
+	posts, err := e.store.GetProcessingScheduledPosts()
+	if err != nil {
+		log.Printf("❌ Error fetching posts left in processing: %v", err)
+		return
+	}
+
+	for _, sp := range posts {
+		saved, err := e.store.GetPublishAttempt(sp.ID)
+		if err != nil {
+			log.Printf("⚠️ Could not read publish attempt of %s: %v", sp.ID, err)
+			continue
+		}
+		if saved != nil {
+			attempt := &publishAttempt{Key: saved.Key, StartedAt: saved.StartedAt, Resumed: true}
+			if fbPostID, found := e.resumePublishAttempt(sp, sp.Page.AccessToken, attempt); found {
+				var account *db.FacebookAccount
+				if sp.AccountID != nil {
+					account, _ = e.store.GetAccountByID(*sp.AccountID)
+				}
+				logEntry := &db.PostLog{ScheduledPostID: sp.ID, PostID: sp.PostID, PageID: sp.PageID, Platform: db.PlatformFacebook}
+				e.handlePostSuccess(sp, account, sp.Page.AccessToken, logEntry, &facebook.PublishResult{PostID: fbPostID})
+				continue
+			}
+		}
+		log.Printf("♻️ Post %s was left in processing, back to pending", sp.ID)
+		if err := e.store.UpdateScheduledPostStatus(sp.ID, "pending"); err != nil {
+			log.Printf("❌ Error updating status: %v", err)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+	"fbscheduler/internal/facebook/fake"
+)
+
+// ambiguousErr là lỗi Graph API tạm thời: Facebook có thể đã tạo bài
+var ambiguousErr = &facebook.GraphError{Code: 2, Message: "service temporarily unavailable"}
+
+func TestCanLookUpPublishedPost(t *testing.T) {
+	tests := []struct {
+		name string
+		post *db.Post
+		want bool
+	}{
+		{"text", &db.Post{Content: "hello"}, true},
+		{"photos", &db.Post{Content: "hello", MediaURLs: []string{"a.jpg", "b.jpg"}}, true},
+		{"story", &db.Post{MediaType: facebook.MediaTypeStory, MediaURLs: []string{"a.jpg"}}, false},
+		{"reel", &db.Post{MediaType: facebook.MediaTypeReel, MediaURLs: []string{"a.mp4"}}, false},
+		{"album", &db.Post{Content: "Trip", MediaURLs: []string{"a.jpg", "b.jpg"}, MediaCaptions: []string{"a", "b"}}, false},
+		{"unpublished", &db.Post{Content: "dark post", Unpublished: true}, false},
+		{"instagram only", &db.Post{Content: "hi", MediaURLs: []string{"a.jpg"}, Platforms: []string{db.PlatformInstagram}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canLookUpPublishedPost(testScheduledPost("1", tt.post)); got != tt.want {
+				t.Errorf("canLookUpPublishedPost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpectedMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		post *db.Post
+		want string
+	}{
+		{"content", &db.Post{Content: "hello", MediaURLs: []string{"a.jpg"}, MediaCaptions: []string{"caption"}}, "hello"},
+		{"single photo caption", &db.Post{MediaURLs: []string{"a.jpg"}, MediaCaptions: []string{"caption"}}, "caption"},
+		{"multi photo without content", &db.Post{MediaURLs: []string{"a.jpg", "b.jpg"}, MediaCaptions: []string{"a"}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expectedMessage(testScheduledPost("1", tt.post)); got != tt.want {
+				t.Errorf("expectedMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindPublishedPostSkipsLookup(t *testing.T) {
+	srv := fake.NewServer()
+	defer srv.Close()
+	// Không cần DB: các trường hợp dưới đây không tới bước đối chiếu post_logs
+	e := NewPostingEngineWithClient(nil, srv.Client(facebook.WithAppSecret("")))
+	attempt := &publishAttempt{Key: "attempt-a", StartedAt: time.Now()}
+
+	tests := []struct {
+		name       string
+		post       *db.Post
+		attempt    *publishAttempt
+		err        error
+		wantLookup bool
+	}{
+		{"rejected post", &db.Post{Content: "hello"}, attempt, &facebook.GraphError{Code: 368, Message: "blocked"}, false},
+		{"no attempt", &db.Post{Content: "hello"}, nil, ambiguousErr, false},
+		{"story", &db.Post{MediaType: facebook.MediaTypeStory, MediaURLs: []string{"a.jpg"}}, attempt, ambiguousErr, false},
+		{"nothing to match", &db.Post{MediaURLs: []string{"a.jpg", "b.jpg"}}, attempt, ambiguousErr, false},
+		{"timeout", &db.Post{Content: "hello"}, attempt, context.DeadlineExceeded, true},
+		{"no matching post on the page", &db.Post{Content: "hello"}, attempt, ambiguousErr, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv.Reset()
+			id, found := e.findPublishedPost(testScheduledPost("1", tt.post), testPageToken, tt.attempt, tt.err)
+			if found {
+				t.Fatalf("findPublishedPost() found %s, want nothing", id)
+			}
+			if lookedUp := len(srv.CallsTo("posts")) > 0; lookedUp != tt.wantLookup {
+				t.Errorf("read the page feed = %v, want %v", lookedUp, tt.wantLookup)
+			}
+		})
+	}
+}
+
+func TestPublishPostRecoversCommittedPost(t *testing.T) {
+	e, store, srv := newTestEngine(t)
+	sp := createScheduledPost(t, store, &db.Post{Content: "Recovered after timeout"}, time.Now())
+
+	// Facebook tạo bài nhưng trả lỗi tạm thời
+	srv.FailNext("feed", fake.Error{Status: 500, Code: 2, Message: "timeout", IsTransient: true, Committed: true})
+	if err := e.PublishPost(sp); err != nil {
+		t.Fatalf("PublishPost() error = %v", err)
+	}
+
+	posts := srv.PagePosts(testPageID)
+	if len(posts) != 1 {
+		t.Fatalf("page has %d posts, want 1 (no duplicate on retry)", len(posts))
+	}
+	if got := getScheduledPost(t, store, sp.ID).Status; got != "completed" {
+		t.Errorf("status = %q, want completed", got)
+	}
+	status, fbPostID := lastPostLog(t, store, sp.ID)
+	if status != "success" || fbPostID != posts[0].ID {
+		t.Errorf("post log = %s %q, want success %q", status, fbPostID, posts[0].ID)
+	}
+}
+
+func TestPublishPostDoesNotRecoverClaimedPost(t *testing.T) {
+	e, store, srv := newTestEngine(t)
+	sp := createScheduledPost(t, store, &db.Post{Content: "Same message"}, time.Now())
+
+	// Bài cùng nội dung vừa được lần đăng khác tạo và claim (chưa kịp ghi post_logs)
+	other, err := e.fbClient.Publish(context.Background(), facebook.PublishRequest{
+		PageID: testPageID, AccessToken: testPageToken, Message: "Same message",
+	})
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if claimed, err := store.ClaimFacebookPost(other.PostID, sp.PageID, "other-attempt"); err != nil || !claimed {
+		t.Fatalf("ClaimFacebookPost() = %v, %v", claimed, err)
+	}
+
+	// Lần đăng này lỗi mơ hồ và Facebook không tạo bài → không được nhận bài của lần đăng kia
+	srv.FailNext("feed", fake.Error{Status: 500, Code: 2, Message: "timeout", IsTransient: true})
+	e.PublishPost(sp)
+
+	got := getScheduledPost(t, store, sp.ID)
+	if got.Status != "pending" || got.RetryCount != 1 {
+		t.Errorf("status = %q, retry_count = %d, want pending with 1 retry", got.Status, got.RetryCount)
+	}
+	if status, fbPostID := lastPostLog(t, store, sp.ID); status == "success" {
+		t.Errorf("post claimed %s of another attempt", fbPostID)
+	}
+}
+
+func TestPublishPostRecoversPostOnRetry(t *testing.T) {
+	e, store, srv := newTestEngine(t)
+	sp := createScheduledPost(t, store, &db.Post{Content: "Shows up late"}, time.Now())
+
+	// Facebook tạo bài nhưng báo lỗi, và lần đọc feed ngay sau đó cũng lỗi → bài chờ retry
+	srv.FailNext("feed", fake.Error{Status: 500, Code: 2, Message: "timeout", IsTransient: true, Committed: true})
+	srv.FailNext("posts", fake.Error{Status: 500, Code: 2, Message: "timeout", IsTransient: true})
+	e.PublishPost(sp)
+	if got := getScheduledPost(t, store, sp.ID); got.Status != "pending" || got.RetryCount != 1 {
+		t.Fatalf("status = %q, retry_count = %d, want pending with 1 retry", got.Status, got.RetryCount)
+	}
+
+	// Lần retry đối chiếu feed từ lần đăng đầu tiên trước khi đăng lại
+	srv.Reset()
+	if err := e.PublishPost(getScheduledPost(t, store, sp.ID)); err != nil {
+		t.Fatalf("PublishPost() error = %v", err)
+	}
+	if n := len(srv.CallsTo("feed")); n != 0 {
+		t.Errorf("retry published the post again (%d feed calls)", n)
+	}
+	if got := getScheduledPost(t, store, sp.ID).Status; got != "completed" {
+		t.Errorf("status = %q, want completed", got)
+	}
+}
+
+func TestRecoverProcessingPosts(t *testing.T) {
+	e, store, srv := newTestEngine(t)
+	live := createScheduledPost(t, store, &db.Post{Content: "Published before the crash"}, time.Now())
+	lost := createScheduledPost(t, store, &db.Post{Content: "Never reached Facebook"}, time.Now())
+
+	// Server dừng sau khi lưu khóa: bài đầu đã lên Facebook, bài sau chưa
+	for _, sp := range []db.ScheduledPost{live, lost} {
+		store.UpdateScheduledPostStatus(sp.ID, "processing")
+		if _, err := e.startPublishAttempt(sp); err != nil {
+			t.Fatalf("startPublishAttempt() error = %v", err)
+		}
+	}
+	if _, err := e.fbClient.Publish(context.Background(), facebook.PublishRequest{
+		PageID: testPageID, AccessToken: testPageToken, Message: "Published before the crash",
+	}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	srv.Reset()
+	e.RecoverProcessingPosts()
+
+	tests := []struct {
+		sp         db.ScheduledPost
+		wantStatus string
+	}{
+		{live, "completed"},
+		{lost, "pending"},
+	}
+	for _, tt := range tests {
+		if got := getScheduledPost(t, store, tt.sp.ID).Status; got != tt.wantStatus {
+			t.Errorf("post %q status = %q, want %s", tt.sp.Post.Content, got, tt.wantStatus)
+		}
+	}
+	if n := len(srv.CallsTo("feed")); n != 0 {
+		t.Errorf("recovery published %d posts", n)
+	}
+}
@@ -0,0 +1,137 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+)
+
+const (
+	// Chu kỳ quét bài tới mốc thu thập insights (phút)
+	InsightsCollectIntervalMinutes = 15
+
+	// Số bài tối đa lấy insights mỗi lượt quét
+	InsightsBatchLimit = 100
+
+	// Số lần lỗi tối đa ở 1 mốc trước khi bỏ qua mốc đó (mốc 7 ngày là mốc cuối → dừng thu thập)
+	InsightsMaxAttempts = 5
+)
+
+// InsightsWindows các mốc thu thập insights tính từ lúc đăng, giãn dần
+var InsightsWindows = []time.Duration{
+	1 * time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+}
+
+// InsightsCollector lấy insights của các bài đã đăng theo mốc InsightsWindows
+type InsightsCollector struct {
+	store    *db.Store
+	fbClient *facebook.Client
+}
+
+// NewInsightsCollector tạo insights collector
+func NewInsightsCollector(store *db.Store, fbClient *facebook.Client) *InsightsCollector {
+	return &InsightsCollector{store: store, fbClient: fbClient}
+}
+
+// CollectDue lấy insights cho các bài đã tới mốc, trả về số snapshot đã lưu
+func (c *InsightsCollector) CollectDue(ctx context.Context) int {
+	due, err := c.store.GetDueInsights(InsightsWindows, InsightsBatchLimit)
+	if err != nil {
+		log.Printf("❌ Error fetching posts due for insights: %v", err)
+		return 0
+	}
+
+	saved := 0
+	for _, d := range due {
+		err := c.Collect(ctx, d)
+		if err == nil {
+			saved++
+			continue
+		}
+		// Bị throttle thì dừng lượt này, lượt sau lấy tiếp
+		if facebook.IsRateLimit(err) {
+			log.Printf("⏸️ Insights collection rate limited, stopping this run: %v", err)
+			break
+		}
+	}
+	if saved > 0 {
+		log.Printf("📊 Collected insights for %d posts", saved)
+	}
+	return saved
+}
+
+// Collect lấy và lưu 1 snapshot insights rồi chuyển sang mốc kế tiếp.
+// Bài đã bị xóa hoặc lỗi không retry được thì bỏ qua mốc này; lỗi tạm thời, throttle, lỗi mạng
+// giữ nguyên để thử lại, mọi lỗi đều được đếm và quá InsightsMaxAttempts lần thì bỏ qua mốc.
+func (c *InsightsCollector) Collect(ctx context.Context, d db.InsightsDue) error {
+	insights, err := c.fbClient.GetPostInsights(ctx, d.FacebookPostID, d.AccessToken)
+	if err != nil {
+		switch {
+		case facebook.IsNotFound(err):
+			log.Printf("🗑️ Post %s no longer exists on %s, stopping insights collection", d.FacebookPostID, d.PageName)
+			c.setCollections(d.PostLogID, len(InsightsWindows))
+		case isRetryableInsightsError(err) && d.Failures+1 < InsightsMaxAttempts:
+			log.Printf("⚠️ Insights for %s failed (%d/%d), will retry: %v", d.FacebookPostID, d.Failures+1, InsightsMaxAttempts, err)
+			if err := c.store.RecordInsightsFailure(d.PostLogID); err != nil {
+				log.Printf("⚠️ Error recording insights failure: %v", err)
+			}
+		default:
+			log.Printf("❌ Insights for %s failed, skipping this window: %v", d.FacebookPostID, err)
+			c.setCollections(d.PostLogID, windowsElapsed(d.Age, d.Collections+1))
+		}
+		return err
+	}
+
+	snapshot := &db.PostInsight{
+		PostLogID:      d.PostLogID,
+		PostID:         d.PostID,
+		PageID:         d.PageID,
+		FacebookPostID: d.FacebookPostID,
+		Impressions:    insights.Impressions,
+		Reach:          insights.Reach,
+		Reactions:      insights.Reactions,
+		Comments:       insights.Comments,
+		Shares:         insights.Shares,
+		Clicks:         insights.Clicks,
+	}
+	if err := c.store.SavePostInsight(snapshot); err != nil {
+		log.Printf("❌ Error saving insights for %s: %v", d.FacebookPostID, err)
+		return err
+	}
+
+	// Bài cũ đã qua nhiều mốc thì chỉ lấy 1 lần rồi nhảy tới mốc chưa tới
+	c.setCollections(d.PostLogID, windowsElapsed(d.Age, d.Collections+1))
+	return nil
+}
+
+func (c *InsightsCollector) setCollections(postLogID string, collections int) {
+	if err := c.store.SetInsightsCollections(postLogID, collections); err != nil {
+		log.Printf("⚠️ Error updating insights collections: %v", err)
+	}
+}
+
+// isRetryableInsightsError: throttle, lỗi tạm thời và lỗi mạng (không phải lỗi Graph API) thì thử lại lượt sau
+func isRetryableInsightsError(err error) bool {
+	if facebook.IsRateLimit(err) || facebook.IsTransient(err) {
+		return true
+	}
+	_, isGraphErr := facebook.AsGraphError(err)
+	return !isGraphErr
+}
+
+// windowsElapsed số mốc đã qua với bài đã đăng được age, tối thiểu atLeast
+func windowsElapsed(age time.Duration, atLeast int) int {
+	elapsed := 0
+	for _, w := range InsightsWindows {
+		if age >= w {
+			elapsed++
+		}
+	}
+	return max(elapsed, atLeast)
+}
@@ -0,0 +1,44 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+	"fbscheduler/internal/facebook/fake"
+)
+
+func TestCollectSkipsWindowAfterRepeatedFailures(t *testing.T) {
+	e, store, srv := newTestEngine(t)
+	sp := createScheduledPost(t, store, &db.Post{Content: "insights"}, time.Now())
+	if err := e.PublishPost(sp); err != nil {
+		t.Fatalf("PublishPost() error = %v", err)
+	}
+	_, fbPostID := lastPostLog(t, store, sp.ID)
+	collector := NewInsightsCollector(store, srv.Client(facebook.WithAppSecret("")))
+
+	// Lỗi tạm thời cũng được đếm: tới InsightsMaxAttempts lần thì bỏ qua mốc
+	for attempt := 1; attempt <= InsightsMaxAttempts; attempt++ {
+		due, err := store.GetDueInsights([]time.Duration{0, time.Hour}, InsightsBatchLimit)
+		if err != nil || len(due) != 1 {
+			t.Fatalf("attempt %d: GetDueInsights() = %d posts, %v, want the published post", attempt, len(due), err)
+		}
+		if due[0].Failures != attempt-1 {
+			t.Errorf("attempt %d: Failures = %d, want %d", attempt, due[0].Failures, attempt-1)
+		}
+		srv.FailNext(fbPostID, fake.Error{Status: 500, Code: 2, Message: "try again", IsTransient: true})
+		if err := collector.Collect(context.Background(), due[0]); err == nil {
+			t.Fatalf("attempt %d: Collect() error = nil, want the transient error", attempt)
+		}
+	}
+
+	due, err := store.GetDueInsights([]time.Duration{0, time.Hour}, InsightsBatchLimit)
+	if err != nil {
+		t.Fatalf("GetDueInsights() error = %v", err)
+	}
+	if len(due) != 0 {
+		t.Errorf("GetDueInsights() = %+v, want the window skipped after %d failures", due, InsightsMaxAttempts)
+	}
+}
@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+)
+
+// ============================================
+// INSTAGRAM
+// Đăng bài lên tài khoản Instagram Business liên kết với page (dùng page token)
+// ============================================
+
+// PublishInstagram đăng bài lên Instagram của page: ảnh đơn, carousel hoặc reel.
+// Page chưa liên kết Instagram → ErrInvalidRequest (không retry).
+// Dùng chung cho scheduler và đăng ngay (API).
+func PublishInstagram(ctx context.Context, fbClient *facebook.Client, page *db.Page, accessToken string, post *db.Post) (string, error) {
+	if page.InstagramAccountID == "" {
+		return "", fmt.Errorf("%w: page %s has no linked instagram account", facebook.ErrInvalidRequest, page.PageName)
+	}
+
+	result, err := fbClient.PublishInstagram(ctx, facebook.InstagramPublishRequest{
+		IGUserID:    page.InstagramAccountID,
+		AccessToken: accessToken,
+		Caption:     post.Content,
+		MediaType:   post.MediaType,
+		Media:       facebook.MediaFromURLs(post.MediaURLs),
+	})
+	if err != nil {
+		return "", err
+	}
+	return result.MediaID, nil
+}
+
+// crossPostInstagram đăng chéo lên Instagram sau khi bài Facebook đã lên, ghi 1 dòng post_logs riêng.
+// Bài Facebook đã đăng nên lỗi Instagram chỉ báo notification, không retry cả bài.
+func (e *PostingEngine) crossPostInstagram(sp db.ScheduledPost, accessToken string) {
+	logEntry := &db.PostLog{
+		ScheduledPostID: sp.ID,
+		PostID:          sp.PostID,
+		PageID:          sp.PageID,
+		Platform:        db.PlatformInstagram,
+	}
+
+	mediaID, err := PublishInstagram(context.Background(), e.fbClient, sp.Page, accessToken, sp.Post)
+	if err != nil {
+		log.Printf("⚠️ Post %s published on Facebook but instagram cross-post failed: %v", sp.ID, err)
+		logEntry.Status = "failed"
+		logEntry.ErrorMessage = err.Error()
+		e.store.NotifyInstagramPostFailed(sp.PageID, sp.Page.PageName, err.Error())
+	} else {
+		log.Printf("📸 Cross-posted to instagram for page %s: %s", sp.Page.PageID, mediaID)
+		logEntry.Status = "success"
+		logEntry.FacebookPostID = mediaID
+	}
+
+	if err := e.store.CreatePostLog(logEntry); err != nil {
+		log.Printf("❌ Error creating instagram log: %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+)
+
+// ============================================
+// NATIVE SCHEDULING
+// Giao việc hẹn giờ cho Facebook (scheduled_publish_time) thay vì tự đăng
+// ============================================
+
+// NativeOverdueGrace thời gian chờ sau giờ hẹn trước khi coi bài hẹn giờ trên Facebook là thất bại
+const NativeOverdueGrace = time.Hour
+
+const (
+	// Thời gian chờ trước lần giao lại đầu tiên sau lỗi tạm thời, nhân đôi sau mỗi lần lỗi
+	HandOffRetryBase = time.Minute
+
+	// Thời gian chờ tối đa giữa 2 lần giao lại
+	HandOffRetryMax = 30 * time.Minute
+)
+
+// HandOff tạo bài hẹn giờ trên Facebook cho 1 scheduled post mode "facebook".
+// Khóa idempotency lưu trước khi gọi Graph API; lần giao trước hoặc lỗi mơ hồ có thể đã tạo bài
+// → đối chiếu danh sách bài hẹn giờ của page trước khi tạo lại.
+// Lỗi tạm thời → giữ pending, giãn thời gian tới lần giao sau (tới giờ thì scheduler tự đăng).
+// Lỗi không thể retry → chuyển về mode "local".
+// Page không có quyền đăng bài → bài thất bại; token page hỏng → bài bị chặn (như khi tự đăng).
+func (e *PostingEngine) HandOff(sp db.ScheduledPost) (string, error) {
+	if err := CheckPostingPermission(sp.Page); err != nil {
+		return "", e.failWithoutPermission(sp, err)
+	}
+	if err := e.blockIfTokenInvalid(sp); err != nil {
+		return "", err
+	}
+
+	account, accessToken, err := e.getAccountForPost(sp)
+	if err != nil {
+		return "", fmt.Errorf("failed to get account: %w", err)
+	}
+
+	attempt, err := e.startPublishAttempt(sp)
+	if err != nil {
+		return "", fmt.Errorf("failed to save publish attempt: %w", err)
+	}
+
+	// Lần giao trước có thể đã tạo bài hẹn giờ → đối chiếu trước khi tạo lại
+	if attempt.Resumed {
+		if objectID, found := e.lookUpScheduledPost(sp, accessToken, attempt); found {
+			log.Printf("🔎 Post %s from an earlier hand-off is already scheduled on Facebook (attempt %s): %s", sp.ID, attempt.Key, objectID)
+			return objectID, e.markHandedOff(sp, account, objectID)
+		}
+	}
+
+	req := buildPublishRequest(sp, accessToken)
+	req.ScheduledPublishTime = sp.ScheduledTime
+	result, err := e.fbClient.Publish(context.Background(), req)
+	if err != nil {
+		// Timeout/5xx nhưng Facebook đã tạo bài hẹn giờ → coi như đã giao, không tạo lại
+		if facebook.IsAmbiguous(err) {
+			if objectID, found := e.lookUpScheduledPost(sp, accessToken, attempt); found {
+				log.Printf("🔎 Post %s was scheduled on Facebook despite %v (attempt %s): %s", sp.ID, err, attempt.Key, objectID)
+				return objectID, e.markHandedOff(sp, account, objectID)
+			}
+		}
+
+		log.Printf("❌ Failed to schedule post %s on Facebook: %v", sp.ID, err)
+		if action := classifyPostError(err); action == actionRetry || action == actionPauseAccount {
+			e.deferHandOff(sp)
+		} else {
+			if err := e.store.UpdateSchedulingMode(sp.ID, "local"); err != nil {
+				log.Printf("⚠️ Error switching post %s to local scheduling: %v", sp.ID, err)
+			}
+			log.Printf("↩️ Post %s falls back to local scheduling", sp.ID)
+		}
+		return "", err
+	}
+
+	e.claimPublishedPost(sp, attempt, result)
+	if err := e.markHandedOff(sp, account, result.PostID); err != nil {
+		return result.PostID, err
+	}
+
+	log.Printf("🗓️ Post %s scheduled on Facebook for %s: %s",
+		sp.ID, sp.ScheduledTime.Format(time.RFC3339), result.PostID)
+	return result.PostID, nil
+}
+
+// markHandedOff chuyển scheduled post sang fb_scheduled với ID bài hẹn giờ trên Facebook
+func (e *PostingEngine) markHandedOff(sp db.ScheduledPost, account *db.FacebookAccount, objectID string) error {
+	if err := e.store.MarkScheduledPostHandedOff(sp.ID, objectID); err != nil {
+		return fmt.Errorf("failed to mark post handed off: %w", err)
+	}
+	if account != nil {
+		if err := e.store.UpdateScheduledPostAccount(sp.ID, account.ID); err != nil {
+			log.Printf("⚠️ Error updating account_id: %v", err)
+		}
+	}
+	return nil
+}
+
+// deferHandOff giãn thời gian tới lần giao lại: HandOffRetryBase nhân đôi sau mỗi lần lỗi, tối đa HandOffRetryMax
+func (e *PostingEngine) deferHandOff(sp db.ScheduledPost) {
+	delay := HandOffRetryMax
+	if sp.HandOffAttempts < 5 {
+		delay = min(HandOffRetryBase<<sp.HandOffAttempts, HandOffRetryMax)
+	}
+	if err := e.store.DeferHandOff(sp.ID, time.Now().Add(delay)); err != nil {
+		log.Printf("⚠️ Error deferring hand-off of post %s: %v", sp.ID, err)
+		return
+	}
+	log.Printf("⏳ Hand-off of post %s retries in %v", sp.ID, delay)
+}
+
+// lookUpScheduledPost đọc danh sách bài hẹn giờ của page, tìm bài cùng nội dung và giờ hẹn
+// chưa ghi post_logs và claim được cho khóa của scheduled post
+func (e *PostingEngine) lookUpScheduledPost(sp db.ScheduledPost, accessToken string, attempt *publishAttempt) (string, bool) {
+	if !canLookUpPublishedPost(sp) {
+		return "", false
+	}
+	message := strings.TrimSpace(expectedMessage(sp))
+	if message == "" {
+		return "", false
+	}
+
+	posts, err := e.fbClient.GetScheduledPosts(context.Background(), sp.Page.PageID, accessToken, PublishLookupLimit)
+	if err != nil {
+		log.Printf("⚠️ Could not check scheduled posts of page %s for post %s (attempt %s): %v", sp.Page.PageID, sp.ID, attempt.Key, err)
+		return "", false
+	}
+
+	// Danh sách trả về mới nhất trước → duyệt ngược để lấy bài tạo sớm nhất
+	for i := len(posts) - 1; i >= 0; i-- {
+		if strings.TrimSpace(posts[i].Message) != message || posts[i].ScheduledPublishTime.Unix() != sp.ScheduledTime.Unix() {
+			continue
+		}
+		logged, err := e.store.IsFacebookPostLogged(posts[i].ID)
+		if err != nil || logged {
+			continue
+		}
+		claimed, err := e.store.ClaimFacebookPost(posts[i].ID, sp.PageID, attempt.Key)
+		if err != nil {
+			log.Printf("⚠️ Could not claim post %s for %s (attempt %s): %v", posts[i].ID, sp.ID, attempt.Key, err)
+			continue
+		}
+		if claimed {
+			return posts[i].ID, true
+		}
+	}
+	return "", false
+}
+
+// Reconcile kiểm tra bài đã hẹn giờ trên Facebook sau giờ đăng:
+// đã lên → completed, bị xóa trên Facebook → failed, quá NativeOverdueGrace mà chưa lên → failed
+func (e *PostingEngine) Reconcile(sp db.ScheduledPost) error {
+	if sp.FbObjectID == nil || *sp.FbObjectID == "" {
+		return fmt.Errorf("scheduled post %s has no Facebook object", sp.ID)
+	}
+	objectID := *sp.FbObjectID
+	accessToken := sp.Page.AccessToken
+
+	logEntry := &db.PostLog{
+		ScheduledPostID: sp.ID,
+		PostID:          sp.PostID,
+		PageID:          sp.PageID,
+		FacebookPostID:  objectID,
+	}
+
+	state, err := e.fbClient.GetPostState(context.Background(), objectID, accessToken, postMediaType(sp))
+	if err != nil {
+		if facebook.IsNotFound(err) {
+			return e.failNativePost(sp, logEntry, "post was deleted on Facebook before publishing")
+		}
+		// Lỗi tạm thời → đối soát lại ở lần quét sau
+		return fmt.Errorf("failed to check post state: %w", err)
+	}
+
+	if state.IsPublished {
+		var account *db.FacebookAccount
+		if sp.AccountID != nil {
+			account, _ = e.store.GetAccountByID(*sp.AccountID)
+		}
+		return e.handlePostSuccess(sp, account, accessToken, logEntry, &facebook.PublishResult{PostID: objectID})
+	}
+
+	if time.Since(sp.ScheduledTime) > NativeOverdueGrace {
+		return e.failNativePost(sp, logEntry,
+			fmt.Sprintf("post was not published by Facebook within %v of the scheduled time", NativeOverdueGrace))
+	}
+	return nil
+}
+
+// failNativePost đánh dấu bài hẹn giờ trên Facebook là thất bại
+func (e *PostingEngine) failNativePost(sp db.ScheduledPost, logEntry *db.PostLog, reason string) error {
+	log.Printf("❌ Facebook-scheduled post %s failed: %s", sp.ID, reason)
+
+	e.store.UpdateScheduledPostStatus(sp.ID, "failed")
+
+	logEntry.Status = "failed"
+	logEntry.ErrorMessage = reason
+	if err := e.store.CreatePostLog(logEntry); err != nil {
+		log.Printf("❌ Error creating log: %v", err)
+	}
+
+	if sp.AccountID != nil {
+		if account, err := e.store.GetAccountByID(*sp.AccountID); err == nil && account != nil {
+			e.store.NotifyPostFailed(account.ID, account.FbUserName, sp.Page.PageName, reason)
+		}
+	}
+	return nil
+}
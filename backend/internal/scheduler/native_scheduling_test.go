@@ -0,0 +1,242 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+	"fbscheduler/internal/facebook/fake"
+)
+
+// scheduleOnFacebook tạo bài hẹn giờ trên Graph API giả, trả về ID bài
+func scheduleOnFacebook(t *testing.T, client *facebook.Client, publishAt time.Time) string {
+	t.Helper()
+	result, err := client.Publish(context.Background(), facebook.PublishRequest{
+		PageID: testPageID, AccessToken: testPageToken, Message: "scheduled", ScheduledPublishTime: publishAt,
+	})
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	return result.PostID
+}
+
+func TestReconcileWithoutStoreChanges(t *testing.T) {
+	srv := fake.NewServer()
+	defer srv.Close()
+	client := srv.Client(facebook.WithAppSecret(""))
+	// Không cần DB: các trường hợp dưới đây không ghi gì, lần quét sau đối soát lại
+	e := NewPostingEngineWithClient(nil, client)
+
+	tests := []struct {
+		name     string
+		objectID func() string
+		fail     *fake.Error
+		wantErr  bool
+	}{
+		{"no facebook object", func() string { return "" }, nil, true},
+		{"not published yet", func() string { return scheduleOnFacebook(t, client, time.Now().Add(time.Hour)) }, nil, false},
+		{"transient error", func() string { return scheduleOnFacebook(t, client, time.Now().Add(time.Hour)) },
+			&fake.Error{Status: 500, Code: 2, Message: "try again", IsTransient: true}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objectID := tt.objectID()
+			if tt.fail != nil {
+				srv.FailNext(objectID, *tt.fail)
+			}
+			sp := testScheduledPost("1", &db.Post{Content: "scheduled"})
+			sp.FbObjectID = &objectID
+			sp.ScheduledTime = time.Now().Add(-time.Minute)
+
+			if err := e.Reconcile(sp); (err != nil) != tt.wantErr {
+				t.Errorf("Reconcile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name       string
+		prepare    func(srv *fake.Server, objectID string)
+		scheduled  time.Duration // giờ hẹn so với lúc đối soát
+		wantStatus string
+		wantLog    string
+	}{
+		{
+			name:       "published",
+			prepare:    func(srv *fake.Server, objectID string) { srv.PublishScheduled(objectID) },
+			scheduled:  -time.Minute,
+			wantStatus: "completed",
+			wantLog:    "success",
+		},
+		{
+			name: "deleted on Facebook",
+			prepare: func(srv *fake.Server, objectID string) {
+				srv.FailNext(objectID, fake.Error{Status: 400, Code: 100, Subcode: 33, Message: "does not exist"})
+			},
+			scheduled:  -time.Minute,
+			wantStatus: "failed",
+			wantLog:    "failed",
+		},
+		{
+			name:       "overdue",
+			prepare:    func(srv *fake.Server, objectID string) {},
+			scheduled:  -NativeOverdueGrace - time.Minute,
+			wantStatus: "failed",
+			wantLog:    "failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, store, srv := newTestEngine(t)
+			sp := createScheduledPost(t, store, &db.Post{Content: "scheduled"}, time.Now().Add(time.Hour))
+
+			objectID, err := e.HandOff(sp)
+			if err != nil {
+				t.Fatalf("HandOff() error = %v", err)
+			}
+			sp = getScheduledPost(t, store, sp.ID)
+			if sp.Status != "fb_scheduled" || sp.FbObjectID == nil || *sp.FbObjectID != objectID {
+				t.Fatalf("after HandOff status = %q, fb_object_id = %v, want fb_scheduled %s", sp.Status, sp.FbObjectID, objectID)
+			}
+
+			tt.prepare(srv, objectID)
+			sp.ScheduledTime = time.Now().Add(tt.scheduled)
+			if err := e.Reconcile(sp); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+
+			if got := getScheduledPost(t, store, sp.ID).Status; got != tt.wantStatus {
+				t.Errorf("status = %q, want %q", got, tt.wantStatus)
+			}
+			status, fbPostID := lastPostLog(t, store, sp.ID)
+			if status != tt.wantLog || fbPostID != objectID {
+				t.Errorf("post log = %s %q, want %s %q", status, fbPostID, tt.wantLog, objectID)
+			}
+		})
+	}
+}
+
+func TestHandOffGate(t *testing.T) {
+	tests := []struct {
+		name       string
+		prepare    func(t *testing.T, store *db.Store, sp db.ScheduledPost)
+		wantErr    error
+		wantStatus string
+	}{
+		{
+			name: "no posting permission",
+			prepare: func(t *testing.T, store *db.Store, sp db.ScheduledPost) {
+				if err := store.SavePageTasks(testPageID, []string{"ANALYZE"}); err != nil {
+					t.Fatalf("SavePageTasks() error = %v", err)
+				}
+			},
+			wantErr:    ErrNoPostingPermission,
+			wantStatus: "failed",
+		},
+		{
+			name: "invalid page token",
+			prepare: func(t *testing.T, store *db.Store, sp db.ScheduledPost) {
+				if err := store.SavePageTokenInfo(sp.PageID, nil, nil, false); err != nil {
+					t.Fatalf("SavePageTokenInfo() error = %v", err)
+				}
+			},
+			wantErr:    ErrPageTokenInvalid,
+			wantStatus: "blocked_token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, store, srv := newTestEngine(t)
+			sp := createScheduledPost(t, store, &db.Post{Content: "scheduled"}, time.Now().Add(time.Hour))
+			tt.prepare(t, store, sp)
+			sp = getScheduledPost(t, store, sp.ID)
+
+			if _, err := e.HandOff(sp); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("HandOff() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := getScheduledPost(t, store, sp.ID).Status; got != tt.wantStatus {
+				t.Errorf("status = %q, want %q", got, tt.wantStatus)
+			}
+			if calls := srv.CallsTo("feed"); len(calls) != 0 {
+				t.Errorf("HandOff() reached the Graph API: %d feed calls", len(calls))
+			}
+		})
+	}
+}
+
+func TestHandOffRecoversAmbiguousError(t *testing.T) {
+	e, store, srv := newTestEngine(t)
+	sp := createScheduledPost(t, store, &db.Post{Content: "scheduled"}, time.Now().Add(time.Hour))
+	// Facebook tạo bài hẹn giờ nhưng trả về lỗi timeout
+	srv.FailNext("feed", fake.Error{Status: 500, Code: 2, Message: "timeout", IsTransient: true, Committed: true})
+
+	objectID, err := e.HandOff(sp)
+	if err != nil {
+		t.Fatalf("HandOff() error = %v", err)
+	}
+	if calls := postCalls(srv, "feed"); calls != 1 {
+		t.Errorf("HandOff() sent %d feed calls, want 1 (no second scheduled post)", calls)
+	}
+	sp = getScheduledPost(t, store, sp.ID)
+	if sp.Status != "fb_scheduled" || sp.FbObjectID == nil || *sp.FbObjectID != objectID {
+		t.Errorf("status = %q, fb_object_id = %v, want fb_scheduled %s", sp.Status, sp.FbObjectID, objectID)
+	}
+}
+
+func TestHandOffBacksOff(t *testing.T) {
+	e, store, srv := newTestEngine(t)
+	sp := createScheduledPost(t, store, &db.Post{Content: "scheduled"}, time.Now().Add(time.Hour))
+	if err := store.UpdateSchedulingMode(sp.ID, "facebook"); err != nil {
+		t.Fatalf("UpdateSchedulingMode() error = %v", err)
+	}
+	sp = getScheduledPost(t, store, sp.ID)
+	srv.FailNext("feed", fake.Error{Status: 500, Code: 2, Message: "try again", IsTransient: true})
+
+	if _, err := e.HandOff(sp); err == nil {
+		t.Fatal("HandOff() error = nil, want the transient error")
+	}
+	sp = getScheduledPost(t, store, sp.ID)
+	if sp.Status != "pending" || sp.SchedulingMode != "facebook" || sp.HandOffAttempts != 1 {
+		t.Errorf("status = %q, mode = %q, attempts = %d, want pending facebook 1", sp.Status, sp.SchedulingMode, sp.HandOffAttempts)
+	}
+
+	// Lượt quét ngay sau đó không giao lại
+	posts, err := store.GetNativeHandoffPosts(facebook.MinScheduleLead)
+	if err != nil {
+		t.Fatalf("GetNativeHandoffPosts() error = %v", err)
+	}
+	for _, p := range posts {
+		if p.ID == sp.ID {
+			t.Error("GetNativeHandoffPosts() returned the post before its retry time")
+		}
+	}
+
+	// Lần giao sau không có bài hẹn giờ nào trên Facebook → tạo bài
+	if _, err := e.HandOff(sp); err != nil {
+		t.Fatalf("HandOff() error = %v", err)
+	}
+	if calls := postCalls(srv, "feed"); calls != 2 {
+		t.Errorf("feed calls = %d, want 2", calls)
+	}
+	if calls := srv.CallsTo("scheduled_posts"); len(calls) != 1 {
+		t.Errorf("scheduled_posts lookups = %d, want 1 before the second hand-off", len(calls))
+	}
+}
+
+// postCalls đếm số lần POST tới edge
+func postCalls(srv *fake.Server, edge string) int {
+	n := 0
+	for _, call := range srv.CallsTo(edge) {
+		if call.Method == "POST" {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,70 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+)
+
+// ============================================
+// PERMALINK
+// Sau khi đăng thành công đọc lại bài trên Facebook: permalink, created_time.
+// Lưu cùng ID ảnh/video và response gốc vào post_logs để mở thẳng bài đang live.
+// ============================================
+
+// Thời gian tối đa chờ đọc lại bài (không giữ engine quá lâu)
+const PublishedPostLookupTimeout = 15 * time.Second
+
+// RecordPublishedPost ghi vào log thông tin bài vừa đăng: ID ảnh/video, response gốc,
+// permalink và created_time đọc lại từ Facebook. Đọc lại lỗi chỉ ghi log, bài vẫn tính là thành công.
+// Story không có permalink nên không đọc lại. Dùng chung cho scheduler và đăng ngay (API).
+func RecordPublishedPost(ctx context.Context, fbClient *facebook.Client, logEntry *db.PostLog, result *facebook.PublishResult, accessToken, mediaType string) {
+	if result == nil || result.PostID == "" {
+		return
+	}
+	logEntry.MediaIDs = result.MediaIDs
+
+	var details *facebook.PublishedPost
+	if mediaType != facebook.MediaTypeStory {
+		lookupCtx, cancel := context.WithTimeout(ctx, PublishedPostLookupTimeout)
+		defer cancel()
+
+		var err error
+		details, err = fbClient.GetPublishedPost(lookupCtx, result.PostID, accessToken)
+		if err != nil {
+			log.Printf("⚠️ Could not read back post %s: %v", result.PostID, err)
+		} else {
+			logEntry.PermalinkURL = details.PermalinkURL
+			if !details.CreatedTime.IsZero() {
+				createdTime := details.CreatedTime
+				logEntry.FbCreatedTime = &createdTime
+			}
+			log.Printf("🔗 Post %s is live at %s", result.PostID, details.PermalinkURL)
+		}
+	}
+
+	logEntry.ResponseData = publishedResponseData(result, details)
+}
+
+// publishedResponseData gộp response gốc: "publish" (request tạo bài) và "post" (đọc lại bài)
+func publishedResponseData(result *facebook.PublishResult, details *facebook.PublishedPost) json.RawMessage {
+	data := map[string]json.RawMessage{}
+	if len(result.Response) > 0 && json.Valid(result.Response) {
+		data["publish"] = result.Response
+	}
+	if details != nil && len(details.Raw) > 0 && json.Valid(details.Raw) {
+		data["post"] = details.Raw
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
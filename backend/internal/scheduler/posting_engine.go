@@ -1,6 +1,8 @@
 package scheduler
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -25,6 +27,39 @@ const (
 
 	// Số request song song tối đa mỗi nick
 	MaxConcurrentPerAccount = 3
+
+	// Thời gian tạm dừng nick khi bị rate limit (khớp với record_post_failure)
+	RateLimitPauseMinutes = 30
+
+	// Comment đầu tiên: số lần thử và khoảng chờ giữa các lần (giây)
+	FirstCommentAttempts     = 3
+	FirstCommentRetrySeconds = 10
+
+	// Throttle theo header usage của Graph API (% hạn mức đã dùng):
+	// từ UsageSlowDownPercent cooldown tăng dần tới MaxCooldownMultiplier lần,
+	// từ UsagePausePercent tạm dừng page/nick trước khi Facebook từ chối request
+	UsageSlowDownPercent  = 50
+	UsagePausePercent     = 90
+	MaxCooldownMultiplier = 4
+
+	// Thời gian tạm dừng khi Facebook không báo estimated_time_to_regain_access (phút)
+	UsagePauseMinutes = 15
+)
+
+// ErrNoPostingPermission page không có quyền đăng bài (thiếu task CREATE_CONTENT hoặc quyền pages_manage_posts)
+var ErrNoPostingPermission = errors.New("no posting permission")
+
+// ErrPageTokenInvalid token của page đã hết hạn hoặc bị thu hồi (bài bị chặn tới khi đăng nhập lại)
+var ErrPageTokenInvalid = errors.New("page token is invalid")
+
+// postErrorAction cách xử lý khi đăng bài lỗi, dựa trên loại lỗi Graph API
+type postErrorAction int
+
+const (
+	actionRetry           postErrorAction = iota // Lỗi tạm thời (mạng, 5xx) → retry
+	actionPauseAccount                           // Rate limit → tạm dừng nick, retry sau
+	actionTokenExpired                           // Token hết hạn/bị thu hồi → đánh dấu nick, không retry
+	actionFailPermanently                        // Thiếu quyền, tham số sai... → không retry
 )
 
 // ============================================
@@ -38,6 +73,8 @@ type PostingEngine struct {
 
 	// Track last post time per account for cooldown
 	accountLastPost map[string]time.Time
+	// Cooldown hiện tại của mỗi nick, giãn ra theo usage (mặc định CooldownAfterPostSeconds)
+	accountCooldown map[string]time.Duration
 	accountMu       sync.RWMutex
 
 	// Semaphore per account for concurrent limit
@@ -47,16 +84,30 @@ type PostingEngine struct {
 
 // NewPostingEngine tạo posting engine mới
 func NewPostingEngine(store *db.Store) *PostingEngine {
+	return NewPostingEngineWithClient(store, facebook.NewClient())
+}
+
+// NewPostingEngineWithClient tạo posting engine với Facebook client tùy chỉnh
+func NewPostingEngineWithClient(store *db.Store, fbClient *facebook.Client) *PostingEngine {
 	return &PostingEngine{
 		store:           store,
-		fbClient:        facebook.NewClient(),
+		fbClient:        fbClient,
 		accountLastPost: make(map[string]time.Time),
+		accountCooldown: make(map[string]time.Duration),
 		accountSem:      make(map[string]chan struct{}),
 	}
 }
 
 // PublishPost đăng 1 bài với rate limiting và retry
 func (e *PostingEngine) PublishPost(sp db.ScheduledPost) error {
+	// Page không có quyền đăng bài → báo lỗi rõ ràng thay vì gọi Graph API rồi retry vô ích
+	if err := CheckPostingPermission(sp.Page); err != nil {
+		return e.failWithoutPermission(sp, err)
+	}
+	if err := e.blockIfTokenInvalid(sp); err != nil {
+		return err
+	}
+
 	// Lấy account để đăng bài
 	account, accessToken, err := e.getAccountForPost(sp)
 	if err != nil {
@@ -82,28 +133,169 @@ func (e *PostingEngine) PublishPost(sp db.ScheduledPost) error {
 		return err
 	}
 
-	// Post to Facebook
-	fbPostID, err := e.fbClient.PostToPage(
-		sp.Page.PageID,
-		accessToken,
-		sp.Post.Content,
-		sp.Post.MediaURLs,
-		sp.Post.MediaType,
-	)
+	// Lưu khóa idempotency trước khi gọi Graph API (đối chiếu feed nếu lỗi mơ hồ)
+	attempt, err := e.startPublishAttempt(sp)
+	if err != nil {
+		log.Printf("❌ Error saving publish attempt: %v", err)
+		e.store.UpdateScheduledPostStatus(sp.ID, "pending")
+		return err
+	}
 
 	// Create log entry
 	logEntry := &db.PostLog{
 		ScheduledPostID: sp.ID,
 		PostID:          sp.PostID,
 		PageID:          sp.PageID,
+		Platform:        db.PlatformFacebook,
+	}
+
+	// Lần đăng trước có thể đã tạo bài (lỗi mơ hồ, crash) → đối chiếu feed trước khi đăng lại
+	if fbPostID, found := e.resumePublishAttempt(sp, accessToken, attempt); found {
+		return e.handlePostSuccess(sp, account, accessToken, logEntry, &facebook.PublishResult{PostID: fbPostID})
+	}
+
+	// Post to Facebook (bài chỉ chọn Instagram thì đăng thẳng lên Instagram)
+	var result *facebook.PublishResult
+	if sp.Post.TargetsFacebook() {
+		result, err = e.publishFacebook(sp, accessToken)
+	} else {
+		logEntry.Platform = db.PlatformInstagram
+		var mediaID string
+		mediaID, err = PublishInstagram(context.Background(), e.fbClient, sp.Page, accessToken, sp.Post)
+		result = &facebook.PublishResult{PostID: mediaID}
 	}
 
+	// Lưu usage Facebook báo về, giãn cooldown hoặc tạm dừng page/nick nếu cần
+	e.applyUsage(sp, account, accessToken)
+
 	if err != nil {
+		// Timeout/5xx nhưng Facebook đã tạo bài → coi như thành công, không retry
+		if fbPostID, found := e.findPublishedPost(sp, accessToken, attempt, err); found {
+			return e.handlePostSuccess(sp, account, accessToken, logEntry, &facebook.PublishResult{PostID: fbPostID})
+		}
 		return e.handlePostError(sp, account, logEntry, err)
 	}
 
 	// Success
-	return e.handlePostSuccess(sp, account, logEntry, fbPostID)
+	e.claimPublishedPost(sp, attempt, result)
+	return e.handlePostSuccess(sp, account, accessToken, logEntry, result)
+}
+
+// CheckPostingPermission trả về ErrNoPostingPermission kèm lý do nếu page không có quyền đăng bài.
+// Dùng chung cho scheduler, xếp lịch và đăng ngay (API).
+func CheckPostingPermission(page *db.Page) error {
+	if page == nil {
+		return nil
+	}
+	if reason := page.MissingPostingPermission(); reason != "" {
+		return fmt.Errorf("%w: %s", ErrNoPostingPermission, reason)
+	}
+	return nil
+}
+
+// CheckPageToken trả về ErrPageTokenInvalid nếu token page đã biết là hỏng (TokenMonitor).
+// Dùng cho đăng ngay (API); scheduler thì chặn bài (blockIfTokenInvalid).
+func CheckPageToken(page *db.Page) error {
+	if page == nil || page.TokenIsValid {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrPageTokenInvalid, page.PageName)
+}
+
+// failWithoutPermission đánh dấu bài thất bại (không retry) khi page không có quyền đăng bài
+func (e *PostingEngine) failWithoutPermission(sp db.ScheduledPost, permErr error) error {
+	log.Printf("⛔ Post %s not published to page %s: %v", sp.ID, sp.Page.PageName, permErr)
+
+	if err := e.store.UpdateScheduledPostStatus(sp.ID, "failed"); err != nil {
+		log.Printf("❌ Error updating status: %v", err)
+	}
+
+	logEntry := &db.PostLog{
+		ScheduledPostID: sp.ID,
+		PostID:          sp.PostID,
+		PageID:          sp.PageID,
+		Platform:        db.PlatformFacebook,
+		Status:          "failed",
+		ErrorMessage:    permErr.Error(),
+	}
+	if sp.Post != nil && !sp.Post.TargetsFacebook() {
+		logEntry.Platform = db.PlatformInstagram
+	}
+	if err := e.store.CreatePostLog(logEntry); err != nil {
+		log.Printf("❌ Error creating log: %v", err)
+	}
+
+	e.store.NotifyNoPostingPermission(sp.PageID, sp.Page.PageName, sp.Page.MissingPostingPermission())
+	return permErr
+}
+
+// blockIfTokenInvalid chặn bài (blocked_token) khi token page đã biết là hỏng, giống TokenMonitor.
+// Bài được đưa lại pending khi token dùng lại được (UnblockPostsForPage).
+func (e *PostingEngine) blockIfTokenInvalid(sp db.ScheduledPost) error {
+	tokenErr := CheckPageToken(sp.Page)
+	if tokenErr == nil {
+		return nil
+	}
+	log.Printf("🔒 Post %s blocked: token of page %s is no longer valid", sp.ID, sp.Page.PageName)
+	if err := e.store.UpdateScheduledPostStatus(sp.ID, "blocked_token"); err != nil {
+		log.Printf("❌ Error updating status: %v", err)
+	}
+	return tokenErr
+}
+
+// publishFacebook đăng bài lên page, in caption lên ảnh trước nếu bài bật burn_captions
+func (e *PostingEngine) publishFacebook(sp db.ScheduledPost, accessToken string) (*facebook.PublishResult, error) {
+	req := buildPublishRequest(sp, accessToken)
+	rendered, err := RenderCaptionOverlays(sp.Post)
+	if err != nil {
+		return nil, err
+	}
+	req.Media = CaptionedMedia(sp.Post, req.Media, rendered)
+
+	return e.fbClient.Publish(context.Background(), req)
+}
+
+// buildPublishRequest tạo PublishRequest từ scheduled post (đã join post + page)
+func buildPublishRequest(sp db.ScheduledPost, accessToken string) facebook.PublishRequest {
+	return facebook.PublishRequest{
+		PageID:      sp.Page.PageID,
+		AccessToken: accessToken,
+		Message:     sp.Post.Content,
+		MediaType:   sp.Post.MediaType,
+		Media:       CaptionedMedia(sp.Post, facebook.MediaFromURLs(sp.Post.MediaURLs), nil),
+		AlbumName:   PostAlbumName(sp.Post),
+
+		Link:            sp.Post.LinkURL,
+		LinkName:        sp.Post.LinkName,
+		LinkDescription: sp.Post.LinkDescription,
+		LinkPicture:     sp.Post.LinkPicture,
+
+		Place: sp.Post.PlaceID,
+		Tags:  strings.Join(sp.Post.Tags, ","),
+
+		Published:     PostPublished(sp.Post),
+		Targeting:     PostAudience(sp.Post.Targeting),
+		FeedTargeting: PostAudience(sp.Post.FeedTargeting),
+	}
+}
+
+// PostAudience chuyển nhóm người xem của bài sang facebook.Audience (nil = mọi người).
+// Dùng chung cho scheduler và đăng ngay (API).
+func PostAudience(a *db.Audience) *facebook.Audience {
+	if a == nil {
+		return nil
+	}
+	audience := facebook.Audience(*a)
+	return &audience
+}
+
+// PostPublished trả về Published của PublishRequest: false cho bài ẩn (dark post), nil = đăng bình thường
+func PostPublished(post *db.Post) *bool {
+	if !post.Unpublished {
+		return nil
+	}
+	published := false
+	return &published
 }
 
 // getAccountForPost lấy account và access token để đăng bài
@@ -111,8 +303,8 @@ func (e *PostingEngine) getAccountForPost(sp db.ScheduledPost) (*db.FacebookAcco
 	// Thử lấy account từ scheduled_post (nếu đã được assign)
 	// TODO: Cần thêm account_id vào ScheduledPost struct
 
-	// Fallback: Lấy best account cho page
-	account, err := e.store.GetBestAccountForPage(sp.PageID)
+	// Fallback: Lấy best account cho page (story dùng hạn mức story nếu có cấu hình)
+	account, err := e.store.GetBestAccountForMediaType(sp.PageID, postMediaType(sp))
 	if err == nil && account != nil {
 		// Lấy access token từ page (vì page token khác user token)
 		page, err := e.store.GetPageByID(sp.PageID)
@@ -148,6 +340,7 @@ func (e *PostingEngine) getAccountSemaphore(accountID string) chan struct{} {
 func (e *PostingEngine) waitForCooldown(accountID string) {
 	e.accountMu.RLock()
 	lastPost, ok := e.accountLastPost[accountID]
+	cooldown, adaptive := e.accountCooldown[accountID]
 	e.accountMu.RUnlock()
 
 	if !ok {
@@ -155,7 +348,9 @@ func (e *PostingEngine) waitForCooldown(accountID string) {
 	}
 
 	elapsed := time.Since(lastPost)
-	cooldown := time.Duration(CooldownAfterPostSeconds) * time.Second
+	if !adaptive {
+		cooldown = time.Duration(CooldownAfterPostSeconds) * time.Second
+	}
 
 	if elapsed < cooldown {
 		waitTime := cooldown - elapsed
@@ -172,7 +367,8 @@ func (e *PostingEngine) updateLastPostTime(accountID string) {
 }
 
 // handlePostSuccess xử lý khi đăng bài thành công
-func (e *PostingEngine) handlePostSuccess(sp db.ScheduledPost, account *db.FacebookAccount, logEntry *db.PostLog, fbPostID string) error {
+func (e *PostingEngine) handlePostSuccess(sp db.ScheduledPost, account *db.FacebookAccount, accessToken string, logEntry *db.PostLog, result *facebook.PublishResult) error {
+	fbPostID := result.PostID
 	log.Printf("✅ Successfully posted to page %s: %s", sp.Page.PageID, fbPostID)
 
 	// Update scheduled post status
@@ -188,14 +384,47 @@ func (e *PostingEngine) handlePostSuccess(sp db.ScheduledPost, account *db.Faceb
 	// Update log
 	logEntry.Status = "success"
 	logEntry.FacebookPostID = fbPostID
+
+	// Comment đầu tiên (bài đã lên nên lỗi comment chỉ là partial, không retry cả bài)
+	isFacebook := logEntry.Platform != db.PlatformInstagram
+	if isFacebook {
+		RecordPublishedPost(context.Background(), e.fbClient, logEntry, result, accessToken, postMediaType(sp))
+	}
+	if isFacebook && hasFirstComment(sp.Post) && postMediaType(sp) != facebook.MediaTypeStory {
+		commentID, err := PostFirstComment(context.Background(), e.fbClient, fbPostID, accessToken, sp.Post)
+		if err != nil {
+			log.Printf("⚠️ Post %s published but first comment failed: %v", fbPostID, err)
+			logEntry.Status = "partial"
+			logEntry.ErrorMessage = "first comment failed: " + err.Error()
+			e.store.NotifyFirstCommentFailed(sp.PageID, sp.Page.PageName, err.Error())
+		} else {
+			log.Printf("💬 First comment posted on %s: %s", fbPostID, commentID)
+			logEntry.CommentID = commentID
+		}
+	}
+
 	if err := e.store.CreatePostLog(logEntry); err != nil {
 		log.Printf("❌ Error creating log: %v", err)
 	}
 
+	// Đăng chéo lên Instagram nếu bài chọn cả 2 nền tảng
+	if isFacebook && sp.Post.HasPlatform(db.PlatformInstagram) {
+		e.crossPostInstagram(sp, accessToken)
+	}
+
 	// Update account stats
 	if account != nil {
 		e.updateLastPostTime(account.ID)
-		if err := e.store.RecordSuccessfulPost(account.ID, sp.PageID); err != nil {
+
+		if postMediaType(sp) == facebook.MediaTypeStory {
+			if err := e.store.RecordSuccessfulStory(account.ID, sp.PageID); err != nil {
+				log.Printf("⚠️ Error recording successful story: %v", err)
+			}
+			// Story đếm riêng thì không ảnh hưởng hạn mức bài đăng
+			if account.MaxStoriesPerDay > 0 {
+				return nil
+			}
+		} else if err := e.store.RecordSuccessfulPost(account.ID, sp.PageID); err != nil {
 			log.Printf("⚠️ Error recording successful post: %v", err)
 		}
 
@@ -210,23 +439,47 @@ func (e *PostingEngine) handlePostSuccess(sp db.ScheduledPost, account *db.Faceb
 func (e *PostingEngine) handlePostError(sp db.ScheduledPost, account *db.FacebookAccount, logEntry *db.PostLog, postErr error) error {
 	log.Printf("❌ Failed to post to page %s: %v", sp.Page.PageID, postErr)
 
-	// Check if rate limit error
-	isRateLimit := e.isRateLimitError(postErr)
+	action := classifyPostError(postErr)
 
 	// Update account stats
 	if account != nil {
-		if err := e.store.RecordPostFailure(account.ID, isRateLimit); err != nil {
+		if err := e.store.RecordPostFailure(account.ID, action == actionPauseAccount); err != nil {
 			log.Printf("⚠️ Error recording post failure: %v", err)
 		}
 
-		// Create notification if rate limit
-		if isRateLimit {
+		switch action {
+		case actionPauseAccount:
 			e.store.NotifyRateLimit(account.ID, account.FbUserName)
+		case actionTokenExpired:
+			if err := e.store.UpdateAccountStatus(account.ID, "token_expired"); err != nil {
+				log.Printf("⚠️ Error marking token expired: %v", err)
+			}
+			e.store.NotifyTokenExpired(account.ID, account.FbUserName)
 		}
 	}
 
 	// Determine retry strategy
-	retryDelay := e.getRetryDelay(sp.RetryCount)
+	var retryDelay time.Duration
+	switch action {
+	case actionRetry:
+		retryDelay = e.getRetryDelay(sp.RetryCount)
+	case actionPauseAccount:
+		// Chờ hết thời gian tạm dừng nick rồi mới retry
+		retryDelay = e.getRetryDelay(sp.RetryCount)
+		pause := RateLimitPauseMinutes * time.Minute
+		// Facebook báo thời gian mở lại (estimated_time_to_regain_access) thì theo đó
+		if regain := e.regainAccessIn(sp); regain > pause {
+			pause = regain
+			if account != nil {
+				if err := e.store.PauseAccount(account.ID, regain); err != nil {
+					log.Printf("⚠️ Error pausing account: %v", err)
+				}
+			}
+		}
+		if retryDelay > 0 && retryDelay < pause {
+			retryDelay = pause
+		}
+	}
 
 	if retryDelay > 0 {
 		// Schedule retry
@@ -240,9 +493,13 @@ func (e *PostingEngine) handlePostError(sp db.ScheduledPost, account *db.Faceboo
 		log.Printf("🔄 Retry %d/3 scheduled in %v for post %s",
 			sp.RetryCount+1, retryDelay, sp.ID)
 	} else {
-		// Max retries reached
+		// Max retries reached hoặc lỗi không thể retry
 		e.store.UpdateScheduledPostStatus(sp.ID, "failed")
-		log.Printf("💀 Max retries reached for post %s", sp.ID)
+		if action == actionRetry || action == actionPauseAccount {
+			log.Printf("💀 Max retries reached for post %s", sp.ID)
+		} else {
+			log.Printf("⛔ Non-retryable error for post %s, skipping retries", sp.ID)
+		}
 
 		// Create notification
 		if account != nil {
@@ -276,18 +533,81 @@ func (e *PostingEngine) getRetryDelay(currentRetryCount int) time.Duration {
 	}
 }
 
-// isRateLimitError kiểm tra có phải lỗi rate limit không
-func (e *PostingEngine) isRateLimitError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errStr := strings.ToLower(err.Error())
-	return strings.Contains(errStr, "rate limit") ||
-		strings.Contains(errStr, "too many") ||
-		strings.Contains(errStr, "limit reached") ||
-		strings.Contains(errStr, "code: 4") ||
-		strings.Contains(errStr, "code: 17") ||
-		strings.Contains(errStr, "code: 32")
+// classifyPostError phân loại lỗi để quyết định retry, tạm dừng nick hay bỏ qua
+func classifyPostError(err error) postErrorAction {
+	// Request không hợp lệ (vd: link kèm media) → retry cũng không thành công
+	if errors.Is(err, facebook.ErrInvalidRequest) || errors.Is(err, ErrNoPostingPermission) {
+		return actionFailPermanently
+	}
+	// Cả lô batch lỗi (token của lô, timeout) → retry, không phải lỗi của từng bài
+	if errors.Is(err, facebook.ErrBatchFailed) {
+		return actionRetry
+	}
+
+	graphErr, ok := facebook.AsGraphError(err)
+	if !ok {
+		// Lỗi mạng, timeout, lỗi đọc media... → retry
+		return actionRetry
+	}
+
+	switch {
+	case graphErr.IsRateLimit():
+		return actionPauseAccount
+	case graphErr.IsTokenExpired():
+		return actionTokenExpired
+	case graphErr.IsPermissionDenied():
+		return actionFailPermanently
+	case graphErr.IsTransient():
+		return actionRetry
+	default:
+		return actionFailPermanently
+	}
+}
+
+// hasFirstComment kiểm tra bài có cấu hình comment đầu tiên không
+func hasFirstComment(post *db.Post) bool {
+	return post != nil && (post.FirstComment != "" || post.FirstCommentImage != "")
+}
+
+// PostFirstComment đăng comment đầu tiên (text + ảnh) lên bài vừa đăng, tự retry lỗi tạm thời.
+// Dùng chung cho scheduler và đăng ngay (API).
+func PostFirstComment(ctx context.Context, fbClient *facebook.Client, fbPostID, accessToken string, post *db.Post) (string, error) {
+	req := facebook.CommentRequest{
+		ObjectID:    fbPostID,
+		AccessToken: accessToken,
+		Message:     post.FirstComment,
+	}
+	if post.FirstCommentImage != "" {
+		req.Image = &facebook.MediaItem{URL: post.FirstCommentImage}
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= FirstCommentAttempts; attempt++ {
+		commentID, err := fbClient.Comment(ctx, req)
+		if err == nil {
+			return commentID, nil
+		}
+		lastErr = err
+
+		// Chỉ retry lỗi mạng / lỗi tạm thời / rate limit
+		action := classifyPostError(err)
+		if action != actionRetry && action != actionPauseAccount {
+			break
+		}
+		if attempt < FirstCommentAttempts {
+			log.Printf("🔄 First comment attempt %d/%d failed: %v", attempt, FirstCommentAttempts, err)
+			time.Sleep(time.Duration(attempt*FirstCommentRetrySeconds) * time.Second)
+		}
+	}
+	return "", lastErr
+}
+
+// postMediaType trả về media_type của bài (rỗng nếu chưa load post)
+func postMediaType(sp db.ScheduledPost) string {
+	if sp.Post == nil {
+		return ""
+	}
+	return sp.Post.MediaType
 }
 
 // updateScheduledTime cập nhật thời gian schedule
@@ -1,7 +1,9 @@
 package scheduler
 
 import (
+	"context"
 	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
 	"log"
 	"sync"
 	"time"
@@ -15,15 +17,24 @@ import (
 type Scheduler struct {
 	store         *db.Store
 	postingEngine *PostingEngine
+	tokenMonitor  *TokenMonitor
+	insights      *InsightsCollector
 	ticker        *time.Ticker
 	stopChan      chan struct{}
 	wg            sync.WaitGroup
 }
 
 func NewScheduler(store *db.Store) *Scheduler {
+	return NewSchedulerWithClient(store, facebook.NewClient())
+}
+
+// NewSchedulerWithClient tạo scheduler dùng Facebook client tùy chỉnh (vd: fake server)
+func NewSchedulerWithClient(store *db.Store, fbClient *facebook.Client) *Scheduler {
 	return &Scheduler{
 		store:         store,
-		postingEngine: NewPostingEngine(store),
+		postingEngine: NewPostingEngineWithClient(store, fbClient),
+		tokenMonitor:  NewTokenMonitor(store, fbClient),
+		insights:      NewInsightsCollector(store, fbClient),
 		ticker:        time.NewTicker(30 * time.Second),
 		stopChan:      make(chan struct{}),
 	}
@@ -32,9 +43,18 @@ func NewScheduler(store *db.Store) *Scheduler {
 func (s *Scheduler) Start() {
 	log.Println("📅 Scheduler: Checking for pending posts every 30 seconds...")
 
+	// Bài còn "processing" từ lần chạy trước (server dừng giữa lúc đăng)
+	s.postingEngine.RecoverProcessingPosts()
+
 	// Run daily reset job
 	go s.runDailyResetJob()
 
+	// Kiểm tra token nick + page định kỳ
+	go s.runTokenHealthJob()
+
+	// Thu thập insights bài đã đăng theo mốc 1h, 6h, 24h, 7 ngày
+	go s.runInsightsJob()
+
 	for {
 		select {
 		case <-s.ticker.C:
@@ -53,6 +73,10 @@ func (s *Scheduler) Stop() {
 }
 
 func (s *Scheduler) processPendingPosts() {
+	// Bài mode "facebook": giao cho Facebook hẹn giờ, đối soát bài đã tới giờ
+	s.handOffNativePosts()
+	s.reconcileNativePosts()
+
 	posts, err := s.store.GetPendingScheduledPosts()
 	if err != nil {
 		log.Printf("❌ Scheduler: Error fetching pending posts: %v", err)
@@ -65,6 +89,16 @@ func (s *Scheduler) processPendingPosts() {
 
 	log.Printf("📤 Scheduler: Found %d posts to publish", len(posts))
 
+	// Bài text/link/ảnh tới giờ cùng lúc → đăng gộp qua Graph batch API
+	if batchable, rest := splitBatchablePosts(posts); len(batchable) > 1 {
+		posts = rest
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.postingEngine.PublishBatch(batchable)
+		}()
+	}
+
 	// Group posts by account to respect rate limits
 	postsByAccount := s.groupPostsByAccount(posts)
 
@@ -78,13 +112,43 @@ func (s *Scheduler) processPendingPosts() {
 	}
 }
 
+// handOffNativePosts tạo bài hẹn giờ trên Facebook cho các bài mode "facebook" còn đủ thời gian
+func (s *Scheduler) handOffNativePosts() {
+	posts, err := s.store.GetNativeHandoffPosts(facebook.MinScheduleLead)
+	if err != nil {
+		log.Printf("❌ Scheduler: Error fetching posts to schedule on Facebook: %v", err)
+		return
+	}
+
+	for _, sp := range posts {
+		if _, err := s.postingEngine.HandOff(sp); err != nil {
+			log.Printf("⚠️ Hand-off of post %s failed: %v", sp.ID, err)
+		}
+	}
+}
+
+// reconcileNativePosts kiểm tra trạng thái các bài đã hẹn giờ trên Facebook và tới giờ đăng
+func (s *Scheduler) reconcileNativePosts() {
+	posts, err := s.store.GetDueNativeScheduledPosts()
+	if err != nil {
+		log.Printf("❌ Scheduler: Error fetching Facebook-scheduled posts: %v", err)
+		return
+	}
+
+	for _, sp := range posts {
+		if err := s.postingEngine.Reconcile(sp); err != nil {
+			log.Printf("⚠️ Reconcile of post %s failed: %v", sp.ID, err)
+		}
+	}
+}
+
 // groupPostsByAccount nhóm posts theo account
 func (s *Scheduler) groupPostsByAccount(posts []db.ScheduledPost) map[string][]db.ScheduledPost {
 	result := make(map[string][]db.ScheduledPost)
 
 	for _, sp := range posts {
 		// Lấy account cho page này
-		account, _ := s.store.GetBestAccountForPage(sp.PageID)
+		account, _ := s.store.GetBestAccountForMediaType(sp.PageID, postMediaType(sp))
 
 		accountID := "default" // Fallback nếu không có account
 		if account != nil {
@@ -138,3 +202,35 @@ func (s *Scheduler) runDailyResetJob() {
 		}
 	}
 }
+
+// runTokenHealthJob kiểm tra token ngay khi khởi động, sau đó mỗi TokenCheckIntervalHours giờ
+func (s *Scheduler) runTokenHealthJob() {
+	ticker := time.NewTicker(TokenCheckIntervalHours * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		log.Println("🔑 Checking account and page tokens...")
+		s.tokenMonitor.CheckAll(context.Background())
+
+		select {
+		case <-ticker.C:
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// runInsightsJob lấy insights cho các bài tới mốc, mỗi InsightsCollectIntervalMinutes phút
+func (s *Scheduler) runInsightsJob() {
+	ticker := time.NewTicker(InsightsCollectIntervalMinutes * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.insights.CollectDue(context.Background())
+		case <-s.stopChan:
+			return
+		}
+	}
+}
@@ -38,6 +38,7 @@ func (s *SchedulingService) SchedulePostToPages(postID string, pageIDs []string,
 		PageIDs:       pageIDs,
 		PreferredDate: preferredDate,
 		UseTimeSlots:  true,
+		MediaType:     s.postMediaType(postID),
 	}
 
 	preview, err := s.algorithm.CalculateSchedule(req)
@@ -91,6 +92,7 @@ func (s *SchedulingService) PreviewSchedule(postID string, pageIDs []string, pre
 		PageIDs:       pageIDs,
 		PreferredDate: preferredDate,
 		UseTimeSlots:  true,
+		MediaType:     s.postMediaType(postID),
 	}
 
 	return s.algorithm.CalculateSchedule(req)
@@ -102,7 +104,7 @@ func (s *SchedulingService) SchedulePostToSinglePage(postID, pageID string, sche
 	defer s.mu.Unlock()
 
 	// Lấy account tốt nhất
-	account, _ := s.store.GetBestAccountForPage(pageID)
+	account, _ := s.store.GetBestAccountForMediaType(pageID, s.postMediaType(postID))
 	
 	// Tạo scheduled post
 	sp := &db.ScheduledPost{
@@ -121,6 +123,15 @@ func (s *SchedulingService) SchedulePostToSinglePage(postID, pageID string, sche
 	return s.store.CreateScheduledPost(sp)
 }
 
+// postMediaType lấy media_type của bài (rỗng nếu không tìm thấy)
+func (s *SchedulingService) postMediaType(postID string) string {
+	post, err := s.store.GetPostByID(postID)
+	if err != nil || post == nil {
+		return ""
+	}
+	return post.MediaType
+}
+
 // GetScheduleStats lấy thống kê schedule
 func (s *SchedulingService) GetScheduleStats(date time.Time) (*ScheduleStats, error) {
 	stats := &ScheduleStats{
@@ -134,6 +145,13 @@ func (s *SchedulingService) GetScheduleStats(date time.Time) (*ScheduleStats, er
 	}
 	stats.PendingCount = pending
 
+	// Đếm số bài đã hẹn giờ trên Facebook
+	fbScheduled, err := s.countScheduledPostsByStatus(date, "fb_scheduled")
+	if err != nil {
+		return nil, err
+	}
+	stats.FbScheduledCount = fbScheduled
+
 	// Đếm số bài completed
 	completed, err := s.countScheduledPostsByStatus(date, "completed")
 	if err != nil {
@@ -148,7 +166,7 @@ func (s *SchedulingService) GetScheduleStats(date time.Time) (*ScheduleStats, er
 	}
 	stats.FailedCount = failed
 
-	stats.TotalCount = pending + completed + failed
+	stats.TotalCount = pending + fbScheduled + completed + failed
 
 	return stats, nil
 }
@@ -171,6 +189,7 @@ type ScheduleStats struct {
 	Date           time.Time `json:"date"`
 	TotalCount     int       `json:"total_count"`
 	PendingCount   int       `json:"pending_count"`
+	FbScheduledCount int     `json:"fb_scheduled_count"`
 	CompletedCount int       `json:"completed_count"`
 	FailedCount    int       `json:"failed_count"`
 }
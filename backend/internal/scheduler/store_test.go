@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/db/dbtest"
+	"fbscheduler/internal/facebook"
+	"fbscheduler/internal/facebook/fake"
+)
+
+const (
+	testPageID    = "123"
+	testPageToken = "page-token"
+)
+
+// newTestEngine tạo engine trên DB test (bỏ qua nếu không có TEST_DATABASE_URL) và Graph API giả
+func newTestEngine(t *testing.T) (*PostingEngine, *db.Store, *fake.Server) {
+	t.Helper()
+	store := dbtest.NewStore(t)
+	srv := fake.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddPage(testPageID, "Test Page", testPageToken)
+	return NewPostingEngineWithClient(store, srv.Client(facebook.WithAppSecret(""))), store, srv
+}
+
+// createScheduledPost lưu page, bài và scheduled post (chưa gán nick), trả về bản đọc lại đủ dữ liệu để đăng
+func createScheduledPost(t *testing.T, store *db.Store, post *db.Post, scheduledTime time.Time) db.ScheduledPost {
+	t.Helper()
+	page := &db.Page{PageID: testPageID, PageName: "Test Page", AccessToken: testPageToken}
+	if err := store.CreateOrUpdatePage(page); err != nil {
+		t.Fatalf("CreateOrUpdatePage() error = %v", err)
+	}
+	post.Status = "scheduled"
+	if err := store.CreatePost(post); err != nil {
+		t.Fatalf("CreatePost() error = %v", err)
+	}
+	sp := &db.ScheduledPost{
+		PostID: post.ID, PageID: page.ID, ScheduledTime: scheduledTime,
+		Status: "pending", MaxRetries: 3, SchedulingMode: "local",
+	}
+	if err := store.CreateScheduledPost(sp); err != nil {
+		t.Fatalf("CreateScheduledPost() error = %v", err)
+	}
+	return getScheduledPost(t, store, sp.ID)
+}
+
+func getScheduledPost(t *testing.T, store *db.Store, id string) db.ScheduledPost {
+	t.Helper()
+	sp, err := store.GetScheduledPostByID(id)
+	if err != nil || sp == nil {
+		t.Fatalf("GetScheduledPostByID(%s) = %v, %v", id, sp, err)
+	}
+	return *sp
+}
+
+// lastPostLog đọc trạng thái và ID bài Facebook của log mới nhất của scheduled post
+func lastPostLog(t *testing.T, store *db.Store, scheduledPostID string) (status, facebookPostID string) {
+	t.Helper()
+	err := store.DB().QueryRow(`
+		SELECT status, COALESCE(facebook_post_id, '') FROM post_logs
+		WHERE scheduled_post_id = $1 ORDER BY posted_at DESC LIMIT 1
+	`, scheduledPostID).Scan(&status, &facebookPostID)
+	if err != nil {
+		t.Fatalf("read post log of %s: %v", scheduledPostID, err)
+	}
+	return status, facebookPostID
+}
@@ -0,0 +1,119 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+)
+
+// ErrUsagePaused page hoặc nick đang tạm dừng vì usage Graph API sắp chạm giới hạn
+var ErrUsagePaused = errors.New("page is paused because Graph API usage is near the limit, try again later")
+
+// adaptiveCooldown tính cooldown giữa 2 bài cùng nick theo % usage cao nhất:
+// dưới UsageSlowDownPercent giữ CooldownAfterPostSeconds, sau đó tăng tuyến tính
+// tới MaxCooldownMultiplier lần ở UsagePausePercent
+func adaptiveCooldown(percent int) time.Duration {
+	base := time.Duration(CooldownAfterPostSeconds) * time.Second
+	if percent <= UsageSlowDownPercent {
+		return base
+	}
+	if percent >= UsagePausePercent {
+		return base * MaxCooldownMultiplier
+	}
+	scale := float64(percent-UsageSlowDownPercent) / float64(UsagePausePercent-UsageSlowDownPercent)
+	return base + time.Duration(scale*float64(base*(MaxCooldownMultiplier-1)))
+}
+
+// applyUsage xử lý usage Facebook báo về cho token vừa dùng (ApplyUsage) và giãn
+// cooldown của nick theo % usage cao nhất
+func (e *PostingEngine) applyUsage(sp db.ScheduledPost, account *db.FacebookAccount, accessToken string) {
+	pageName := sp.PageID
+	if sp.Page != nil {
+		pageName = sp.Page.PageName
+	}
+	usage, ok := ApplyUsage(e.store, e.fbClient, sp.PageID, pageName, account, accessToken)
+	if !ok || account == nil {
+		return
+	}
+
+	cooldown := adaptiveCooldown(usage.MaxPercent())
+	e.accountMu.Lock()
+	e.accountCooldown[account.ID] = cooldown
+	e.accountMu.Unlock()
+	if cooldown > time.Duration(CooldownAfterPostSeconds)*time.Second {
+		log.Printf("🐢 API usage at %d%%, cooldown for account %s raised to %v", usage.MaxPercent(), account.FbUserName, cooldown)
+	}
+}
+
+// ApplyUsage lưu snapshot usage Facebook báo về cho token vừa dùng (page và nick),
+// tạm dừng page (usage page/business use case) hoặc nick (usage app) khi sắp bị throttle
+// hay đã bị throttle. Dùng chung cho scheduler và đăng ngay (API) để đăng tay không
+// đẩy nick vượt hạn mức scheduler đang giữ.
+func ApplyUsage(store *db.Store, fbClient *facebook.Client, pageID, pageName string, account *db.FacebookAccount, accessToken string) (facebook.Usage, bool) {
+	usage, ok := fbClient.Usage(accessToken)
+	if !ok {
+		return usage, false
+	}
+
+	data, err := json.Marshal(usage)
+	if err == nil {
+		if err := store.SavePageAPIUsage(pageID, data); err != nil {
+			log.Printf("⚠️ Error saving page API usage: %v", err)
+		}
+		if account != nil {
+			if err := store.SaveAccountAPIUsage(account.ID, data); err != nil {
+				log.Printf("⚠️ Error saving account API usage: %v", err)
+			}
+		}
+	}
+
+	regain := usage.RegainAccessIn()
+	pause := regain
+	if pause <= 0 {
+		pause = UsagePauseMinutes * time.Minute
+	}
+
+	if usage.PagePercent() >= UsagePausePercent || regain > 0 {
+		log.Printf("⏸️ Page %s API usage at %d%%, pausing for %v", pageName, usage.PagePercent(), pause)
+		if err := store.PausePage(pageID, pause); err != nil {
+			log.Printf("⚠️ Error pausing page: %v", err)
+		}
+	}
+	if account != nil && usage.AppPercent() >= UsagePausePercent {
+		log.Printf("⏸️ App usage at %d%%, pausing account %s for %v", usage.AppPercent(), account.FbUserName, pause)
+		if err := store.PauseAccount(account.ID, pause); err != nil {
+			log.Printf("⚠️ Error pausing account: %v", err)
+		}
+	}
+	return usage, true
+}
+
+// CheckUsagePause trả lỗi nếu page hoặc nick đang bị tạm dừng theo usage
+// (rate_limit_until chưa hết). Lỗi DB coi như không tạm dừng, giống scheduler.
+func CheckUsagePause(store *db.Store, pageID string, account *db.FacebookAccount) error {
+	if paused, err := store.IsPaused("page", pageID); err == nil && paused {
+		return ErrUsagePaused
+	}
+	if account != nil {
+		if paused, err := store.IsPaused("account", account.ID); err == nil && paused {
+			return ErrUsagePaused
+		}
+	}
+	return nil
+}
+
+// regainAccessIn thời gian Facebook ước tính tới khi hết bị throttle với token của page
+func (e *PostingEngine) regainAccessIn(sp db.ScheduledPost) time.Duration {
+	if sp.Page == nil {
+		return 0
+	}
+	usage, ok := e.fbClient.Usage(sp.Page.AccessToken)
+	if !ok {
+		return 0
+	}
+	return usage.RegainAccessIn()
+}
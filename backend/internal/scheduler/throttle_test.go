@@ -0,0 +1,41 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveCooldown(t *testing.T) {
+	base := time.Duration(CooldownAfterPostSeconds) * time.Second
+	mid := (UsageSlowDownPercent + UsagePausePercent) / 2
+
+	tests := []struct {
+		name    string
+		percent int
+		want    time.Duration
+	}{
+		{"idle", 0, base},
+		{"at slow-down threshold", UsageSlowDownPercent, base},
+		{"halfway to pause", mid, base + base*(MaxCooldownMultiplier-1)/2},
+		{"at pause threshold", UsagePausePercent, base * MaxCooldownMultiplier},
+		{"throttled", 100, base * MaxCooldownMultiplier},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := adaptiveCooldown(tt.percent); got != tt.want {
+				t.Errorf("adaptiveCooldown(%d) = %v, want %v", tt.percent, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdaptiveCooldownIsMonotonic(t *testing.T) {
+	prev := adaptiveCooldown(0)
+	for percent := 1; percent <= 100; percent++ {
+		got := adaptiveCooldown(percent)
+		if got < prev {
+			t.Fatalf("adaptiveCooldown(%d) = %v is shorter than at %d%% (%v)", percent, got, percent-1, prev)
+		}
+		prev = got
+	}
+}
@@ -0,0 +1,187 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"fbscheduler/internal/db"
+	"fbscheduler/internal/facebook"
+)
+
+// ============================================
+// TOKEN MONITOR
+// Kiểm tra token nick + page định kỳ bằng debug_token
+// ============================================
+
+const (
+	// Chu kỳ kiểm tra token (giờ)
+	TokenCheckIntervalHours = 6
+
+	// Thông báo trước khi token nick hết hạn (ngày)
+	TokenExpiryWarningDays = 7
+)
+
+// TokenMonitor kiểm tra token, đánh dấu nick hết hạn, chặn bài của page có token hỏng
+type TokenMonitor struct {
+	store    *db.Store
+	fbClient *facebook.Client
+}
+
+// NewTokenMonitor tạo token monitor
+func NewTokenMonitor(store *db.Store, fbClient *facebook.Client) *TokenMonitor {
+	return &TokenMonitor{store: store, fbClient: fbClient}
+}
+
+// CheckAll kiểm tra token của tất cả nick và page đang active
+func (m *TokenMonitor) CheckAll(ctx context.Context) {
+	accounts, err := m.store.GetAllAccounts()
+	if err != nil {
+		log.Printf("❌ Token monitor: Error fetching accounts: %v", err)
+	}
+	for i := range accounts {
+		if accounts[i].Status == "disabled" {
+			continue
+		}
+		if err := m.CheckAccount(ctx, &accounts[i]); err != nil {
+			log.Printf("⚠️ Token monitor: Could not check account %s: %v", accounts[i].FbUserName, err)
+		}
+	}
+
+	pages, err := m.store.GetActivePages()
+	if err != nil {
+		log.Printf("❌ Token monitor: Error fetching pages: %v", err)
+	}
+	for i := range pages {
+		if err := m.CheckPage(ctx, &pages[i]); err != nil {
+			log.Printf("⚠️ Token monitor: Could not check page %s: %v", pages[i].PageName, err)
+		}
+	}
+}
+
+// CheckAccount kiểm tra user token của nick: lưu hạn + quyền, đánh dấu token_expired,
+// thông báo trước TokenExpiryWarningDays ngày (mỗi hạn token chỉ thông báo 1 lần)
+func (m *TokenMonitor) CheckAccount(ctx context.Context, account *db.FacebookAccount) error {
+	info, err := m.fbClient.DebugToken(ctx, account.AccessToken)
+	if err != nil {
+		return err
+	}
+
+	account.TokenExpiresAt = optionalTime(info.ExpiresAt)
+	account.DataAccessExpiresAt = optionalTime(info.DataAccessExpiresAt)
+	account.TokenScopes = info.Scopes
+	account.TokenIsValid = info.IsValid && !isExpired(info.ExpiresAt)
+	if err := m.store.SaveAccountTokenInfo(account.ID, account.TokenExpiresAt, account.DataAccessExpiresAt, info.Scopes, account.TokenIsValid); err != nil {
+		return err
+	}
+
+	if !account.TokenIsValid {
+		if account.Status != "token_expired" {
+			log.Printf("🔒 Token of account %s is no longer valid", account.FbUserName)
+			if err := m.store.UpdateAccountStatus(account.ID, "token_expired"); err != nil {
+				return err
+			}
+			account.Status = "token_expired"
+			m.store.NotifyTokenExpired(account.ID, account.FbUserName)
+		}
+		return nil
+	}
+
+	// Token dùng lại được (vd: lỗi 190 trước đó do page token) → mở lại nick
+	if account.Status == "token_expired" {
+		log.Printf("🔓 Token of account %s is valid again", account.FbUserName)
+		if err := m.store.UpdateAccountStatus(account.ID, "active"); err != nil {
+			return err
+		}
+		account.Status = "active"
+	}
+
+	m.refreshPageTasks(account)
+
+	if !info.ExpiresAt.IsZero() && time.Until(info.ExpiresAt) < TokenExpiryWarningDays*24*time.Hour {
+		notify, err := m.store.MarkTokenExpiryNotified(account.ID, info.ExpiresAt)
+		if err != nil {
+			return err
+		}
+		if notify {
+			daysLeft := int(time.Until(info.ExpiresAt).Hours() / 24)
+			log.Printf("⏰ Token of account %s expires in %d days", account.FbUserName, daysLeft)
+			m.store.NotifyTokenExpiring(account.ID, account.FbUserName, daysLeft)
+		}
+	}
+	return nil
+}
+
+// CheckPage kiểm tra page token: token hỏng → chặn bài pending (blocked_token),
+// token dùng lại được và page còn quyền đăng bài → mở lại các bài đã chặn
+func (m *TokenMonitor) CheckPage(ctx context.Context, page *db.Page) error {
+	info, err := m.fbClient.DebugToken(ctx, page.AccessToken)
+	if err != nil {
+		return err
+	}
+
+	wasValid := page.TokenIsValid
+	page.TokenExpiresAt = optionalTime(info.ExpiresAt)
+	page.TokenScopes = info.Scopes
+	page.TokenIsValid = info.IsValid && !isExpired(info.ExpiresAt)
+	if err := m.store.SavePageTokenInfo(page.ID, page.TokenExpiresAt, info.Scopes, page.TokenIsValid); err != nil {
+		return err
+	}
+
+	if !page.TokenIsValid {
+		blocked, err := m.store.BlockPendingPostsForPage(page.ID)
+		if err != nil {
+			return err
+		}
+		if wasValid || blocked > 0 {
+			log.Printf("🔒 Token of page %s is no longer valid, blocked %d pending posts", page.PageName, blocked)
+			m.store.NotifyPageTokenInvalid(page.ID, page.PageName, int(blocked))
+		}
+		return nil
+	}
+
+	// Token dùng được nhưng page không có quyền đăng bài → giữ nguyên các bài đã chặn
+	if reason := page.MissingPostingPermission(); reason != "" {
+		log.Printf("🔒 Page %s has no posting permission: %s", page.PageName, reason)
+		return nil
+	}
+
+	unblocked, err := m.store.UnblockPostsForPage(page.ID)
+	if err != nil {
+		return err
+	}
+	if unblocked > 0 {
+		log.Printf("🔓 Token of page %s is valid again, released %d blocked posts", page.PageName, unblocked)
+	}
+	return nil
+}
+
+// refreshPageTasks cập nhật quyền (tasks) của nick trên các page đã lưu, lỗi chỉ ghi log
+func (m *TokenMonitor) refreshPageTasks(account *db.FacebookAccount) {
+	pages, err := m.fbClient.GetUserPages(account.AccessToken)
+	if err != nil {
+		log.Printf("⚠️ Token monitor: Could not fetch pages of account %s: %v", account.FbUserName, err)
+		return
+	}
+	for _, p := range pages {
+		if len(p.Tasks) == 0 {
+			continue
+		}
+		if err := m.store.SavePageTasks(p.ID, p.Tasks); err != nil {
+			log.Printf("⚠️ Token monitor: Could not save tasks of page %s: %v", p.Name, err)
+		}
+	}
+}
+
+// isExpired kiểm tra hạn token (zero = không hết hạn)
+func isExpired(expiresAt time.Time) bool {
+	return !expiresAt.IsZero() && expiresAt.Before(time.Now())
+}
+
+// optionalTime trả về nil cho zero time (token không hết hạn)
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,9 @@
+-- ============================================
+-- MIGRATION 009: Link post overrides
+-- Tiêu đề / mô tả / ảnh tùy chỉnh cho bài đăng dạng link
+-- ============================================
+
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS link_name TEXT,
+    ADD COLUMN IF NOT EXISTS link_description TEXT,
+    ADD COLUMN IF NOT EXISTS link_picture TEXT;
@@ -0,0 +1,39 @@
+-- ============================================
+-- MIGRATION 010: Page Stories
+-- Đếm story riêng với bài feed (nếu nick có cấu hình max_stories_per_day)
+-- ============================================
+
+-- max_stories_per_day = 0: story tính chung vào posts_today / max_posts_per_day
+ALTER TABLE facebook_accounts
+    ADD COLUMN IF NOT EXISTS stories_today INTEGER DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS max_stories_per_day INTEGER DEFAULT 0;
+
+-- Reset cả posts_today và stories_today hàng ngày
+CREATE OR REPLACE FUNCTION reset_daily_post_counts() 
+RETURNS void AS $$
+BEGIN
+    UPDATE facebook_accounts SET posts_today = 0, stories_today = 0;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function: Ghi nhận story thành công
+CREATE OR REPLACE FUNCTION record_successful_story(
+    p_account_id UUID,
+    p_page_id UUID
+) RETURNS void AS $$
+BEGIN
+    UPDATE facebook_accounts 
+    SET 
+        stories_today = CASE WHEN max_stories_per_day > 0 THEN stories_today + 1 ELSE stories_today END,
+        posts_today = CASE WHEN max_stories_per_day > 0 THEN posts_today ELSE posts_today + 1 END,
+        last_post_at = NOW(),
+        consecutive_failures = 0
+    WHERE id = p_account_id;
+    
+    UPDATE page_account_assignments
+    SET 
+        posts_count = posts_count + 1,
+        last_post_at = NOW()
+    WHERE account_id = p_account_id AND page_id = p_page_id;
+END;
+$$ LANGUAGE plpgsql;
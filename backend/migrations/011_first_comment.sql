@@ -0,0 +1,12 @@
+-- ============================================
+-- MIGRATION 011: Tự động comment đầu tiên sau khi đăng
+-- ============================================
+
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS first_comment TEXT,
+    ADD COLUMN IF NOT EXISTS first_comment_image TEXT;
+
+-- comment_id: ID comment trên Facebook
+-- status 'partial' = bài đã đăng nhưng comment đầu tiên lỗi
+ALTER TABLE post_logs
+    ADD COLUMN IF NOT EXISTS comment_id VARCHAR(100);
@@ -0,0 +1,18 @@
+-- ============================================
+-- MIGRATION 012: Native Facebook scheduling
+-- Giao việc hẹn giờ cho Facebook (published=false + scheduled_publish_time)
+-- ============================================
+
+-- Mặc định của page: true = bài schedule lên page này được hẹn giờ trên Facebook
+ALTER TABLE pages
+    ADD COLUMN IF NOT EXISTS native_scheduling BOOLEAN DEFAULT false;
+
+-- scheduling_mode: 'local' (scheduler tự đăng) | 'facebook' (Facebook tự đăng)
+-- fb_object_id: ID bài đã hẹn giờ trên Facebook
+-- status 'fb_scheduled' = đã giao cho Facebook, scheduler chỉ đối soát trạng thái
+ALTER TABLE scheduled_posts
+    ADD COLUMN IF NOT EXISTS scheduling_mode VARCHAR(20) DEFAULT 'local',
+    ADD COLUMN IF NOT EXISTS fb_object_id VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_posts_fb_scheduled
+    ON scheduled_posts(scheduled_time) WHERE status = 'fb_scheduled';
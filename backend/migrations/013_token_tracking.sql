@@ -0,0 +1,15 @@
+-- ============================================
+-- MIGRATION 013: Theo dõi hạn token (debug_token)
+-- token_expires_at đã có sẵn, giờ được ghi từ debug_token:
+--   user token: long-lived ~60 ngày, page token: NULL = không hết hạn
+-- ============================================
+
+ALTER TABLE facebook_accounts
+    ADD COLUMN IF NOT EXISTS token_scopes TEXT[] DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS token_is_valid BOOLEAN DEFAULT true,
+    ADD COLUMN IF NOT EXISTS data_access_expires_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS token_checked_at TIMESTAMP;
+
+ALTER TABLE pages
+    ADD COLUMN IF NOT EXISTS token_scopes TEXT[] DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS token_checked_at TIMESTAMP;
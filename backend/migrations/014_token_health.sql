@@ -0,0 +1,15 @@
+-- ============================================
+-- MIGRATION 014: Job kiểm tra token định kỳ
+-- ============================================
+
+-- token_expiry_notified_at: hạn token đã được thông báo (chống thông báo trùng,
+-- đăng nhập lại → hạn mới → thông báo lại khi sắp hết hạn)
+ALTER TABLE facebook_accounts
+    ADD COLUMN IF NOT EXISTS token_expiry_notified_at TIMESTAMP;
+
+ALTER TABLE pages
+    ADD COLUMN IF NOT EXISTS token_is_valid BOOLEAN DEFAULT true;
+
+-- status 'blocked_token' = bài pending của page có token hỏng, chờ đăng nhập lại
+CREATE INDEX IF NOT EXISTS idx_scheduled_posts_blocked_token
+    ON scheduled_posts(page_id) WHERE status = 'blocked_token';
@@ -0,0 +1,14 @@
+-- ============================================
+-- MIGRATION 015: Throttle theo header usage của Graph API
+-- ============================================
+
+-- api_usage: snapshot X-App-Usage / X-Page-Usage / X-Business-Use-Case-Usage gần nhất
+ALTER TABLE pages
+    ADD COLUMN IF NOT EXISTS api_usage JSONB,
+    ADD COLUMN IF NOT EXISTS api_usage_updated_at TIMESTAMP,
+    -- Tạm dừng đăng lên page khi sắp bị Facebook throttle (so với scheduled_time nên dùng TIMESTAMPTZ)
+    ADD COLUMN IF NOT EXISTS rate_limit_until TIMESTAMPTZ;
+
+ALTER TABLE facebook_accounts
+    ADD COLUMN IF NOT EXISTS api_usage JSONB,
+    ADD COLUMN IF NOT EXISTS api_usage_updated_at TIMESTAMP;
@@ -0,0 +1,30 @@
+-- ============================================
+-- MIGRATION 016: Thu thập insights bài đăng
+-- Mỗi lần thu thập lưu 1 snapshot (time series), theo mốc 1h, 6h, 24h, 7 ngày sau khi đăng
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS post_insights (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    post_log_id UUID REFERENCES post_logs(id) ON DELETE CASCADE,
+    post_id UUID REFERENCES posts(id) ON DELETE CASCADE,
+    page_id UUID REFERENCES pages(id) ON DELETE CASCADE,
+    facebook_post_id VARCHAR(255) NOT NULL,
+    impressions BIGINT DEFAULT 0,
+    reach BIGINT DEFAULT 0,
+    reactions BIGINT DEFAULT 0,
+    comments BIGINT DEFAULT 0,
+    shares BIGINT DEFAULT 0,
+    clicks BIGINT DEFAULT 0,
+    collected_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_insights_log ON post_insights(post_log_id, collected_at DESC);
+CREATE INDEX IF NOT EXISTS idx_post_insights_post ON post_insights(post_id);
+CREATE INDEX IF NOT EXISTS idx_post_insights_page ON post_insights(page_id, collected_at DESC);
+
+-- insights_collections: số mốc thu thập đã qua (0..4), 4 = xong
+ALTER TABLE post_logs
+    ADD COLUMN IF NOT EXISTS insights_collections INT DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_post_logs_insights_due
+    ON post_logs(posted_at) WHERE status IN ('success', 'partial') AND insights_collections < 4;
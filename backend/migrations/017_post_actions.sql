@@ -0,0 +1,10 @@
+-- ============================================
+-- MIGRATION 017: Sửa / xóa bài đã đăng từ app
+-- action: publish (mặc định), edit, delete - mỗi thao tác ghi 1 dòng post_logs
+-- edited_at / deleted_at đánh dấu trên dòng publish gốc
+-- ============================================
+
+ALTER TABLE post_logs
+    ADD COLUMN IF NOT EXISTS action VARCHAR(20) DEFAULT 'publish',
+    ADD COLUMN IF NOT EXISTS edited_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
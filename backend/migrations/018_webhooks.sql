@@ -0,0 +1,33 @@
+-- ============================================
+-- MIGRATION 018: Nhận webhook từ Facebook
+-- Lưu sự kiện feed của page (bài bị gỡ, comment, reaction) và thay đổi quyền của app
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS webhook_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    object VARCHAR(30) NOT NULL,          -- page, permissions
+    entry_id VARCHAR(255) NOT NULL,       -- FB page id (page) hoặc FB user id (permissions)
+    field VARCHAR(100) NOT NULL,          -- feed hoặc tên quyền
+    item VARCHAR(30),                     -- status, photo, video, comment, reaction...
+    verb VARCHAR(30),                     -- add, edited, remove, hide, granted, revoked
+    facebook_post_id VARCHAR(255),
+    comment_id VARCHAR(255),
+    from_id VARCHAR(255),
+    from_name VARCHAR(255),
+    message TEXT,
+    payload JSONB NOT NULL,
+    -- Liên kết tới page / bài đã đăng từ app (nếu có)
+    page_id UUID REFERENCES pages(id) ON DELETE SET NULL,
+    post_log_id UUID REFERENCES post_logs(id) ON DELETE SET NULL,
+    received_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_events_received ON webhook_events(received_at DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_events_page ON webhook_events(page_id, received_at DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_events_post_log ON webhook_events(post_log_id);
+
+-- Lần cuối đăng ký subscribed_apps thành công cho page
+ALTER TABLE pages
+    ADD COLUMN IF NOT EXISTS webhook_subscribed_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_post_logs_facebook_post_id ON post_logs(facebook_post_id);
@@ -0,0 +1,23 @@
+-- ============================================
+-- MIGRATION 019: Check-in địa điểm và tag người
+-- place_id / tags trên bài đăng + danh sách địa điểm đã lưu theo page
+-- ============================================
+
+-- place_id: FB place page ID để check-in; tags: FB user ID được tag (cần place_id)
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS place_id VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS tags TEXT[] DEFAULT '{}';
+
+CREATE TABLE IF NOT EXISTS saved_locations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    page_id UUID NOT NULL REFERENCES pages(id) ON DELETE CASCADE,
+    place_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    address TEXT,
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE(page_id, place_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_locations_page ON saved_locations(page_id, name);
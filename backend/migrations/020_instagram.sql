@@ -0,0 +1,17 @@
+-- ============================================
+-- MIGRATION 020: Đăng chéo sang Instagram Business
+-- Tài khoản IG liên kết với page + nền tảng đích của bài + nền tảng của từng dòng log
+-- ============================================
+
+-- Tài khoản Instagram Business liên kết với page (lấy lúc đăng nhập Facebook)
+ALTER TABLE pages
+    ADD COLUMN IF NOT EXISTS instagram_account_id VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS instagram_username VARCHAR(255);
+
+-- platforms: nơi bài được đăng - facebook, instagram hoặc cả hai
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS platforms TEXT[] DEFAULT '{facebook}';
+
+-- platform: nền tảng của dòng log; facebook_post_id giữ IG media id khi platform = instagram
+ALTER TABLE post_logs
+    ADD COLUMN IF NOT EXISTS platform VARCHAR(20) DEFAULT 'facebook';
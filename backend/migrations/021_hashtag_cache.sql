@@ -0,0 +1,21 @@
+-- ============================================
+-- MIGRATION 021: Cache tìm kiếm hashtag Instagram
+-- Instagram chỉ cho tra 30 hashtag khác nhau / 7 ngày mỗi tài khoản:
+-- giữ hashtag id + số bài top/recent, searched_at để đếm hạn mức
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS hashtag_cache (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    instagram_account_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    -- hashtag_id rỗng = hashtag không tồn tại trên Instagram
+    hashtag_id VARCHAR(255),
+    top_media_count INT DEFAULT 0,
+    recent_media_count INT DEFAULT 0,
+    -- Lần gọi ig_hashtag_search gần nhất (tính vào hạn mức 30 / 7 ngày)
+    searched_at TIMESTAMPTZ DEFAULT NOW(),
+    fetched_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE(instagram_account_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_hashtag_cache_searched ON hashtag_cache(instagram_account_id, searched_at DESC);
@@ -0,0 +1,10 @@
+-- ============================================
+-- MIGRATION 022: Caption riêng cho từng ảnh + in caption lên ảnh
+-- Bài nhiều ảnh có caption riêng được đăng dạng album (album_name)
+-- ============================================
+
+-- media_captions[i] là caption của media_urls[i]; burn_captions = in caption lên ảnh trước khi upload
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS media_captions TEXT[] DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS burn_captions BOOLEAN DEFAULT false,
+    ADD COLUMN IF NOT EXISTS album_name VARCHAR(255);
@@ -0,0 +1,8 @@
+-- ============================================
+-- MIGRATION 023: Quyền đăng bài của page
+-- tasks: quyền của user trên page (me/accounts), cần CREATE_CONTENT để đăng bài.
+-- Cập nhật khi kết nối page và khi token monitor kiểm tra token.
+-- ============================================
+
+ALTER TABLE pages
+    ADD COLUMN IF NOT EXISTS tasks TEXT[] DEFAULT '{}';
@@ -0,0 +1,9 @@
+-- ============================================
+-- MIGRATION 024: Khóa idempotency cho mỗi lần đăng bài
+-- Lưu trước khi gọi Graph API; đăng lỗi mơ hồ (timeout, 5xx) thì đối chiếu
+-- feed của page từ publish_started_at để tránh đăng trùng khi retry
+-- ============================================
+
+ALTER TABLE scheduled_posts
+    ADD COLUMN IF NOT EXISTS publish_key VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS publish_started_at TIMESTAMP;
@@ -0,0 +1,11 @@
+-- ============================================
+-- MIGRATION 025: Giới hạn người xem và bài ẩn (dark post)
+-- targeting: chỉ người phù hợp mới xem được bài (quốc gia, tỉnh/thành, ngôn ngữ, tuổi)
+-- feed_targeting: ưu tiên hiển thị bài trên news feed của người phù hợp
+-- unpublished: tạo bài trên page nhưng không hiện trên dòng thời gian
+-- ============================================
+
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS targeting JSONB,
+    ADD COLUMN IF NOT EXISTS feed_targeting JSONB,
+    ADD COLUMN IF NOT EXISTS unpublished BOOLEAN DEFAULT false;
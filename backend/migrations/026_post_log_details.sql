@@ -0,0 +1,10 @@
+-- ============================================
+-- MIGRATION 026: Thông tin bài đã đăng trên Facebook
+-- Sau khi đăng thành công lưu permalink, created_time, ID ảnh/video;
+-- response_data giữ response gốc của Graph API
+-- ============================================
+
+ALTER TABLE post_logs
+    ADD COLUMN IF NOT EXISTS permalink_url TEXT,
+    ADD COLUMN IF NOT EXISTS fb_created_time TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS media_ids TEXT[] DEFAULT '{}';
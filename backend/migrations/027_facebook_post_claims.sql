@@ -0,0 +1,13 @@
+-- ============================================
+-- MIGRATION 027: Ghi nhận bài Facebook thuộc lần đăng nào
+-- Mỗi bài Facebook chỉ thuộc 1 khóa idempotency (publish_key): đăng thành công
+-- hoặc đối chiếu feed sau lỗi mơ hồ đều phải claim được bài trước khi ghi nhận,
+-- để 2 bài cùng nội dung không nhận nhầm bài của nhau
+-- ============================================
+
+CREATE TABLE IF NOT EXISTS facebook_post_claims (
+    facebook_post_id VARCHAR(255) PRIMARY KEY,
+    page_id UUID REFERENCES pages(id) ON DELETE CASCADE,
+    publish_key VARCHAR(64) NOT NULL,
+    claimed_at TIMESTAMP DEFAULT NOW()
+);
@@ -0,0 +1,8 @@
+-- ============================================
+-- MIGRATION 028: Giữ khóa idempotency qua các lần retry
+-- publish_key / publish_started_at là của lần đăng đầu tiên chưa xong; lần đăng lại
+-- đối chiếu feed từ thời điểm đó. Lưu TIMESTAMPTZ để so với created_time của Facebook.
+-- ============================================
+
+ALTER TABLE scheduled_posts
+    ALTER COLUMN publish_started_at TYPE TIMESTAMPTZ USING publish_started_at AT TIME ZONE current_setting('TimeZone');
@@ -0,0 +1,8 @@
+-- ============================================
+-- MIGRATION 029: Giãn thời gian thử lại khi giao bài hẹn giờ cho Facebook lỗi
+-- Lỗi tạm thời khi tạo bài hẹn giờ không gửi lại ở mỗi lượt quét 30 giây
+-- ============================================
+
+ALTER TABLE scheduled_posts
+    ADD COLUMN IF NOT EXISTS handoff_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS handoff_retry_at TIMESTAMPTZ;
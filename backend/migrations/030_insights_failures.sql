@@ -0,0 +1,7 @@
+-- ============================================
+-- MIGRATION 030: Đếm số lần lấy insights lỗi ở mốc hiện tại
+-- Mọi loại lỗi (kể cả lỗi mạng, lỗi tạm thời) đều được đếm, quá số lần tối đa thì bỏ qua mốc
+-- ============================================
+
+ALTER TABLE post_logs
+    ADD COLUMN IF NOT EXISTS insights_failures INT NOT NULL DEFAULT 0;
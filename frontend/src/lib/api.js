@@ -84,7 +84,7 @@ export const api = {
 	testScheduleNow: (id) => request(`/api/schedule/${id}/test`, { method: 'POST' }),
 	
 	// Logs
-	getLogs: (limit = 50, offset = 0) => request(`/api/logs?limit=${limit}&offset=${offset}`),
+	getLogs: (limit = 50, offset = 0, status = '') => request(`/api/logs?limit=${limit}&offset=${offset}${status ? `&status=${status}` : ''}`),
 	
 	// Upload
 	uploadImage: async (file) => {